@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// defaultAccount is the account of users who have not switched with
+// /account. Its entries go to the main tab like before accounts existed.
+const defaultAccount = "personal"
+
+// accountName is what /account accepts as the name of an account, so that
+// it makes a valid tab title.
+var accountName = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
+// account returns the active account of the user, defaultAccount when they
+// have not switched.
+func (pref UserPreference) account() string {
+	if pref.Account == "" {
+		return defaultAccount
+	}
+	return pref.Account
+}
+
+// accountSheet returns the tab the entries of chatID's account go to, e.g.
+// "Business_123456" for the account "business". It is "", the main tab, for
+// defaultAccount.
+func accountSheet(chatID int64, account string) string {
+	if account == defaultAccount {
+		return ""
+	}
+	return fmt.Sprintf("%s_%d", cases.Title(language.Indonesian).String(account), chatID)
+}
+
+// accountHeader is the header row of an account tab, laid out like the main
+// tab according to sheetConfig.
+func (b *Bot) accountHeader() []interface{} {
+	config := b.sheetConfig
+	header := make([]interface{}, config.width())
+	for col, name := range fixedColumns {
+		header[col] = name
+	}
+	for _, field := range config.fields() {
+		header[columnIndex(*field.col)] = field.name
+	}
+	for i := range header {
+		if header[i] == nil {
+			header[i] = ""
+		}
+	}
+	return header
+}
+
+// entrySheet returns the tab new entries of the chat with ID id go to: the
+// joint sheet it shares after /subscribe, otherwise the tab of its active
+// account. Entries of unknown chats go to the main tab.
+func (b *Bot) entrySheet(ctx context.Context, id string) (string, error) {
+	chatID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", nil
+	}
+	pref, _ := b.getPreference(chatID)
+	title := accountSheet(chatID, pref.account())
+	if pref.JointSheet != "" {
+		title = pref.JointSheet
+	}
+	if title == "" {
+		return "", nil
+	}
+	if err := b.ensureTab(ctx, title, b.accountHeader()); err != nil {
+		return "", err
+	}
+	return title, nil
+}
+
+// getAccounts returns the accounts of chatID that have a tab, after
+// defaultAccount, which always exists. The active account is included even
+// when nothing was recorded in it yet.
+func (b *Bot) getAccounts(ctx context.Context, chatID int64) ([]string, error) {
+	titles, err := storeWithContext(ctx, b.store).SheetTitles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sheet titles: %w", err)
+	}
+
+	suffix := fmt.Sprintf("_%d", chatID)
+	var accounts []string
+	for _, title := range titles {
+		if name, ok := strings.CutSuffix(title, suffix); ok && accountName.MatchString(strings.ToLower(name)) {
+			accounts = append(accounts, strings.ToLower(name))
+		}
+	}
+	pref, _ := b.getPreference(chatID)
+	if active := pref.account(); active != defaultAccount && !slices.Contains(accounts, active) {
+		accounts = append(accounts, active)
+	}
+	slices.Sort(accounts)
+	return append([]string{defaultAccount}, accounts...), nil
+}
+
+// getAccountSummary sums this month's spending of an account of chatID. The
+// total of defaultAccount is the main tab's, as on /summary.
+func (b *Bot) getAccountSummary(ctx context.Context, chatID int64, account string) (int, error) {
+	title := accountSheet(chatID, account)
+	if title == "" {
+		return b.getSummary(ctx, monthStart()), nil
+	}
+	return b.getTabSummary(ctx, title)
+}
+
+// getTabSummary sums this month's spending in the tab title, 0 when the tab
+// does not exist yet.
+func (b *Bot) getTabSummary(ctx context.Context, title string) (int, error) {
+	titles, err := storeWithContext(ctx, b.store).SheetTitles()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get sheet titles: %w", err)
+	}
+	if !slices.Contains(titles, title) {
+		return 0, nil
+	}
+	rows, err := b.getRowsIn(ctx, title)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, row := range rows {
+		if !row.Date.Before(monthStart()) {
+			total += row.Nominal
+		}
+	}
+	return total, nil
+}
+
+// getCombinedSummary sums this month's spending of every account of chatID
+// and of the joint sheet it shares.
+func (b *Bot) getCombinedSummary(ctx context.Context, chatID int64) (int, error) {
+	accounts, err := b.getAccounts(ctx, chatID)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	if pref, _ := b.getPreference(chatID); pref.JointSheet != "" {
+		if total, err = b.getTabSummary(ctx, pref.JointSheet); err != nil {
+			return 0, err
+		}
+	}
+	for _, account := range accounts {
+		sum, err := b.getAccountSummary(ctx, chatID, account)
+		if err != nil {
+			return 0, err
+		}
+		total += sum
+	}
+	return total, nil
+}
+
+// formatAccounts lists the accounts of chatID with this month's total of
+// each, marking the active one.
+func (b *Bot) formatAccounts(ctx context.Context, chatID int64) (string, error) {
+	accounts, err := b.getAccounts(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+	pref, _ := b.getPreference(chatID)
+
+	var result strings.Builder
+	result.WriteString("👛 Akun kamu (bulan ini):\n\n")
+	total := 0
+	for _, account := range accounts {
+		sum, err := b.getAccountSummary(ctx, chatID, account)
+		if err != nil {
+			return "", err
+		}
+		total += sum
+		marker := "  "
+		if account == pref.account() {
+			marker = "▶️"
+		}
+		result.WriteString(fmt.Sprintf("%s %s: Rp %s\n", marker, account, formatRupiah(sum)))
+	}
+	result.WriteString(fmt.Sprintf("\n💰 Total gabungan: Rp %s", formatRupiah(total)))
+	return result.String(), nil
+}
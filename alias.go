@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const aliasesSheet = "Aliases"
+
+var aliasesHeader = []interface{}{"ChatID", "Shortcut", "FullCategory"}
+
+// loadAliases reads the Aliases tab into b.aliases, once.
+func (b *Bot) loadAliases(ctx context.Context) error {
+	b.mu.RLock()
+	loaded := b.aliases != nil
+	b.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	if err := b.ensureTab(ctx, aliasesSheet, aliasesHeader); err != nil {
+		return err
+	}
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(aliasesSheet, "A:C"))
+	if err != nil {
+		return fmt.Errorf("failed to get aliases: %w", err)
+	}
+
+	aliases := make(map[int64]map[string]string)
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 { // Skip header
+			continue
+		}
+		chatID, err := strconv.ParseInt(fmt.Sprintf("%v", row[0]), 10, 64)
+		if err != nil {
+			continue
+		}
+		if aliases[chatID] == nil {
+			aliases[chatID] = make(map[string]string)
+		}
+		aliases[chatID][strings.ToLower(fmt.Sprintf("%v", row[1]))] = fmt.Sprintf("%v", row[2])
+	}
+
+	b.mu.Lock()
+	b.aliases = aliases
+	b.mu.Unlock()
+	return nil
+}
+
+// expandAlias returns the category a shortcut of chatID stands for, or
+// category itself when it is not a shortcut.
+func (b *Bot) expandAlias(ctx context.Context, chatID int64, category string) string {
+	if err := b.loadAliases(ctx); err != nil {
+		log.Printf("failed to load aliases: %v", err)
+		return category
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if full, ok := b.aliases[chatID][strings.ToLower(strings.TrimSpace(category))]; ok {
+		return full
+	}
+	return category
+}
+
+// aliasRow returns the row of a shortcut of chatID in the Aliases tab, or 0.
+func (b *Bot) aliasRow(ctx context.Context, chatID int64, shortcut string) (int, error) {
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(aliasesSheet, "A:B"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get aliases: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	for i, row := range rows {
+		if i > 0 && len(row) > 1 && fmt.Sprintf("%v", row[0]) == id && strings.EqualFold(fmt.Sprintf("%v", row[1]), shortcut) {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (b *Bot) setAlias(ctx context.Context, chatID int64, shortcut, category string) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.loadAliases(ctx); err != nil {
+		return err
+	}
+
+	shortcut = strings.ToLower(shortcut)
+	row, err := b.aliasRow(ctx, chatID, shortcut)
+	if err != nil {
+		return err
+	}
+
+	values := [][]interface{}{{strconv.FormatInt(chatID, 10), shortcut, category}}
+	if row > 0 {
+		err = store.Update(sheetRange(aliasesSheet, fmt.Sprintf("A%d", row)), values)
+	} else {
+		err = store.Append(sheetRange(aliasesSheet, "A1"), values)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save alias: %w", err)
+	}
+
+	b.mu.Lock()
+	if b.aliases[chatID] == nil {
+		b.aliases[chatID] = make(map[string]string)
+	}
+	b.aliases[chatID][shortcut] = category
+	b.mu.Unlock()
+	return nil
+}
+
+// deleteAlias removes a shortcut of chatID and reports whether it existed.
+func (b *Bot) deleteAlias(ctx context.Context, chatID int64, shortcut string) (bool, error) {
+	if err := b.loadAliases(ctx); err != nil {
+		return false, err
+	}
+
+	row, err := b.aliasRow(ctx, chatID, shortcut)
+	if err != nil || row == 0 {
+		return false, err
+	}
+	if err := storeWithContext(ctx, b.store).Clear(sheetRange(aliasesSheet, fmt.Sprintf("A%d:C%d", row, row))); err != nil {
+		return false, fmt.Errorf("failed to delete alias: %w", err)
+	}
+
+	b.mu.Lock()
+	delete(b.aliases[chatID], strings.ToLower(shortcut))
+	b.mu.Unlock()
+	return true, nil
+}
+
+func (b *Bot) formatAliases(ctx context.Context, chatID int64) (string, error) {
+	if err := b.loadAliases(ctx); err != nil {
+		return "", err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	aliases := b.aliases[chatID]
+	if len(aliases) == 0 {
+		return "🔤 Belum ada alias. Gunakan /alias <singkatan> <kategori>", nil
+	}
+
+	shortcuts := make([]string, 0, len(aliases))
+	for shortcut := range aliases {
+		shortcuts = append(shortcuts, shortcut)
+	}
+	sort.Strings(shortcuts)
+
+	var result strings.Builder
+	result.WriteString("🔤 Alias kategori:\n\n")
+	for _, shortcut := range shortcuts {
+		result.WriteString(fmt.Sprintf("• %s → %s\n", shortcut, aliases[shortcut]))
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// editSessionTTL is how long /edit waits for the new values of an entry
+// before the edit counts as abandoned.
+const editSessionTTL = 10 * time.Minute
+
+// editSession is an /edit waiting for the new values of an entry.
+type editSession struct {
+	row     int
+	started time.Time
+}
+
+// EditAnalytics counts how /edit sessions end, to see how often users go
+// through with correcting an entry. A session is cancelled by /cancel, by
+// timing out, by failing to save, or by starting another /edit.
+type EditAnalytics struct {
+	EditStarted   int
+	EditCompleted int
+	EditCancelled int
+}
+
+func (a *EditAnalytics) add(other EditAnalytics) {
+	a.EditStarted += other.EditStarted
+	a.EditCompleted += other.EditCompleted
+	a.EditCancelled += other.EditCancelled
+}
+
+// countEdit applies count to the edit analytics of chatID. b.mu must be
+// held.
+func (b *Bot) countEdit(chatID int64, count func(a *EditAnalytics)) {
+	stats := b.editStats[chatID]
+	count(&stats)
+	b.editStats[chatID] = stats
+}
+
+func (b *Bot) editingRow(chatID int64) (int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	session, ok := b.editingState[chatID]
+	if ok && time.Since(session.started) > editSessionTTL {
+		delete(b.editingState, chatID)
+		b.countEdit(chatID, func(a *EditAnalytics) { a.EditCancelled++ })
+		return 0, false
+	}
+	return session.row, ok
+}
+
+func (b *Bot) setEditing(chatID int64, row int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.editingState[chatID]; ok {
+		b.countEdit(chatID, func(a *EditAnalytics) { a.EditCancelled++ })
+	}
+	b.editingState[chatID] = editSession{row: row, started: time.Now()}
+	b.countEdit(chatID, func(a *EditAnalytics) { a.EditStarted++ })
+}
+
+// finishEditing ends the /edit of chatID, counting it as completed when the
+// entry was saved and as cancelled otherwise.
+func (b *Bot) finishEditing(chatID int64, completed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.editingState[chatID]; !ok {
+		return
+	}
+	delete(b.editingState, chatID)
+	b.countEdit(chatID, func(a *EditAnalytics) {
+		if completed {
+			a.EditCompleted++
+		} else {
+			a.EditCancelled++
+		}
+	})
+}
+
+// getEditAnalytics sums the edit analytics of every user.
+func (b *Bot) getEditAnalytics() (total EditAnalytics, users int) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, stats := range b.editStats {
+		total.add(stats)
+	}
+	return total, len(b.editStats)
+}
+
+func (a EditAnalytics) format(users int) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("✏️ Statistik /edit (%d pengguna):\n\n", users))
+	result.WriteString(fmt.Sprintf("Dimulai: %d\n", a.EditStarted))
+	result.WriteString(fmt.Sprintf("Selesai: %d\n", a.EditCompleted))
+	result.WriteString(fmt.Sprintf("Dibatalkan: %d\n", a.EditCancelled))
+	if a.EditStarted > 0 {
+		result.WriteString(fmt.Sprintf("\n✅ Tingkat penyelesaian: %d%%", a.EditCompleted*100/a.EditStarted))
+	}
+	return result.String()
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditSheet records every change the bot makes to a user's data, so that
+// they can check it with /log.
+const auditSheet = "AuditLog"
+
+var auditHeader = []interface{}{"Timestamp", "ChatID", "Action", "RowAffected", "OldValue", "NewValue"}
+
+// auditLogEntries is how many events /log shows.
+const auditLogEntries = 10
+
+// Actions of the audit log.
+const (
+	auditAppend           = "append"
+	auditEdit             = "edit"
+	auditDelete           = "delete"
+	auditPreferenceChange = "preference-change"
+)
+
+// auditActionLabels are the actions as shown by /log.
+var auditActionLabels = map[string]string{
+	auditAppend:           "➕ Tambah",
+	auditEdit:             "✏️ Edit",
+	auditDelete:           "🗑 Hapus",
+	auditPreferenceChange: "⚙️ Pengaturan",
+}
+
+// AuditEvent is a row of the audit log.
+type AuditEvent struct {
+	Timestamp string
+	Action    string
+	Row       int // 0 for changes that are not to an entry
+	OldValue  string
+	NewValue  string
+}
+
+// auditEntryValue describes an entry in the audit log. The description is
+// kept as stored, so an encrypted one stays encrypted in the log.
+func auditEntryValue(nominal int, category, description string) string {
+	return fmt.Sprintf("Rp %s | %s | %s", formatRupiah(nominal), category, description)
+}
+
+// auditRowValue describes a row of the expense tab with auditEntryValue and
+// returns its owner, or empty strings when the row is not an entry.
+func (b *Bot) auditRowValue(raw []interface{}) (value, owner string) {
+	row, err := b.sheetConfig.parseRow(raw)
+	if err != nil {
+		return "", ""
+	}
+	return auditEntryValue(row.Nominal, row.Category, row.Description), refChatID(row.Ref)
+}
+
+// logAudit appends an event for chatID to the audit log. It only logs a
+// failure, the change itself has already been made.
+func (b *Bot) logAudit(ctx context.Context, chatID, action string, row int, oldValue, newValue string) {
+	if chatID == "" {
+		return
+	}
+	if err := b.ensureTab(ctx, auditSheet, auditHeader); err != nil {
+		log.Printf("failed to log %s of row %d: %v", action, row, err)
+		return
+	}
+
+	rowAffected := interface{}("")
+	if row > 0 {
+		rowAffected = row
+	}
+	values := [][]interface{}{{time.Now().Format("02-01-2006 15:04:05"), chatID, action, rowAffected, oldValue, newValue}}
+	if err := storeWithContext(ctx, b.store).Append(sheetRange(auditSheet, "A1"), values); err != nil {
+		log.Printf("failed to log %s of row %d: %v", action, row, err)
+	}
+}
+
+// preferenceChanges lists the fields that differ between two preferences as
+// "Header=value" pairs, using the Preferences tab header for the names.
+func preferenceChanges(old, updated UserPreference) (string, string) {
+	oldRow, newRow := preferenceRow(old), preferenceRow(updated)
+	var before, after []string
+	for i := 1; i < len(newRow); i++ { // Skip ChatID
+		oldCell, newCell := fmt.Sprintf("%v", oldRow[i]), fmt.Sprintf("%v", newRow[i])
+		if oldCell != newCell {
+			before = append(before, fmt.Sprintf("%v=%s", preferencesHeader[i], oldCell))
+			after = append(after, fmt.Sprintf("%v=%s", preferencesHeader[i], newCell))
+		}
+	}
+	return strings.Join(before, ", "), strings.Join(after, ", ")
+}
+
+// getAuditLog returns the last limit events of chatID, newest first.
+func (b *Bot) getAuditLog(ctx context.Context, chatID string, limit int) ([]AuditEvent, error) {
+	if err := b.ensureTab(ctx, auditSheet, auditHeader); err != nil {
+		return nil, err
+	}
+
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(auditSheet, "A:F"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+
+	var events []AuditEvent
+	for i := len(rows) - 1; i > 0 && len(events) < limit; i-- { // Row 0 is the header
+		cells := rows[i]
+		cell := func(col int) string {
+			if col < len(cells) && cells[col] != nil {
+				return fmt.Sprintf("%v", cells[col])
+			}
+			return ""
+		}
+		if cell(1) != chatID {
+			continue
+		}
+
+		event := AuditEvent{Timestamp: cell(0), Action: cell(2), OldValue: cell(4), NewValue: cell(5)}
+		event.Row, _ = strconv.Atoi(cell(3))
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// openAuditValue decrypts the description of an entry described by
+// auditEntryValue. Other values are returned unchanged.
+func (b *Bot) openAuditValue(chatID, action, value string) string {
+	if action == auditPreferenceChange {
+		return value
+	}
+	parts := strings.SplitN(value, " | ", 3)
+	if len(parts) < 3 {
+		return value
+	}
+	parts[2] = b.openDescription(chatID, parts[2])
+	return strings.Join(parts, " | ")
+}
+
+// formatAuditLog lists the events of chatID for /log.
+func (b *Bot) formatAuditLog(chatID string, events []AuditEvent) string {
+	if len(events) == 0 {
+		return "📜 Belum ada perubahan yang tercatat"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📜 %d Perubahan Terakhir pada Datamu:\n\n", len(events)))
+	for _, event := range events {
+		label, ok := auditActionLabels[event.Action]
+		if !ok {
+			label = event.Action
+		}
+		if event.Row > 0 {
+			label += fmt.Sprintf(" #%d", event.Row)
+		}
+		result.WriteString(fmt.Sprintf("🕒 %s - %s\n", event.Timestamp, label))
+		if event.OldValue != "" {
+			result.WriteString(fmt.Sprintf("   Sebelum: %s\n", b.openAuditValue(chatID, event.Action, event.OldValue)))
+		}
+		if event.NewValue != "" {
+			result.WriteString(fmt.Sprintf("   Sesudah: %s\n", b.openAuditValue(chatID, event.Action, event.NewValue)))
+		}
+		result.WriteString("\n")
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
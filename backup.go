@@ -0,0 +1,49 @@
+package main
+
+import "fmt"
+
+// backupToSheet copies every tab of src into dst, replacing what the backup
+// previously held, and returns the number of rows copied. Every tab is read
+// before the backup is touched, so a failed read leaves the last backup as
+// it was.
+func backupToSheet(src, dst SheetStore) (int, error) {
+	titles, err := src.SheetTitles()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list source sheets: %w", err)
+	}
+
+	tabs := make([][][]interface{}, len(titles))
+	for i, title := range titles {
+		rows, err := src.Get(sheetRange(title, "A:Z"))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read sheet %q: %w", title, err)
+		}
+		tabs[i] = rows
+	}
+
+	var data []RangeValues
+	total := 0
+	for i, title := range titles {
+		rows := tabs[i]
+		if err := dst.EnsureSheet(title); err != nil {
+			return 0, fmt.Errorf("failed to create backup sheet %q: %w", title, err)
+		}
+		if err := dst.Clear(sheetRange(title, "A:Z")); err != nil {
+			return 0, fmt.Errorf("failed to clear backup sheet %q: %w", title, err)
+		}
+
+		if len(rows) == 0 {
+			continue
+		}
+		data = append(data, RangeValues{Range: sheetRange(title, "A1"), Values: rows})
+		total += len(rows)
+	}
+
+	if len(data) == 0 {
+		return 0, nil
+	}
+	if err := dst.BatchUpdate(data); err != nil {
+		return 0, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,2093 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// UserPreference holds the per-chat settings of a user.
+type UserPreference struct {
+	ChatID       int64
+	Name         string
+	Timezone     string // IANA name, e.g. "Asia/Jakarta"
+	ReminderType ReminderType
+	MonthlyLimit int    // 0 when no limit is set
+	WeeklyLimit  int    // 0 when no limit is set
+	Language     string // key of Bot.messages, defaultLanguage when empty
+	DigestTime   string // "15:04" in the user's timezone, empty when the digest is off
+
+	WeeklyReportEnabled bool // send last week's summary on Monday morning
+
+	PausedUntil *time.Time // reminders are not sent before it, nil when they are not paused
+	ReportEmail string     // address last month's report is emailed to, empty when it is not
+	Account     string     // account new entries go to, see accountSheet, empty for defaultAccount
+	JointSheet  string     // tab shared with another chat after /subscribe, see jointSheet, empty when not shared
+}
+
+// Bot ties the Telegram API client to the store the expenses are kept in,
+// together with the per-chat state of the conversation.
+type Bot struct {
+	api         *tgbotapi.BotAPI
+	store       SheetStore
+	backupStore SheetStore // nil when backups are not configured
+	sheetConfig SheetConfig
+	namedRanges map[string]string // read once at startup, see loadNamedRanges
+
+	adminChatIDs map[int64]bool
+	messages     map[string]Messages // replies by language
+	tips         map[string][]string // /random tips by category
+
+	// appendMu serializes appendData, which reads where the last entry is
+	// before writing the next one below it.
+	appendMu sync.Mutex
+
+	mu           sync.RWMutex
+	readyTabs    map[string]bool
+	prefs        map[int64]UserPreference
+	editingState map[int64]editSession // Map to store which entry user is editing
+	pendingMerge map[int64]categoryMerge
+	pendingClear map[int64]string // category waiting on /clear category confirmation
+
+	pendingDeleteRange map[int64]rowSpan          // rows waiting on /delete range confirmation
+	pendingSubscribe   map[int64]subscribeRequest // /subscribe requests by the chat invited
+
+	conversationStates map[int64]conversationState // users in the onboarding wizard
+	pendingExpense     map[int64]pendingExpense    // expenses waiting on a category suggestion
+	pendingConversions map[int64]int               // converted IDR nominals waiting on a category and description
+	pendingMigrations  map[int64]map[string]bool   // refs of migrated entries waiting on confirmation to delete them
+	pendingVoice       map[int64]newExpense        // expenses transcribed from voice messages waiting on confirmation
+	pendingCheckin     map[int64]time.Time         // when each /checkin waiting on a location expires
+	lastRecorded       map[int64]recordedEntry     // the entry each chat recorded last, for tagging it with a location
+
+	deadLetterMu   sync.Mutex
+	failedMessages []FailedMessage // sends that failed every retry, oldest first
+
+	historyMu        sync.Mutex
+	operationHistory map[int64][]HistoryEntry // changes /undo can revert, newest last
+
+	limitWarnings     map[int64]int       // highest limit threshold warned about this month
+	limitWarningMonth string              // month limitWarnings belongs to, as "2006-01"
+	goalReportMonth   map[int64]string    // month each user's goal report was last sent for
+	weeklyReportDay   map[int64]string    // Monday each user's weekly report was last sent on
+	reportEmailMonth  map[int64]string    // month each user's report email was last sent in
+	reminderSent      map[int64]time.Time // when each user's reminder was last sent, since the bot started
+
+	categoryIndex categoryIndex
+	aliases       map[int64]map[string]string // shortcut to category per chat, nil until loaded
+
+	messageRowMap map[int64]map[int]int // chat ID to message ID to the row recorded from it
+	pendingResets map[int64]pendingReset
+	editStats     map[int64]EditAnalytics // how each user's /edit sessions ended
+
+	receipts    ReceiptStore // nil when receipts are not saved
+	transcriber Transcriber  // nil when voice messages are not transcribed
+	mailer      Mailer       // nil when reports are not emailed
+	callbacks   *CallbackQueryRouter
+
+	// openSpreadsheet opens another Google Spreadsheet by ID for /migrate,
+	// with its requests tied to ctx. It is nil when the bot does not store
+	// its data in Google Sheets.
+	openSpreadsheet func(ctx context.Context, spreadsheetID string) (SheetStore, error)
+
+	charts chartCache
+	rates  rateCache
+
+	cipher              *descriptionCipher // nil when no ENCRYPTION_KEY is set
+	encryptDescriptions bool               // encrypt the description of new entries with cipher
+
+	inlineQueries bool // answer inline queries, see handleInlineQuery
+}
+
+// categoryMerge is a /merge waiting for the user to confirm it.
+type categoryMerge struct {
+	from, to string
+}
+
+// NewBot connects to Telegram with token and returns a bot keeping its data
+// in store.
+func NewBot(token string, store SheetStore) (*Bot, error) {
+	api, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot API client: %w", err)
+	}
+	return NewBotWithAPI(api, store)
+}
+
+// NewBotWithAPI returns a bot that talks to Telegram through api, which is
+// not contacted until the bot sends something. Tests use it with an api
+// whose Client answers offline.
+func NewBotWithAPI(api *tgbotapi.BotAPI, store SheetStore) (*Bot, error) {
+	messages, err := loadMessages()
+	if err != nil {
+		return nil, err
+	}
+
+	tips, err := loadTips()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bot{
+		api:          api,
+		store:        store,
+		sheetConfig:  defaultSheetConfig,
+		adminChatIDs: make(map[int64]bool),
+		messages:     messages,
+		tips:         tips,
+		readyTabs:    make(map[string]bool),
+		prefs:        make(map[int64]UserPreference),
+		editingState: make(map[int64]editSession),
+		pendingMerge: make(map[int64]categoryMerge),
+		pendingClear: make(map[int64]string),
+
+		pendingDeleteRange: make(map[int64]rowSpan),
+		pendingSubscribe:   make(map[int64]subscribeRequest),
+
+		conversationStates: make(map[int64]conversationState),
+		pendingExpense:     make(map[int64]pendingExpense),
+		pendingConversions: make(map[int64]int),
+		pendingMigrations:  make(map[int64]map[string]bool),
+		pendingVoice:       make(map[int64]newExpense),
+		pendingCheckin:     make(map[int64]time.Time),
+		lastRecorded:       make(map[int64]recordedEntry),
+		limitWarnings:      make(map[int64]int),
+		goalReportMonth:    make(map[int64]string),
+		weeklyReportDay:    make(map[int64]string),
+		reportEmailMonth:   make(map[int64]string),
+		reminderSent:       make(map[int64]time.Time),
+		operationHistory:   make(map[int64][]HistoryEntry),
+		messageRowMap:      make(map[int64]map[int]int),
+		pendingResets:      make(map[int64]pendingReset),
+		editStats:          make(map[int64]EditAnalytics),
+	}
+	b.callbacks = b.newCallbackRouter()
+	return b, nil
+}
+
+func (b *Bot) isAdmin(chatID int64) bool {
+	return b.adminChatIDs[chatID]
+}
+
+// ensureTab makes sure the given tab exists and starts with a header row.
+// Tabs already checked since startup are not checked again.
+func (b *Bot) ensureTab(ctx context.Context, title string, header []interface{}) error {
+	store := storeWithContext(ctx, b.store)
+	b.mu.RLock()
+	ready := b.readyTabs[title]
+	b.mu.RUnlock()
+	if ready {
+		return nil
+	}
+
+	if err := store.EnsureSheet(title); err != nil {
+		return fmt.Errorf("failed to create sheet %q: %w", title, err)
+	}
+	rows, err := store.Get(sheetRange(title, "A1:Z1"))
+	if err != nil {
+		return fmt.Errorf("failed to read sheet %q: %w", title, err)
+	}
+	if len(rows) == 0 {
+		if err := store.Update(sheetRange(title, "A1"), [][]interface{}{header}); err != nil {
+			return fmt.Errorf("failed to write header of sheet %q: %w", title, err)
+		}
+	}
+
+	b.mu.Lock()
+	b.readyTabs[title] = true
+	b.mu.Unlock()
+	return nil
+}
+
+// handleUpdate handles an update from Telegram. The Sheets requests made for
+// it are cancelled with ctx, and it is dropped when ctx is done before it
+// starts.
+func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	if err := ctx.Err(); err != nil {
+		log.Printf("dropping update %d: %v", update.UpdateID, err)
+		return
+	}
+
+	if update.CallbackQuery != nil {
+		b.handleCallbackQuery(ctx, update.CallbackQuery)
+		return
+	}
+
+	if update.EditedMessage != nil {
+		b.handleEditedMessage(ctx, update.EditedMessage)
+		return
+	}
+
+	if update.InlineQuery != nil {
+		b.handleInlineQuery(ctx, update.InlineQuery)
+		return
+	}
+
+	if update.Message == nil {
+		return
+	}
+
+	chatId := update.Message.Chat.ID
+	text := update.Message.Text
+
+	if update.Message.Voice != nil {
+		b.handleVoice(ctx, update.Message)
+		return
+	}
+
+	if update.Message.Location != nil {
+		b.handleLocation(ctx, update.Message)
+		return
+	}
+
+	// A receipt photo is recorded from its caption
+	var receiptFileID string
+	if photos := update.Message.Photo; len(photos) > 0 {
+		text = update.Message.Caption
+		receiptFileID = photos[len(photos)-1].FileID // largest size last
+	}
+
+	// Check if user is in editing state
+	if editingRow, isEditing := b.editingRow(chatId); isEditing {
+		// User is in editing state, expect new data
+		if text == "/cancel" {
+			b.finishEditing(chatId, false)
+			b.sendMessage(tgbotapi.NewMessage(chatId, "🚫 Edit dibatalkan"))
+			return
+		}
+
+		parts := strings.Split(text, ",")
+		if len(parts) == 3 {
+			nominalStr := strings.TrimSpace(parts[0])
+			budget := b.expandAlias(ctx, chatId, strings.TrimSpace(parts[1]))
+			keterangan := strings.TrimSpace(parts[2])
+
+			normalizedNominal := normalizeNominal(nominalStr)
+			change, err := b.editEntry(ctx, editingRow, normalizedNominal, budget, keterangan)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengedit data."))
+				b.finishEditing(chatId, false)
+				return
+			}
+			b.pushHistory(chatId, change)
+
+			// Show the edited entry
+			editedEntry, _ := b.getEntryByNumber(ctx, editingRow)
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Data berhasil diedit:\n%s", editedEntry))
+			b.sendMessage(msg)
+			b.finishEditing(chatId, true)
+			return
+		} else {
+			b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).EditFormatError))
+			return
+		}
+	}
+
+	// A /reset is confirmed by sending back its code, anything else cancels it
+	if reset, ok := b.takePendingReset(chatId); ok {
+		if strings.TrimSpace(text) != reset.code {
+			b.sendMessage(tgbotapi.NewMessage(chatId, "🚫 Kode salah, reset dibatalkan"))
+			return
+		}
+
+		entries, err := b.resetUser(ctx, chatId)
+		if err != nil {
+			log.Printf("failed to reset %d: %v", chatId, err)
+			b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menghapus data. Sebagian data mungkin sudah terhapus, silakan coba /reset lagi"))
+			return
+		}
+		b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("🗑 Semua data kamu sudah dihapus (%d entri). Kirim /start untuk memulai dari awal", entries)))
+		return
+	}
+
+	// Complete an expense started from /convert, commands still work in between
+	if nominal, ok := b.pendingConversion(chatId); ok && !strings.HasPrefix(text, "/") {
+		parts := strings.Split(text, ",")
+		if len(parts) != 2 {
+			b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Kirim dalam format: Kategori, Keterangan\nContoh: Belanja, Kaos dari luar negeri"))
+			return
+		}
+		b.clearPendingConversion(chatId)
+
+		b.recordExpense(ctx, chatId, newExpense{
+			nominal:     nominal,
+			category:    b.expandAlias(ctx, chatId, strings.TrimSpace(parts[0])),
+			description: strings.TrimSpace(parts[1]),
+			date:        time.Now(),
+			messageID:   update.Message.MessageID,
+			messageRef:  messageRef(update.Message),
+			sender:      senderName(update.Message.From),
+		})
+		return
+	}
+
+	// Answer the onboarding wizard, commands still work in between
+	if state, inWizard := b.conversationState(chatId); inWizard && !strings.HasPrefix(text, "/") {
+		b.handleOnboardingMessage(chatId, text, state)
+		return
+	}
+
+	// Handle commands
+	if strings.HasPrefix(text, "/") {
+		switch {
+		case text == "/start":
+			// New users are walked through the onboarding wizard first
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.startOnboarding(chatId)
+				return
+			}
+
+			msg := tgbotapi.NewMessage(chatId, startText(b.msg(chatId).StartIntro, pref.Language))
+			b.sendMessage(msg)
+			return
+
+		case text == "/help" || strings.HasPrefix(text, "/help "):
+			topic := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(text, "/help")), "/"))
+			if topic == "" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).Help))
+				return
+			}
+
+			if topic == "reminder" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.reminderHelp(chatId)))
+				return
+			}
+
+			help, ok := commandHelp[topic]
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Tidak ada bantuan untuk /%s. Gunakan /help untuk melihat daftar perintah", topic)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, help))
+			return
+
+		case text == "/edit":
+			keyboard, err := b.editListKeyboard(ctx, 0)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data"))
+				return
+			}
+			if keyboard == nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "Belum ada data yang dimasukkan"))
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, "✏️ Pilih entri yang ingin diedit:")
+			msg.ReplyMarkup = keyboard
+			b.sendMessage(msg)
+			return
+
+		case strings.HasPrefix(text, "/edit "):
+			// Extract row number from command
+			rowNumberStr := strings.TrimSpace(strings.TrimPrefix(text, "/edit "))
+			rowNumber, err := strconv.Atoi(rowNumberStr)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nomor entri tidak valid. Gunakan format: /edit <nomor>"))
+				return
+			}
+			b.startEditing(ctx, chatId, rowNumber)
+			return
+
+		case text == "/summary":
+			// The total covers every account of the chat, see /summary <account>
+			summary, err := b.getCombinedSummary(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil total pengeluaran"))
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("📊 Total pengeluaran bulan ini: Rp. %d", summary))
+			b.sendMessage(msg)
+			return
+
+		case text == "/summary chart":
+			now := time.Now()
+			rows, err := b.getEntriesBetween(ctx, now.AddDate(0, 0, -(trendDays-1)), now)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran harian"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("📈 Pengeluaran harian %d hari terakhir:\n\n%s", trendDays, renderDailyTimeline(rows, trendDays))))
+			return
+
+		case strings.HasPrefix(text, "/summary category"):
+			category := normalizeCategory(strings.TrimPrefix(text, "/summary category"))
+			if category == "" {
+				summary := b.getSummary(ctx, monthStart())
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("📊 Total pengeluaran bulan ini: Rp. %d", summary)))
+				return
+			}
+
+			total, err := b.getSummaryByCategory(ctx, category, monthStart())
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil total kategori"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("📊 Total %s: Rp %s (bulan ini)", category, formatRupiah(total))))
+			return
+
+		case strings.HasPrefix(text, "/summary "):
+			account := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, "/summary")))
+			accounts, err := b.getAccounts(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil daftar akun"))
+				return
+			}
+			if !slices.Contains(accounts, account) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Akun %s tidak ditemukan. Lihat daftarnya dengan /account list", account)))
+				return
+			}
+
+			total, err := b.getAccountSummary(ctx, chatId, account)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil total pengeluaran"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("📊 Total akun %s: Rp %s (bulan ini)", account, formatRupiah(total))))
+			return
+
+		case text == "/account" || strings.HasPrefix(text, "/account "):
+			arg := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, "/account")))
+			if arg == "list" {
+				list, err := b.formatAccounts(ctx, chatId)
+				if err != nil {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil daftar akun"))
+					return
+				}
+				b.sendMessage(tgbotapi.NewMessage(chatId, list))
+				return
+			}
+
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+			if arg == "" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("👛 Akun aktif: %s\nGunakan: /account <nama> untuk pindah akun, /account list untuk daftar akun", pref.account())))
+				return
+			}
+			if !accountName.MatchString(arg) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nama akun hanya boleh huruf dan angka, maksimal 20 karakter\nContoh: /account bisnis"))
+				return
+			}
+
+			pref.Account = arg
+			if arg == defaultAccount {
+				pref.Account = ""
+			}
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengganti akun"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Akun aktif sekarang %s. Pengeluaran berikutnya dicatat di akun ini.", arg)))
+			return
+
+		case text == "/subscribe" || strings.HasPrefix(text, "/subscribe "):
+			target, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(text, "/subscribe")), 10, 64)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /subscribe <chat ID>\nChat ID bisa dilihat dengan /whoami"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, b.requestSubscription(chatId, target)))
+			return
+
+		case text == "/unsubscribe":
+			pref, _ := b.getPreference(chatId)
+			partners := b.jointPartners(chatId, pref.JointSheet)
+			copied, err := b.unsubscribe(ctx, chatId)
+			if errors.Is(err, errNotSubscribed) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "ℹ️ Kamu tidak sedang mencatat pengeluaran bersama"))
+				return
+			}
+			if err != nil {
+				log.Printf("failed to unsubscribe %d: %v", chatId, err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Gagal menyalin entri bersama, %d entri sudah tersalin", copied)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Berhenti mencatat bersama. %d entri bersama disalin ke akunmu sendiri.", copied)))
+			for _, partner := range partners {
+				b.sendMessage(tgbotapi.NewMessage(partner, fmt.Sprintf("ℹ️ Chat %d berhenti mencatat pengeluaran bersama", chatId)))
+			}
+			return
+
+		case text == "/total":
+			total := b.getSummary(ctx, time.Time{})
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("📊 Total seluruh pengeluaran: Rp. %d", total))
+			b.sendMessage(msg)
+			return
+
+		case strings.HasPrefix(text, "/weekly target"):
+			if _, ok := b.getPreference(chatId); !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			amount := strings.TrimSpace(strings.TrimPrefix(text, "/weekly target"))
+			limit := normalizeNominal(amount)
+			if amount == "" || limit < 0 || (limit == 0 && amount != "0") {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /weekly target <nominal>\nContoh: /weekly target 700rb"))
+				return
+			}
+
+			if err := b.setWeeklyLimit(ctx, chatId, limit); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan target mingguan"))
+				return
+			}
+			if limit == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "✅ Target mingguan dihapus"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf(
+				"✅ Target mingguan Rp %s telah diatur. Kamu akan mendapat peringatan di %d%% dan %d%%.",
+				formatRupiah(limit), limitThresholdNotice, limitThresholdReached)))
+			return
+
+		case text == "/weekly status":
+			status, err := b.getWeeklyStatus(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran mingguan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, status))
+			return
+
+		case text == "/weekly":
+			weeklySummary, err := b.getWeeklySummary(ctx)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran mingguan")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, weeklySummary)
+			b.sendMessage(msg)
+			return
+
+		case strings.HasPrefix(text, "/week "):
+			var year, week int
+			arg := strings.TrimSpace(strings.TrimPrefix(text, "/week "))
+			if _, err := fmt.Sscanf(arg, "%d-%d", &year, &week); err != nil || week < 1 || week > 53 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /week <tahun>-<minggu>\nContoh: /week 2024-03"))
+				return
+			}
+			if y, w := isoWeekStart(year, week).ISOWeek(); y != year || w != week {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Tahun %d tidak punya minggu ke-%d", year, week)))
+				return
+			}
+
+			// Older weeks are not supported to keep scans of the sheet short
+			if isoWeekStart(year, week).Before(time.Now().AddDate(0, 0, -52*7)) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Minggu yang diminta lebih dari 52 minggu yang lalu"))
+				return
+			}
+
+			weekSummary, err := b.getWeekSummaryByISOWeek(ctx, year, week)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran mingguan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, weekSummary))
+			return
+
+		case text == "/this_week_vs_budget":
+			comparison, err := b.getWeekVsBudget(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membandingkan pengeluaran dengan budget"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, comparison))
+			return
+
+		case text == "/ping":
+			if !b.isAdmin(chatId) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Perintah ini hanya untuk admin"))
+				return
+			}
+			started := time.Now()
+			if err := storeWithContext(ctx, b.store).Ping(); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "🚨 Spreadsheet tidak bisa diakses: "+err.Error()))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("🏓 Pong! Spreadsheet terhubung (%d ms)", time.Since(started).Milliseconds())))
+			return
+
+		case text == "/sync":
+			if !b.isAdmin(chatId) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Perintah ini hanya untuk admin"))
+				return
+			}
+			loaded, err := b.loadUserPreferences(ctx)
+			if err != nil {
+				log.Printf("failed to sync preferences: %v", err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membaca tab Preferences"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Sinkronisasi selesai. %d preferensi dimuat.", loaded)))
+			return
+
+		case text == "/stats edits":
+			if !b.isAdmin(chatId) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Perintah ini hanya untuk admin"))
+				return
+			}
+			stats, users := b.getEditAnalytics()
+			b.sendMessage(tgbotapi.NewMessage(chatId, stats.format(users)))
+			return
+
+		case text == "/stats" || strings.HasPrefix(text, "/stats "):
+			if strings.TrimSpace(strings.TrimPrefix(text, "/stats")) != "weekly_comparison" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /stats weekly_comparison"))
+				return
+			}
+
+			comparison, err := b.compareWeeks(ctx)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membandingkan pengeluaran mingguan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, comparison))
+			return
+
+		case text == "/monthly goals":
+			now := time.Now()
+			if pref, ok := b.getPreference(chatId); ok {
+				now = now.In(pref.location())
+			}
+			progress, err := b.getMonthGoalProgress(ctx, chatId, now.Year(), now.Month())
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil target kategori"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, formatGoalProgress(progress, now.Year(), now.Month())))
+			return
+
+		case text == "/goal" || strings.HasPrefix(text, "/goal "):
+			args := strings.Fields(strings.TrimPrefix(text, "/goal"))
+			if len(args) < 2 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /goal <kategori> <nominal>\nContoh: /goal Makanan 1,5jt"))
+				return
+			}
+
+			category := normalizeCategory(strings.Join(args[:len(args)-1], " "))
+			amount := args[len(args)-1]
+			target := normalizeNominal(amount)
+			if target < 0 || (target == 0 && amount != "0") {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nominal tidak valid"))
+				return
+			}
+
+			if err := b.setGoal(ctx, chatId, category, target); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan target kategori"))
+				return
+			}
+			if target == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Target kategori %s dihapus", category)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Target bulanan %s diatur ke Rp %s", category, formatRupiah(target))))
+			return
+
+		case text == "/report monthly" || strings.HasPrefix(text, "/report monthly "):
+			month := time.Now()
+			if arg := strings.TrimSpace(strings.TrimPrefix(text, "/report monthly")); arg != "" {
+				parsed, err := time.ParseInLocation("2006-01", arg, time.Local)
+				if err != nil {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /report monthly <tahun>-<bulan>\nContoh: /report monthly 2024-07"))
+					return
+				}
+				month = parsed
+			}
+
+			report, err := b.getMonthlyReport(ctx, month)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat laporan bulanan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, report.format()))
+			return
+
+		case text == "/monthly_report email" || strings.HasPrefix(text, "/monthly_report email "):
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+			if b.mailer == nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Pengiriman email belum diatur di bot ini"))
+				return
+			}
+
+			arg := strings.TrimSpace(strings.TrimPrefix(text, "/monthly_report email"))
+			if arg == "" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /monthly_report email <alamat>\nContoh: /monthly_report email nama@contoh.com"))
+				return
+			}
+			address, err := mail.ParseAddress(arg)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Alamat email tidak valid"))
+				return
+			}
+
+			if err := b.emailMonthlyReport(ctx, address.Address, time.Now()); err != nil {
+				log.Printf("failed to email monthly report to %d: %v", chatId, err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengirim laporan ke email"))
+				return
+			}
+			pref.ReportEmail = address.Address
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("⚠️ Laporan telah dikirim ke %s, tapi alamatnya gagal disimpan untuk bulan berikutnya.", address.Address)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Laporan telah dikirim ke %s.", address.Address)))
+			return
+
+		case text == "/report email off":
+			pref, ok := b.getPreference(chatId)
+			if !ok || pref.ReportEmail == "" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "ℹ️ Laporan bulanan tidak sedang dikirim ke email"))
+				return
+			}
+
+			pref.ReportEmail = ""
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mematikan laporan email"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, "✅ Laporan bulanan tidak akan dikirim ke email lagi"))
+			return
+
+		case text == "/monthly_summary_to_sheet" || strings.HasPrefix(text, "/monthly_summary_to_sheet "):
+			month := time.Now()
+			if arg := strings.TrimSpace(strings.TrimPrefix(text, "/monthly_summary_to_sheet")); arg != "" {
+				parsed, err := time.ParseInLocation("2006-01", arg, time.Local)
+				if err != nil {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /monthly_summary_to_sheet <tahun>-<bulan>\nContoh: /monthly_summary_to_sheet 2024-07"))
+					return
+				}
+				month = parsed
+			}
+
+			report, err := b.getMonthlyReport(ctx, month)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat laporan bulanan"))
+				return
+			}
+			col, err := b.writeMonthlySummaryToSheet(ctx, report)
+			if err != nil {
+				log.Printf("failed to write monthly summary: %v", err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menulis ringkasan ke spreadsheet"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Ringkasan %s %d ditulis ke tab %s, kolom %s",
+				monthNames[report.Month.Month()-1], report.Month.Year(), summarySheet, col)))
+			return
+
+		case text == "/monthly target" || strings.HasPrefix(text, "/monthly target "):
+			if _, ok := b.getPreference(chatId); !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			amount := strings.TrimSpace(strings.TrimPrefix(text, "/monthly target"))
+			target := normalizeNominal(amount)
+			if target <= 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /monthly target <nominal>\nContoh: /monthly target 3jt"))
+				return
+			}
+
+			if err := b.setMonthlyLimit(ctx, chatId, target); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan target bulanan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf(
+				"✅ Target bulanan Rp %s telah diatur. Kamu akan mendapat peringatan di %d%%, %d%%, dan %d%%.",
+				formatRupiah(target), limitThresholdNotice, limitThresholdWarning, limitThresholdReached)))
+			return
+
+		case text == "/monthly breakdown":
+			breakdown, err := b.getMonthlyBreakdown(ctx)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran bulanan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, breakdown))
+			return
+
+		case text == "/monthly":
+			monthlySummary, pages, err := b.getMonthlySummaryPage(ctx, 0)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran bulanan")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, monthlySummary)
+			if keyboard := monthlyPageKeyboard(0, pages); keyboard != nil {
+				msg.ReplyMarkup = keyboard
+			}
+			b.sendMessage(msg)
+			return
+
+		case text == "/today":
+			daily, err := b.getDailySummary(ctx, time.Now())
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran hari ini")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, formatDailySummary(daily))
+			b.sendMessage(msg)
+			return
+
+		case text == "/weekend":
+			weekendSummary, err := b.getWeekendSummary(ctx)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran akhir pekan")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, weekendSummary)
+			b.sendMessage(msg)
+			return
+
+		case text == "/compare categories" || strings.HasPrefix(text, "/compare categories "):
+			arg := strings.TrimSpace(strings.TrimPrefix(text, "/compare categories"))
+			categories := strings.Fields(arg)
+			if strings.Contains(arg, ",") {
+				categories = strings.Split(arg, ",")
+			}
+			if len(categories) != 2 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /compare categories <kategori1> <kategori2>\n"+
+					"Contoh: /compare categories Makanan Kopi\nPisahkan dengan koma untuk kategori lebih dari satu kata"))
+				return
+			}
+
+			comparison, err := b.compareCategoriesMonthly(ctx, normalizeCategory(categories[0]), normalizeCategory(categories[1]), compareMonths)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membandingkan kategori"))
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, comparison)
+			msg.ParseMode = tgbotapi.ModeHTML
+			b.sendMessage(msg)
+			return
+
+		case text == "/year_vs_year" || strings.HasPrefix(text, "/year_vs_year "):
+			args := strings.Fields(strings.TrimPrefix(text, "/year_vs_year"))
+			var years [2]int
+			valid := len(args) == 2
+			for i := 0; valid && i < 2; i++ {
+				year, err := strconv.Atoi(args[i])
+				years[i] = year
+				valid = err == nil && year >= 2000 && year <= time.Now().Year()
+			}
+			if !valid {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /year_vs_year <tahun1> <tahun2>\nContoh: /year_vs_year 2023 2024"))
+				return
+			}
+
+			comparison, err := b.compareYears(ctx, years[0], years[1])
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membandingkan tahun"))
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, comparison)
+			msg.ParseMode = tgbotapi.ModeHTML
+			b.sendMessage(msg)
+			return
+
+		case text == "/random":
+			tip, err := b.getRandomTip(ctx)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil tips keuangan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, tip))
+			return
+
+		case text == "/forecast" || strings.HasPrefix(text, "/forecast "):
+			days := 0
+			if arg := strings.TrimSpace(strings.TrimPrefix(text, "/forecast")); arg != "" {
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 1 {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /forecast [jumlah hari]\nContoh: /forecast 7"))
+					return
+				}
+				days = n
+			}
+
+			forecast, err := b.getForecast(ctx, chatId, days)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menghitung proyeksi pengeluaran"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, forecast))
+			return
+
+		case text == "/leaderboard":
+			if update.Message.Chat.IsPrivate() {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ /leaderboard hanya bisa dipakai di grup"))
+				return
+			}
+			leaderboard, err := b.getLeaderboard(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat leaderboard"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, formatLeaderboard(leaderboard)))
+			return
+
+		case text == "/trend":
+			trend, err := b.getTrend(ctx)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil tren pengeluaran")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, trend)
+			b.sendMessage(msg)
+			return
+
+		case text == "/cost_per_day" || strings.HasPrefix(text, "/cost_per_day "):
+			args := strings.Fields(strings.TrimPrefix(text, "/cost_per_day"))
+			if len(args) == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /cost_per_day <kategori> [hari]\nContoh: /cost_per_day Kopi"))
+				return
+			}
+
+			days := costPerDayDays
+			if n, err := strconv.Atoi(args[len(args)-1]); err == nil && len(args) > 1 {
+				if n < 1 {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Jumlah hari minimal 1"))
+					return
+				}
+				days = n
+				args = args[:len(args)-1]
+			}
+			category := normalizeCategory(strings.Join(args, " "))
+
+			average, activeDays, err := b.getDailyCostByCategory(ctx, category, days)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menghitung rata-rata harian"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf(
+				"☕ Rata-rata harian %s: Rp %s/hari selama %d hari\n📅 Ada pengeluaran di %d dari %d hari",
+				category, formatRupiah(int(math.Round(average))), days, activeDays, days)))
+			return
+
+		case text == "/chart bar monthly" || strings.HasPrefix(text, "/chart bar monthly "):
+			months := barChartMonths
+			if arg := strings.TrimSpace(strings.TrimPrefix(text, "/chart bar monthly")); arg != "" {
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 1 || n > barChartMonths {
+					b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Jumlah bulan harus antara 1 dan %d\nContoh: /chart bar monthly 6", barChartMonths)))
+					return
+				}
+				months = n
+			}
+
+			totals, err := b.getMonthlyTotals(ctx, months)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat grafik"))
+				return
+			}
+			sum := 0
+			for _, month := range totals {
+				sum += month.Total
+			}
+			if sum <= 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("Tidak ada pengeluaran dalam %d bulan terakhir", months)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("📊 Pengeluaran %d bulan terakhir (%s %d – %s %d)\n\n%s\n💰 Total: Rp %s\n📈 Rata-rata: Rp %s/bulan",
+				months,
+				monthNames[totals[0].Month.Month()-1], totals[0].Month.Year(),
+				monthNames[totals[months-1].Month.Month()-1], totals[months-1].Month.Year(),
+				renderMonthlyBarChart(totals), formatRupiah(sum), formatRupiah(sum/months))))
+			return
+
+		case text == "/chart" || text == "/chart pie":
+			pie, ok, err := b.getPieChart(ctx, chatId)
+			if err != nil {
+				log.Printf("failed to make pie chart: %v", err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat grafik"))
+				return
+			}
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "Tidak ada pengeluaran bulan ini"))
+				return
+			}
+
+			photo := tgbotapi.NewPhoto(chatId, tgbotapi.FileBytes{Name: "chart.png", Bytes: pie.png})
+			photo.Caption = pie.caption
+			b.sendMessage(photo)
+			return
+
+		case text == "/convert" || strings.HasPrefix(text, "/convert "):
+			args := strings.Fields(strings.TrimPrefix(text, "/convert"))
+			if len(args) != 3 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /convert <nominal> <dari> <ke>\nContoh: /convert 100 USD IDR"))
+				return
+			}
+			amount, ok := parseAmount(args[0])
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nominal tidak valid"))
+				return
+			}
+			from, to := strings.ToUpper(args[1]), strings.ToUpper(args[2])
+
+			rate, err := b.rates.exchangeRate(from, to)
+			if err != nil {
+				log.Printf("failed to convert %s to %s: %v", from, to, err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Gagal mengambil kurs %s ke %s. Pastikan kode mata uang benar, contoh: USD, SGD, IDR", from, to)))
+				return
+			}
+
+			converted := amount * rate
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("💱 %s %s = %s (kurs: %s)",
+				formatDecimal(amount, 2), from, formatMoney(converted, to), formatRate(rate)))
+			if nominal := int(math.Round(converted)); to == "IDR" && nominal > 0 {
+				msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+					tgbotapi.NewInlineKeyboardRow(
+						tgbotapi.NewInlineKeyboardButtonData("📝 Catat sebagai pengeluaran?", fmt.Sprintf("convert_record:%d", nominal)),
+					),
+				)
+			}
+			b.sendMessage(msg)
+			return
+
+		case text == "/share":
+			summary, err := b.getMonthlyShareSummary(ctx, time.Now())
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran bulanan"))
+				return
+			}
+			if summary.Total <= 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "Tidak ada pengeluaran bulan ini"))
+				return
+			}
+
+			card, err := renderSummaryCard(summary)
+			if err != nil {
+				log.Printf("failed to render summary card: %v", err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat kartu ringkasan"))
+				return
+			}
+			photo := tgbotapi.NewPhoto(chatId, tgbotapi.FileBytes{Name: "ringkasan.png", Bytes: card})
+			photo.Caption = "📤 Ringkasan pengeluaran bulan ini, siap dibagikan"
+			b.sendMessage(photo)
+			return
+
+		case text == "/normalize categories":
+			if !b.isAdmin(chatId) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Perintah ini hanya untuk admin"))
+				return
+			}
+
+			count, err := b.normalizeCategories(ctx)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal menormalkan kategori")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ %d kategori berhasil dinormalkan", count))
+			b.sendMessage(msg)
+			return
+
+		case text == "/notify" || strings.HasPrefix(text, "/notify "):
+			if !b.isAdmin(chatId) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Perintah ini hanya untuk admin"))
+				return
+			}
+
+			announcement := strings.TrimSpace(strings.TrimPrefix(text, "/notify"))
+			if announcement == "" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /notify <pesan>\nContoh: /notify Bot akan maintenance malam ini"))
+				return
+			}
+
+			b.sendMessage(tgbotapi.NewMessage(chatId, "📣 Mengirim pengumuman ke semua pengguna..."))
+			// Sending is rate-limited, so don't hold up other updates meanwhile.
+			go func() {
+				sent, failed := b.broadcast("📣 " + announcement)
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Pengumuman terkirim ke %d pengguna, %d gagal", sent, failed)))
+			}()
+			return
+
+		case strings.HasPrefix(text, "/merge"):
+			args := strings.Fields(strings.TrimPrefix(text, "/merge"))
+			if len(args) != 2 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /merge <kategori_lama> <kategori_baru>\nContoh: /merge Kopi Coffee"))
+				return
+			}
+
+			from, to := normalizeCategory(args[0]), normalizeCategory(args[1])
+			count, err := b.countCategory(ctx, from)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data kategori"))
+				return
+			}
+			if count == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Tidak ada entri dengan kategori \"%s\"", from)))
+				return
+			}
+
+			b.mu.Lock()
+			b.pendingMerge[chatId] = categoryMerge{from: from, to: to}
+			b.mu.Unlock()
+
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("🔀 %d entri dengan kategori \"%s\" akan diubah menjadi \"%s\". Lanjutkan?", count, from, to))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("✅ Ya", "merge_confirm"),
+					tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "merge_cancel"),
+				),
+			)
+			b.sendMessage(msg)
+			return
+
+		case strings.HasPrefix(text, "/clear category "):
+			category := normalizeCategory(strings.TrimPrefix(text, "/clear category "))
+			count, preview, err := b.previewCategory(ctx, chatId, category)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data kategori"))
+				return
+			}
+			if count == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Tidak ada entri dengan kategori \"%s\"", category)))
+				return
+			}
+
+			b.mu.Lock()
+			b.pendingClear[chatId] = category
+			b.mu.Unlock()
+
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("🗑 %d entri dengan kategori \"%s\" akan dihapus dan tidak bisa dibatalkan dengan /undo.\n\n%s\n\nLanjutkan?", count, category, preview))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("✅ Ya, hapus", "clear_confirm"),
+					tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "clear_cancel"),
+				),
+			)
+			b.sendMessage(msg)
+			return
+
+		case text == "/delete range" || strings.HasPrefix(text, "/delete range "):
+			args := strings.Fields(strings.TrimPrefix(text, "/delete range"))
+			var start, end int
+			var err error
+			if len(args) == 2 {
+				if start, err = strconv.Atoi(args[0]); err == nil {
+					end, err = strconv.Atoi(args[1])
+				}
+			}
+			if len(args) != 2 || err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /delete range <nomor_awal> <nomor_akhir>\nContoh: /delete range 10 15"))
+				return
+			}
+
+			count, preview, err := b.previewRowRange(ctx, chatId, start, end)
+			if errors.Is(err, errInvalidRowRange) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Rentang nomor tidak valid. Nomor awal minimal 2, tidak lebih besar dari nomor akhir, dan nomor akhir harus ada. Lihat nomor entri di /last"))
+				return
+			}
+			if errors.Is(err, errRowsNotOwned) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Rentang ini berisi entri milik pengguna lain. Kamu hanya bisa menghapus entrimu sendiri"))
+				return
+			}
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data"))
+				return
+			}
+			if count == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Tidak ada entri di nomor %d sampai %d", start, end)))
+				return
+			}
+
+			b.mu.Lock()
+			b.pendingDeleteRange[chatId] = rowSpan{start: start, end: end}
+			b.mu.Unlock()
+
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("🗑 %d entri di nomor %d sampai %d akan dihapus dan tidak bisa dibatalkan dengan /undo.\n\n%s\n\nLanjutkan?", count, start, end, preview))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("✅ Ya, hapus", "delete_range_confirm"),
+					tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "delete_range_cancel"),
+				),
+			)
+			b.sendMessage(msg)
+			return
+
+		case text == "/migrate" || strings.HasPrefix(text, "/migrate "):
+			args := strings.Fields(strings.TrimPrefix(text, "/migrate"))
+			if len(args) != 1 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /migrate <ID atau link spreadsheet>\nBagikan spreadsheet kamu ke email service account bot sebagai editor terlebih dahulu"))
+				return
+			}
+			if b.openSpreadsheet == nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Migrasi hanya tersedia jika bot menyimpan data di Google Sheets"))
+				return
+			}
+
+			dst, err := b.openSpreadsheet(ctx, spreadsheetIDFromLink(args[0]))
+			if err != nil {
+				log.Printf("failed to open migration spreadsheet: %v", err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Spreadsheet tidak bisa dibuka. Pastikan ID-nya benar dan spreadsheet sudah dibagikan ke email service account bot sebagai editor"))
+				return
+			}
+
+			count, refs, err := b.migrateData(ctx, dst, chatId)
+			if err != nil {
+				log.Printf("failed to migrate data: %v", err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal memigrasikan data"))
+				return
+			}
+			if count == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "Tidak ada data kamu untuk dimigrasikan"))
+				return
+			}
+
+			b.mu.Lock()
+			b.pendingMigrations[chatId] = refs
+			b.mu.Unlock()
+
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ %d baris berhasil dimigrasikan.\n\n"+
+				"Hapus data yang sudah dimigrasikan dari spreadsheet bersama? Penghapusan tidak bisa dibatalkan dengan /undo.", count))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("🗑 Ya, hapus", "migrate_delete"),
+					tgbotapi.NewInlineKeyboardButtonData("📄 Simpan", "migrate_keep"),
+				),
+			)
+			b.sendMessage(msg)
+			return
+
+		case text == "/backup":
+			if b.backupStore == nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Backup belum dikonfigurasi. Atur BACKUP_SPREADSHEET_ID terlebih dahulu"))
+				return
+			}
+
+			rows, err := backupToSheet(storeWithContext(ctx, b.store), storeWithContext(ctx, b.backupStore))
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat backup"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Backup berhasil: %d baris", rows)))
+			return
+
+		case text == "/save status":
+			status, err := b.getSavingsStatus(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data tabungan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, status))
+			return
+
+		case strings.HasPrefix(text, "/save"):
+			args := strings.Fields(strings.TrimPrefix(text, "/save"))
+			if len(args) == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /save <nominal> [catatan]\nContoh: /save 500rb Dana darurat"))
+				return
+			}
+
+			nominal := normalizeNominal(args[0])
+			if nominal <= 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nominal tidak valid"))
+				return
+			}
+
+			note := strings.Join(args[1:], " ")
+			if err := b.appendSaving(ctx, chatId, nominal, note); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan data tabungan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("🐷 Tabungan Rp %s berhasil dicatat", formatRupiah(nominal))))
+			return
+
+		case text == "/reminder":
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("🔔 Pengingat saat ini: %s\n\nPilih pengingat baru:", pref.ReminderType.label()))
+			msg.ReplyMarkup = reminderKeyboard("reminder:")
+			b.sendMessage(msg)
+			return
+
+		case strings.HasPrefix(text, "/reminder pause"):
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+			days, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(text, "/reminder pause")))
+			if err != nil || days < 1 || days > maxReminderPauseDays {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Gunakan format: /reminder pause <hari>, 1 sampai %d hari\nContoh: /reminder pause 7", maxReminderPauseDays)))
+				return
+			}
+
+			until := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+			pref.PausedUntil = &until
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan pengaturan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("⏸ Pengingat dijeda sampai %s. Kirim /reminder resume untuk melanjutkan lebih awal.",
+				until.In(pref.location()).Format("02-01-2006 15:04"))))
+			return
+
+		case text == "/reminder resume":
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+			if !pref.remindersPaused(time.Now()) {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "Pengingat tidak sedang dijeda"))
+				return
+			}
+
+			pref.PausedUntil = nil
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan pengaturan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, "▶️ Pengingat dilanjutkan: "+pref.ReminderType.label()))
+			return
+
+		case text == "/test_reminder":
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			reminder, err := b.reminderText(ctx, chatId, pref.ReminderType)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat contoh pengingat"))
+				return
+			}
+			if reminder == "" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "ℹ️ Kamu belum memilih pengingat. Atur dulu dengan /reminder"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, "🧪 Ini adalah contoh pengingat:\n\n"+reminder))
+			return
+
+		case text == "/limit" || strings.HasPrefix(text, "/limit "):
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			args := strings.Fields(strings.TrimPrefix(text, "/limit"))
+			if len(args) == 0 {
+				if pref.MonthlyLimit <= 0 {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "ℹ️ Batas bulanan belum diatur. Gunakan: /limit monthly <nominal>"))
+					return
+				}
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("🎯 Batas bulanan: Rp %s", formatRupiah(pref.MonthlyLimit))))
+				return
+			}
+			if len(args) != 2 || args[0] != "monthly" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /limit monthly <nominal>\nContoh: /limit monthly 3jt"))
+				return
+			}
+
+			limit := normalizeNominal(args[1])
+			if limit < 0 || (limit == 0 && args[1] != "0") {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nominal tidak valid"))
+				return
+			}
+
+			if err := b.setMonthlyLimit(ctx, chatId, limit); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan batas bulanan"))
+				return
+			}
+			if limit == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "✅ Batas bulanan dihapus"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Batas bulanan diatur ke Rp %s", formatRupiah(limit))))
+			return
+
+		case text == "/digest" || strings.HasPrefix(text, "/digest "):
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			arg := strings.TrimSpace(strings.TrimPrefix(text, "/digest"))
+			switch arg {
+			case "":
+				if pref.DigestTime == "" {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "ℹ️ Ringkasan pagi belum aktif. Gunakan: /digest <jam>\nContoh: /digest 07:00"))
+					return
+				}
+				digest, err := b.getDigest(ctx, pref, time.Now())
+				if err != nil {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat ringkasan pagi"))
+					return
+				}
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("⏰ Ringkasan pagi dikirim setiap hari pukul %s\n\n%s", pref.DigestTime, digest)))
+				return
+
+			case "off":
+				pref.DigestTime = ""
+
+			default:
+				digestTime, err := time.Parse("15:04", arg)
+				if err != nil {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /digest <jam>\nContoh: /digest 07:00"))
+					return
+				}
+				pref.DigestTime = digestTime.Format("15:04")
+			}
+
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan ringkasan pagi"))
+				return
+			}
+			if pref.DigestTime == "" {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "✅ Ringkasan pagi dimatikan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Ringkasan pagi akan dikirim setiap hari pukul %s", pref.DigestTime)))
+			return
+
+		case text == "/week_report" || strings.HasPrefix(text, "/week_report "):
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			switch strings.TrimSpace(strings.TrimPrefix(text, "/week_report")) {
+			case "on":
+				pref.WeeklyReportEnabled = true
+			case "off":
+				pref.WeeklyReportEnabled = false
+			default:
+				status := "tidak aktif"
+				if pref.WeeklyReportEnabled {
+					status = "aktif"
+				}
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("ℹ️ Laporan mingguan %s. Gunakan: /week_report on|off", status)))
+				return
+			}
+
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan laporan mingguan"))
+				return
+			}
+			if !pref.WeeklyReportEnabled {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "✅ Laporan mingguan dimatikan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Laporan pengeluaran minggu lalu akan dikirim setiap Senin pukul %02d:00", weeklyReportHour)))
+			return
+
+		case text == "/lang" || strings.HasPrefix(text, "/lang "):
+			pref, ok := b.getPreference(chatId)
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).NeedStart))
+				return
+			}
+
+			lang := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(text, "/lang")))
+			if _, ok := b.messages[lang]; !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).LanguageUsage))
+				return
+			}
+
+			pref.Language = lang
+			if err := b.saveUserPreference(ctx, pref); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan bahasa"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).LanguageChanged))
+			return
+
+		case text == "/reset":
+			code, err := b.startReset(chatId)
+			if err != nil {
+				log.Printf("%v", err)
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat kode konfirmasi"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("⚠️ Semua data kamu akan dihapus: entri yang kamu catat, tabungan, pin, alias, dan pengaturan. "+
+				"Tindakan ini tidak bisa dibatalkan.\n\nKetik kode ini untuk mengonfirmasi: %s\n\nKode berlaku %d menit.", code, int(resetCodeTTL.Minutes()))))
+			return
+
+		case text == "/whoami":
+			whoami, err := b.getWhoami(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data kamu"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, whoami))
+			return
+
+		case text == "/installment status":
+			status, err := b.getInstallmentStatus(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data cicilan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, status))
+			return
+
+		case text == "/installment" || strings.HasPrefix(text, "/installment "):
+			args := strings.Fields(strings.TrimPrefix(text, "/installment"))
+			if len(args) < 4 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /installment <nominal> <bulan> <kategori> <keterangan>\nContoh: /installment 3,6jt 12 Elektronik HP baru"))
+				return
+			}
+
+			nominal := normalizeNominal(args[0])
+			months, err := strconv.Atoi(args[1])
+			if nominal <= 0 || err != nil || months < 1 || months > 60 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nominal atau jumlah bulan tidak valid (1-60 bulan)"))
+				return
+			}
+
+			category, description := b.expandAlias(ctx, chatId, args[2]), strings.Join(args[3:], " ")
+			if err := b.createInstallmentPlan(ctx, chatId, nominal, months, category, description); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membuat rencana cicilan"))
+				return
+			}
+			// The first installment is due today
+			if err := b.postDueInstallments(ctx, time.Now()); err != nil {
+				log.Printf("failed to record due installments: %v", err)
+			}
+
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("💳 Cicilan dibuat: %d x Rp %s untuk %s.\nCicilan pertama sudah dicatat hari ini, sisanya dicatat otomatis setiap bulan.\nGunakan /installment status untuk melihat sisa cicilan.",
+				months, formatRupiah(nominal/months), description)))
+			return
+
+		case text == "/last":
+			lastEntry, err := b.getLastEntry(ctx)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data terakhir")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, lastEntry)
+			b.sendMessage(msg)
+			return
+
+		case strings.HasPrefix(text, "/last "):
+			n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(text, "/last")))
+			if err != nil || n < 1 || n > maxLastEntries {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Gunakan format: /last <jumlah>, antara 1 dan %d\nContoh: /last 10", maxLastEntries)))
+				return
+			}
+
+			pinned, err := b.pinnedRows(ctx, chatId)
+			if err != nil {
+				log.Printf("failed to get pins of %d: %v", chatId, err)
+			}
+			pages, err := b.getLastEntries(ctx, n, pinned)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil riwayat transaksi"))
+				return
+			}
+			for _, page := range pages {
+				b.sendMessage(tgbotapi.NewMessage(chatId, page))
+			}
+			return
+
+		case text == "/cancel":
+			// Edits in progress are cancelled before the commands are handled
+			b.sendMessage(tgbotapi.NewMessage(chatId, "ℹ️ Tidak ada edit yang sedang berjalan"))
+			return
+
+		case text == "/remove":
+			lastEntry, err := b.getLastEntry(ctx)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data terakhir")
+				b.sendMessage(msg)
+				return
+			}
+
+			change, err := b.removeLastEntry(ctx)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal menghapus data terakhir")
+				b.sendMessage(msg)
+				return
+			}
+			b.pushHistory(chatId, change)
+
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Data berhasil dihapus:\n%s", lastEntry))
+			b.sendMessage(msg)
+			return
+
+		case text == "/undo":
+			change, ok, err := b.undo(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal membatalkan aksi terakhir"))
+				return
+			}
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "ℹ️ Tidak ada aksi yang bisa dibatalkan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("↩️ Aksi %s pada entri #%d berhasil dibatalkan", change.Action, change.Row)))
+			return
+
+		case strings.HasPrefix(text, "/pin "):
+			rowNumberStr, label, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(text, "/pin ")), " ")
+			rowNumber, err := strconv.Atoi(rowNumberStr)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /pin <nomor> [label]\nContoh: /pin 7 Bayar kos"))
+				return
+			}
+
+			if _, err := b.getEntryByNumber(ctx, rowNumber); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Entri tidak ditemukan"))
+				return
+			}
+
+			if err := b.pinEntry(ctx, chatId, rowNumber, strings.TrimSpace(label)); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan pin"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("📌 Entri #%d berhasil di-pin", rowNumber)))
+			return
+
+		case text == "/pins":
+			pins, err := b.formatPins(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil daftar pin"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, pins))
+			return
+
+		case strings.HasPrefix(text, "/unpin "):
+			rowNumber, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(text, "/unpin ")))
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /unpin <nomor>"))
+				return
+			}
+
+			removed, err := b.unpinEntry(ctx, chatId, rowNumber)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menghapus pin"))
+				return
+			}
+			if !removed {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Entri #%d tidak di-pin", rowNumber)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Pin entri #%d dihapus", rowNumber)))
+			return
+
+		case strings.HasPrefix(text, "/link "):
+			rowNumber, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(text, "/link ")))
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /link <nomor>\nContoh: /link 7"))
+				return
+			}
+
+			// The message to link is the one /link replies to
+			linked := update.Message.ReplyToMessage
+			if linked == nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Teruskan pesan ke bot, lalu balas pesan itu dengan /link <nomor>"))
+				return
+			}
+
+			if _, err := b.getEntryByNumber(ctx, rowNumber); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Entri tidak ditemukan"))
+				return
+			}
+
+			if err := b.linkMessage(ctx, chatId, newLink(rowNumber, linked)); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menautkan pesan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("🔗 Pesan ditautkan ke entri #%d. Lihat dengan /view %d", rowNumber, rowNumber)))
+			return
+
+		case strings.HasPrefix(text, "/view "):
+			rowNumber, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(text, "/view ")))
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /view <nomor>"))
+				return
+			}
+
+			entry, err := b.getEntryByNumber(ctx, rowNumber)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Entri tidak ditemukan"))
+				return
+			}
+
+			link, found, err := b.getLink(ctx, chatId, rowNumber)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil pesan tertaut"))
+				return
+			}
+			if !found {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("#%d\n%s\n\n🔗 Belum ada pesan tertaut", rowNumber, entry)))
+				return
+			}
+
+			// Reply to the linked message so it can be opened with a tap
+			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("#%d\n%s\n\n%s", rowNumber, entry, link.format()))
+			msg.ReplyToMessageID = link.MessageID
+			msg.AllowSendingWithoutReply = true
+			b.sendMessage(msg)
+			return
+
+		case text == "/copy_month" || strings.HasPrefix(text, "/copy_month "):
+			now := time.Now()
+			month := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.Local)
+			if arg := strings.TrimSpace(strings.TrimPrefix(text, "/copy_month")); arg != "" {
+				parsed, err := time.ParseInLocation("2006-01", arg, time.Local)
+				if err != nil {
+					b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /copy_month <tahun>-<bulan>\nContoh: /copy_month 2024-06"))
+					return
+				}
+				month = parsed
+			}
+
+			copied, err := b.copyMonth(ctx, chatId, month)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyalin entri"))
+				return
+			}
+			if copied == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("Tidak ada entri kamu pada %s", month.Format("01-2006"))))
+				return
+			}
+			b.sendPendingList(ctx, chatId, 0, fmt.Sprintf("📋 %d entri dari %s disalin ke bulan ini. Konfirmasi atau hapus masing-masing:", copied, month.Format("01-2006")))
+			return
+
+		case text == "/checkin":
+			b.askCheckin(chatId)
+			return
+
+		case text == "/pending":
+			b.sendPendingList(ctx, chatId, 0, "")
+			return
+
+		case text == "/template list" || text == "/template":
+			templates, err := b.getTemplates(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil daftar template"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, formatTemplates(templates)))
+			return
+
+		case strings.HasPrefix(text, "/template add "):
+			name, entry, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(text, "/template add ")), " ")
+			parts := strings.Split(entry, ",")
+			if name == "" || len(parts) != 3 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /template add <nama> <nominal>, <kategori>, <keterangan>\nContoh: /template add kos 700rb, Rumah, Bayar kos"))
+				return
+			}
+			nominal := normalizeNominal(strings.TrimSpace(parts[0]))
+			if nominal <= 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Nominal tidak valid"))
+				return
+			}
+
+			template := Template{
+				Name:        name,
+				Nominal:     nominal,
+				Category:    b.expandAlias(ctx, chatId, strings.TrimSpace(parts[1])),
+				Description: strings.TrimSpace(parts[2]),
+			}
+			if err := b.setTemplate(ctx, chatId, template); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan template"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Template \"%s\" disimpan: Rp %s | 🎯%s | 📚%s\nCatat dengan /template use %s",
+				strings.ToLower(name), formatRupiah(nominal), normalizeCategory(template.Category), template.Description, strings.ToLower(name))))
+			return
+
+		case strings.HasPrefix(text, "/template use "):
+			name := strings.TrimSpace(strings.TrimPrefix(text, "/template use "))
+			template, ok, err := b.getTemplate(ctx, chatId, name)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil template"))
+				return
+			}
+			if !ok {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Template \"%s\" tidak ditemukan. Lihat daftarnya dengan /template list", name)))
+				return
+			}
+
+			b.recordExpense(ctx, chatId, newExpense{
+				nominal:     template.Nominal,
+				category:    template.Category,
+				description: template.Description,
+				date:        time.Now(),
+				messageID:   update.Message.MessageID,
+				messageRef:  messageRef(update.Message),
+				sender:      senderName(update.Message.From),
+			})
+			return
+
+		case strings.HasPrefix(text, "/template delete "):
+			name := strings.TrimSpace(strings.TrimPrefix(text, "/template delete "))
+			deleted, err := b.deleteTemplate(ctx, chatId, name)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menghapus template"))
+				return
+			}
+			if !deleted {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Template \"%s\" tidak ditemukan", name)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Template \"%s\" dihapus", name)))
+			return
+
+		case text == "/alias list":
+			aliases, err := b.formatAliases(ctx, chatId)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil daftar alias"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, aliases))
+			return
+
+		case strings.HasPrefix(text, "/alias delete "):
+			shortcut := strings.TrimSpace(strings.TrimPrefix(text, "/alias delete "))
+			deleted, err := b.deleteAlias(ctx, chatId, shortcut)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menghapus alias"))
+				return
+			}
+			if !deleted {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("❌ Alias \"%s\" tidak ditemukan", shortcut)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Alias \"%s\" dihapus", shortcut)))
+			return
+
+		case text == "/alias" || strings.HasPrefix(text, "/alias "):
+			args := strings.Fields(strings.TrimPrefix(text, "/alias"))
+			if len(args) < 2 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /alias <singkatan> <kategori>\nContoh: /alias mkn Makanan"))
+				return
+			}
+
+			shortcut, category := args[0], normalizeCategory(strings.Join(args[1:], " "))
+			if err := b.setAlias(ctx, chatId, shortcut, category); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan alias"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ \"%s\" sekarang berarti \"%s\"", strings.ToLower(shortcut), category)))
+			return
+
+		case strings.HasPrefix(text, "/tax config "):
+			category, rateStr, ok := strings.Cut(strings.TrimPrefix(text, "/tax config "), "=")
+			category = normalizeCategory(category)
+			rate, validRate := parseTaxRate(rateStr)
+			if !ok || category == "" || !validRate {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gunakan format: /tax config <kategori>=<tarif>\nContoh: /tax config Jasa=11%"))
+				return
+			}
+
+			if err := b.setTaxRate(ctx, chatId, category, rate); err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menyimpan tarif pajak"))
+				return
+			}
+			if rate == 0 {
+				b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Kategori %s tidak lagi dihitung pajaknya", category)))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Tarif pajak %s diatur ke %s", category, formatTaxRate(rate))))
+			return
+
+		case text == "/tax":
+			estimate, err := b.getTaxEstimate(ctx, chatId, time.Now())
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal menghitung estimasi pajak"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, estimate))
+			return
+
+		case text == "/log":
+			id := strconv.FormatInt(chatId, 10)
+			events, err := b.getAuditLog(ctx, id, auditLogEntries)
+			if err != nil {
+				b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengambil log perubahan"))
+				return
+			}
+			b.sendMessage(tgbotapi.NewMessage(chatId, b.formatAuditLog(id, events)))
+			return
+
+		case text == "/history":
+			pinned, err := b.pinnedRows(ctx, chatId)
+			if err != nil {
+				log.Printf("failed to get pins of %d: %v", chatId, err)
+			}
+			history, err := b.getLastFiveEntries(ctx, pinned)
+			if err != nil {
+				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil riwayat transaksi")
+				b.sendMessage(msg)
+				return
+			}
+			msg := tgbotapi.NewMessage(chatId, history)
+			b.sendMessage(msg)
+			return
+
+		default:
+			msg := tgbotapi.NewMessage(chatId, b.msg(chatId).CommandUnknown)
+			b.sendMessage(msg)
+			return
+		}
+	}
+
+	// Handle data input
+	expense, reply, ok := b.parseExpense(ctx, chatId, text)
+	if !ok {
+		b.sendMessage(tgbotapi.NewMessage(chatId, reply))
+		return
+	}
+	expense.messageID = update.Message.MessageID
+	expense.messageRef = messageRef(update.Message)
+	expense.sender = senderName(update.Message.From)
+	expense.receipt = receiptFileID
+
+	// Ask first when the description is usually filed under another category
+	if suggestion := b.suggestCategory(ctx, expense.description); suggestion != "" && !strings.EqualFold(suggestion, normalizeCategory(expense.category)) {
+		b.mu.Lock()
+		b.pendingExpense[chatId] = pendingExpense{expense: expense, suggestion: suggestion}
+		b.mu.Unlock()
+
+		msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("💡 Biasanya kamu memasukkan ini ke \"%s\". Gunakan kategori ini?", suggestion))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✅ Ya", "suggest_yes"),
+				tgbotapi.NewInlineKeyboardButtonData("❌ Tidak", "suggest_no"),
+			),
+		)
+		b.sendMessage(msg)
+		return
+	}
+
+	b.recordExpense(ctx, chatId, expense)
+}
+
+// parseExpense parses text in the "Nominal, Kategori, Keterangan" format,
+// optionally followed by ", DD-MM-YYYY". When it does not parse, reply tells
+// the user why. The message the expense came from is left for the caller to
+// fill in.
+func (b *Bot) parseExpense(ctx context.Context, chatId int64, text string) (newExpense, string, bool) {
+	parts := strings.Split(text, ",")
+	if len(parts) != 3 && len(parts) != 4 {
+		return newExpense{}, b.msg(chatId).FormatError, false
+	}
+
+	date := time.Now()
+	if len(parts) == 4 {
+		var err error
+		dateStr := strings.TrimSpace(parts[3])
+		date, err = parseEntryDate(dateStr)
+		if errors.Is(err, errFutureDate) {
+			return newExpense{}, fmt.Sprintf(b.msg(chatId).FutureDate, dateStr), false
+		}
+		if err != nil {
+			return newExpense{}, fmt.Sprintf(b.msg(chatId).InvalidDate, dateStr), false
+		}
+	}
+
+	return newExpense{
+		nominal:     normalizeNominal(strings.TrimSpace(parts[0])),
+		category:    b.expandAlias(ctx, chatId, strings.TrimSpace(parts[1])),
+		description: strings.TrimSpace(parts[2]),
+		date:        date,
+		dated:       len(parts) == 4,
+	}, "", true
+}
+
+// newExpense is an expense parsed from a message, not yet recorded.
+type newExpense struct {
+	nominal     int
+	category    string
+	description string
+	date        time.Time
+	dated       bool // date was given by the user instead of being today
+	messageID   int
+	messageRef  string
+	sender      string // see senderName
+	receipt     string // file ID of the receipt photo sent with the expense, if any
+}
+
+// recordExpense appends expense to the sheet and confirms it to the user.
+func (b *Bot) recordExpense(ctx context.Context, chatId int64, expense newExpense) {
+	change, err := b.appendData(ctx, expense.nominal, expense.category, expense.description, expense.date, expense.messageRef, expense.sender)
+	if err != nil {
+		b.sendMessage(tgbotapi.NewMessage(chatId, b.msg(chatId).AddFailed))
+		return
+	}
+	b.pushHistory(chatId, change)
+	// Edits of the message and /checkin only work on the main tab
+	if change.Sheet == "" {
+		b.setMessageRow(chatId, expense.messageID, change.Row)
+		b.setLastRecorded(chatId, change.Row)
+	}
+	b.learnCategory(expense.description, normalizeCategory(expense.category))
+
+	dateLabel := b.msg(chatId).DateToday
+	if expense.dated {
+		dateLabel = expense.date.Format("02-01-2006")
+	}
+
+	summary := b.getSummary(ctx, monthStart())
+	if change.Sheet != "" {
+		pref, _ := b.getPreference(chatId)
+		if summary, err = b.getAccountSummary(ctx, chatId, pref.account()); err != nil {
+			log.Printf("failed to get account summary of %d: %v", chatId, err)
+		}
+	}
+	response := fmt.Sprintf(
+		b.msg(chatId).DataAdded,
+		dateLabel, expense.nominal, normalizeCategory(expense.category), expense.description, summary,
+	)
+	if expense.receipt != "" {
+		receiptURL, err := b.attachReceipt(ctx, change.Sheet, change.Row, expense.receipt)
+		if err != nil {
+			log.Printf("failed to attach receipt to row %d: %v", change.Row, err)
+			response += "\n\n⚠️ Foto struk tidak tersimpan"
+		} else {
+			response += "\n\n🧾 Struk: " + receiptURL
+		}
+	}
+	if warning := b.checkWeeklyLimit(ctx, chatId, expense); warning != "" {
+		response += "\n\n" + warning
+	}
+	b.sendMessage(tgbotapi.NewMessage(chatId, response))
+	b.checkMonthlyLimit(ctx, chatId)
+}
+
+func (b *Bot) handleCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		return
+	}
+
+	b.api.Request(tgbotapi.NewCallback(query.ID, ""))
+	if !b.callbacks.Dispatch(ctx, query) {
+		log.Printf("no handler for callback %q", query.Data)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeTelegram answers the Bot API offline and records the text of every
+// message the bot sends.
+type fakeTelegram struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (f *fakeTelegram) Do(req *http.Request) (*http.Response, error) {
+	body, _ := io.ReadAll(req.Body)
+	form, _ := url.ParseQuery(string(body))
+	if text := form.Get("text"); text != "" {
+		f.mu.Lock()
+		f.sent = append(f.sent, text)
+		f.mu.Unlock()
+	}
+	result := `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1}}}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(result)),
+	}, nil
+}
+
+// last returns the text of the last message sent.
+func (f *fakeTelegram) last() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.sent) == 0 {
+		return ""
+	}
+	return f.sent[len(f.sent)-1]
+}
+
+// newTestBot returns a bot on an empty MemorySheetStore whose expense tab has
+// its header, talking to a fakeTelegram.
+func newTestBot(t *testing.T) (*Bot, *fakeTelegram) {
+	t.Helper()
+	telegram := &fakeTelegram{}
+	api := &tgbotapi.BotAPI{Token: "test", Client: telegram}
+	api.SetAPIEndpoint(tgbotapi.APIEndpoint)
+
+	store := &MemorySheetStore{}
+	if err := store.Update("A1", [][]interface{}{expenseHeader}); err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewBotWithAPI(api, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, telegram
+}
+
+// textUpdate is a message with text sent by chatID.
+func textUpdate(chatID int64, text string) tgbotapi.Update {
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		MessageID: 1,
+		Date:      1700000000,
+		Chat:      &tgbotapi.Chat{ID: chatID},
+		From:      &tgbotapi.User{ID: chatID, FirstName: "Test"},
+		Text:      text,
+	}}
+}
+
+func TestHandleUpdateRecordsExpense(t *testing.T) {
+	b, telegram := newTestBot(t)
+
+	b.handleUpdate(context.Background(), textUpdate(42, "10rb, Makanan, Makan siang"))
+
+	rows, err := b.getRows(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d entries, want 1", len(rows))
+	}
+	if row := rows[0]; row.Nominal != 10000 || row.Category != "Makanan" || row.Description != "Makan siang" {
+		t.Errorf("got entry %+v, want 10000 Makanan Makan siang", row)
+	}
+	if reply := telegram.last(); !strings.Contains(reply, "Makanan") {
+		t.Errorf("got reply %q, want the recorded entry", reply)
+	}
+}
+
+func TestHandleUpdateEditSetsEditingRow(t *testing.T) {
+	b, telegram := newTestBot(t)
+	for _, text := range []string{"10rb, Makanan, Sarapan", "20rb, Makanan, Makan siang", "30rb, Transport, Ojek"} {
+		b.handleUpdate(context.Background(), textUpdate(42, text))
+	}
+
+	b.handleUpdate(context.Background(), textUpdate(42, "/edit 3"))
+
+	row, editing := b.editingRow(42)
+	if !editing || row != 3 {
+		t.Fatalf("got editing row %d (editing %v), want 3", row, editing)
+	}
+	if reply := telegram.last(); !strings.Contains(reply, "#3") {
+		t.Errorf("got reply %q, want the edit prompt of entry #3", reply)
+	}
+}
+
+func TestHandleUpdateDropsCancelledUpdate(t *testing.T) {
+	b, _ := newTestBot(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b.handleUpdate(ctx, textUpdate(42, "10rb, Makanan, Makan siang"))
+
+	if rows, _ := b.getRows(context.Background()); len(rows) != 0 {
+		t.Errorf("got %d entries from a cancelled update, want 0", len(rows))
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// broadcastRate is how many messages a broadcast sends per second, the
+// limit Telegram allows for bots.
+const broadcastRate = 30
+
+// broadcast sends text to every user with saved preferences and returns how
+// many sends succeeded and failed.
+func (b *Bot) broadcast(text string) (sent, failed int) {
+	b.mu.RLock()
+	chatIDs := make([]int64, 0, len(b.prefs))
+	for chatID := range b.prefs {
+		chatIDs = append(chatIDs, chatID)
+	}
+	b.mu.RUnlock()
+
+	limiter := time.NewTicker(time.Second / broadcastRate)
+	defer limiter.Stop()
+
+	for _, chatID := range chatIDs {
+		<-limiter.C
+		if _, err := b.sendMessage(tgbotapi.NewMessage(chatID, text)); err != nil {
+			log.Printf("failed to notify %d: %v", chatID, err)
+			failed++
+			continue
+		}
+		sent++
+	}
+
+	log.Printf("broadcast done: %d sent, %d failed", sent, failed)
+	return sent, failed
+}
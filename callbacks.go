@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CallbackHandler handles an inline keyboard callback of the message
+// messageID in chatID, within the context of its update. data is the
+// callback data without the prefix the handler was registered for.
+type CallbackHandler func(ctx context.Context, chatID int64, messageID int, data string)
+
+// CallbackQueryRouter sends each inline keyboard callback to the handler
+// registered for the prefix of its data.
+type CallbackQueryRouter struct {
+	handlers map[string]CallbackHandler
+}
+
+func NewCallbackQueryRouter() *CallbackQueryRouter {
+	return &CallbackQueryRouter{handlers: make(map[string]CallbackHandler)}
+}
+
+// Register handles the callbacks whose data starts with prefix. When
+// several prefixes match, the longest wins.
+func (r *CallbackQueryRouter) Register(prefix string, handler CallbackHandler) {
+	r.handlers[prefix] = handler
+}
+
+// Dispatch runs the handler of query and reports whether there was one.
+func (r *CallbackQueryRouter) Dispatch(ctx context.Context, query *tgbotapi.CallbackQuery) bool {
+	match := ""
+	for prefix := range r.handlers {
+		if strings.HasPrefix(query.Data, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	handler, ok := r.handlers[match]
+	if !ok {
+		return false
+	}
+	handler(ctx, query.Message.Chat.ID, query.Message.MessageID, strings.TrimPrefix(query.Data, match))
+	return true
+}
+
+// newCallbackRouter registers the bot's inline keyboard callbacks.
+func (b *Bot) newCallbackRouter() *CallbackQueryRouter {
+	r := NewCallbackQueryRouter()
+	r.Register("onboard_", b.handleOnboardingCallback)
+	r.Register("reminder:", b.handleReminderCallback)
+	r.Register("monthly_page:", b.handleMonthlyPageCallback)
+	r.Register("edit_page:", b.handleEditPageCallback)
+	r.Register("edit_row:", b.handleEditRowCallback)
+	r.Register("convert_record:", b.handleConvertRecordCallback)
+	r.Register("suggest_", b.handleSuggestCallback)
+	r.Register("merge_", b.handleMergeCallback)
+	r.Register("migrate_", b.handleMigrateCallback)
+	r.Register("clear_", b.handleClearCallback)
+	r.Register("voice_", b.handleVoiceCallback)
+	r.Register("delete_range_", b.handleDeleteRangeCallback)
+	r.Register("pending_", b.handlePendingCallback)
+	r.Register("subscribe_", b.handleSubscribeCallback)
+	return r
+}
+
+func (b *Bot) handleReminderCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	pref, ok := b.getPreference(chatID)
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, b.msg(chatID).NeedStart))
+		return
+	}
+
+	pref.ReminderType = ReminderType(data)
+	if err := b.saveUserPreference(ctx, pref); err != nil {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal menyimpan pengingat"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "✅ Pengingat diubah menjadi: "+pref.ReminderType.label()))
+}
+
+func (b *Bot) handleMonthlyPageCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	page, _ := strconv.Atoi(data)
+	monthlySummary, pages, err := b.getMonthlySummaryPage(ctx, page)
+	if err != nil {
+		b.sendMessage(tgbotapi.NewMessage(chatID, "❌ Gagal mengambil data pengeluaran bulanan"))
+		return
+	}
+	page = max(0, min(page, pages-1))
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, monthlySummary)
+	edit.ReplyMarkup = monthlyPageKeyboard(page, pages)
+	b.sendMessage(edit)
+}
+
+func (b *Bot) handleEditPageCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	page, _ := strconv.Atoi(data)
+	keyboard, err := b.editListKeyboard(ctx, page)
+	if err != nil || keyboard == nil {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal mengambil data"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, *keyboard))
+}
+
+func (b *Bot) handleEditRowCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	rowNumber, err := strconv.Atoi(data)
+	if err != nil {
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("✏️ Entri #%d dipilih", rowNumber)))
+	b.startEditing(ctx, chatID, rowNumber)
+}
+
+func (b *Bot) handleConvertRecordCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	nominal, err := strconv.Atoi(data)
+	if err != nil || nominal <= 0 {
+		return
+	}
+	b.setPendingConversion(chatID, nominal)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📝 Pengeluaran Rp %s\nKirim kategori dan keterangannya dalam format: Kategori, Keterangan", formatRupiah(nominal)))
+	msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, InputFieldPlaceholder: "Kategori, Keterangan"}
+	b.sendMessage(msg)
+}
+
+// handleSuggestCallback answers a category suggestion with "yes" or "no".
+func (b *Bot) handleSuggestCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	b.mu.Lock()
+	pending, ok := b.pendingExpense[chatID]
+	delete(b.pendingExpense, chatID)
+	b.mu.Unlock()
+
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Tidak ada pengeluaran yang menunggu konfirmasi"))
+		return
+	}
+	if data == "yes" {
+		pending.expense.category = pending.suggestion
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🎯 Kategori: "+normalizeCategory(pending.expense.category)))
+	b.recordExpense(ctx, chatID, pending.expense)
+}
+
+// handleMergeCallback answers a /merge confirmation with "confirm" or
+// "cancel".
+func (b *Bot) handleMergeCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	b.mu.Lock()
+	merge, ok := b.pendingMerge[chatID]
+	delete(b.pendingMerge, chatID)
+	b.mu.Unlock()
+
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Tidak ada penggabungan yang menunggu konfirmasi"))
+		return
+	}
+	if data != "confirm" {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🚫 Penggabungan kategori dibatalkan"))
+		return
+	}
+
+	count, err := b.mergeCategories(ctx, merge.from, merge.to)
+	if err != nil {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal menggabungkan kategori"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID,
+		fmt.Sprintf("✅ %d entri berhasil digabung dari \"%s\" ke \"%s\".", count, merge.from, merge.to)))
+}
+
+// handleMigrateCallback answers whether migrated entries are deleted from
+// the shared spreadsheet with "delete" or "keep".
+func (b *Bot) handleMigrateCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	b.mu.Lock()
+	refs, ok := b.pendingMigrations[chatID]
+	delete(b.pendingMigrations, chatID)
+	b.mu.Unlock()
+
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Tidak ada migrasi yang menunggu konfirmasi"))
+		return
+	}
+	if data != "delete" {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "✅ Migrasi selesai. Data di spreadsheet bersama tetap disimpan"))
+		return
+	}
+
+	count, err := b.deleteMigrated(ctx, chatID, refs)
+	if err != nil {
+		log.Printf("failed to delete migrated entries: %v", err)
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal menghapus data dari spreadsheet bersama"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID,
+		fmt.Sprintf("✅ Migrasi selesai. %d baris dihapus dari spreadsheet bersama.", count)))
+}
+
+// handleClearCallback answers a /clear category confirmation with "confirm"
+// or "cancel".
+func (b *Bot) handleClearCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	b.mu.Lock()
+	category, ok := b.pendingClear[chatID]
+	delete(b.pendingClear, chatID)
+	b.mu.Unlock()
+
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Tidak ada penghapusan yang menunggu konfirmasi"))
+		return
+	}
+	if data != "confirm" {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🚫 Penghapusan kategori dibatalkan"))
+		return
+	}
+
+	count, err := b.clearCategory(ctx, chatID, category)
+	if err != nil {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal menghapus entri kategori"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID,
+		fmt.Sprintf("✅ %d entri dengan kategori \"%s\" berhasil dihapus.", count, category)))
+}
+
+// handleDeleteRangeCallback answers a /delete range confirmation with
+// "confirm" or "cancel".
+func (b *Bot) handleDeleteRangeCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	b.mu.Lock()
+	rows, ok := b.pendingDeleteRange[chatID]
+	delete(b.pendingDeleteRange, chatID)
+	b.mu.Unlock()
+
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Tidak ada penghapusan yang menunggu konfirmasi"))
+		return
+	}
+	if data != "confirm" {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🚫 Penghapusan entri dibatalkan"))
+		return
+	}
+
+	if err := b.deleteRowRange(ctx, chatID, rows.start, rows.end); errors.Is(err, errRowsNotOwned) {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Rentang ini berisi entri milik pengguna lain, tidak ada yang dihapus"))
+		return
+	} else if err != nil {
+		log.Printf("failed to delete rows: %v", err)
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal menghapus entri"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID,
+		fmt.Sprintf("✅ Entri nomor %d sampai %d berhasil dihapus.", rows.start, rows.end)))
+}
+
+// handlePendingCallback records or deletes a /copy_month item, with data
+// "confirm:<row>" or "delete:<row>", and refreshes the list.
+func (b *Bot) handlePendingCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	action, rowStr, _ := strings.Cut(data, ":")
+	sheetRow, err := strconv.Atoi(rowStr)
+	if err != nil {
+		return
+	}
+
+	item, ok, err := b.findPendingItem(ctx, chatID, sheetRow)
+	if err != nil {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal mengambil entri yang menunggu konfirmasi"))
+		return
+	}
+	if !ok {
+		b.sendPendingList(ctx, chatID, messageID, "❌ Entri sudah dikonfirmasi atau dihapus")
+		return
+	}
+
+	notice := fmt.Sprintf("🗑 Entri %s Rp %s dihapus", item.Category, formatRupiah(item.Nominal))
+	if action == "confirm" {
+		change, err := b.appendData(ctx, item.Nominal, item.Category, item.Description, item.Date, fmt.Sprintf("%d:%s", chatID, copiedRefSuffix), "")
+		if err != nil {
+			b.sendPendingList(ctx, chatID, messageID, "❌ Gagal mencatat entri")
+			return
+		}
+		b.pushHistory(chatID, change)
+		notice = fmt.Sprintf("✅ Entri %s Rp %s dicatat sebagai #%d", item.Category, formatRupiah(item.Nominal), change.Row)
+	}
+
+	if err := b.removePendingItem(ctx, item); err != nil {
+		log.Printf("failed to remove pending item of %d: %v", chatID, err)
+	}
+	b.sendPendingList(ctx, chatID, messageID, notice)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wcharczuk/go-chart/v2"
+)
+
+// chartCacheTTL is how long a rendered chart is reused for the same user.
+const chartCacheTTL = 5 * time.Minute
+
+// chartCache holds rendered charts by chat and chart kind.
+type chartCache struct {
+	mu     sync.Mutex
+	charts map[string]cachedChart
+}
+
+type cachedChart struct {
+	png      []byte
+	caption  string
+	rendered time.Time
+}
+
+func (c *chartCache) get(chatID int64, kind string) (cachedChart, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.charts[fmt.Sprintf("%d:%s", chatID, kind)]
+	return cached, ok && time.Since(cached.rendered) < chartCacheTTL
+}
+
+func (c *chartCache) put(chatID int64, kind string, cached cachedChart) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.charts == nil {
+		c.charts = make(map[string]cachedChart)
+	}
+	cached.rendered = time.Now()
+	c.charts[fmt.Sprintf("%d:%s", chatID, kind)] = cached
+}
+
+// getCategoryTotals sums the spending of each category in the month of date.
+func (b *Bot) getCategoryTotals(ctx context.Context, date time.Time) (map[string]int, error) {
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.Local)
+	rows, err := b.getEntriesBetween(ctx, start, start.AddDate(0, 1, -1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category totals: %w", err)
+	}
+
+	totals := make(map[string]int)
+	for _, row := range rows {
+		totals[row.Category] += row.Nominal
+	}
+	return totals, nil
+}
+
+// renderPieChart draws totals as a PNG pie chart, biggest slice first.
+func renderPieChart(totals map[string]int) ([]byte, error) {
+	categories := make([]string, 0, len(totals))
+	sum := 0
+	for category, total := range totals {
+		if total > 0 {
+			categories = append(categories, category)
+			sum += total
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return totals[categories[i]] > totals[categories[j]]
+	})
+
+	values := make([]chart.Value, 0, len(categories))
+	for _, category := range categories {
+		values = append(values, chart.Value{
+			Value: float64(totals[category]),
+			Label: fmt.Sprintf("%s %.0f%%", category, float64(totals[category])/float64(sum)*100),
+		})
+	}
+
+	pie := chart.PieChart{Width: 640, Height: 640, Values: values}
+	var buf bytes.Buffer
+	if err := pie.Render(chart.PNG, &buf); err != nil {
+		return nil, fmt.Errorf("failed to render pie chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// getPieChart returns this month's category pie chart for chatID, rendering
+// it only when there is no recent one. ok is false when there is nothing to
+// draw.
+func (b *Bot) getPieChart(ctx context.Context, chatID int64) (cached cachedChart, ok bool, err error) {
+	if cached, ok := b.charts.get(chatID, "pie"); ok {
+		return cached, true, nil
+	}
+
+	now := time.Now()
+	totals, err := b.getCategoryTotals(ctx, now)
+	if err != nil {
+		return cached, false, err
+	}
+
+	sum := 0
+	for _, total := range totals {
+		sum += total
+	}
+	if sum <= 0 {
+		return cached, false, nil
+	}
+
+	png, err := renderPieChart(totals)
+	if err != nil {
+		return cached, false, err
+	}
+
+	cached = cachedChart{
+		png:     png,
+		caption: fmt.Sprintf("🥧 Pengeluaran per kategori %s\n💰 Total: Rp %s", now.Format("01-2006"), formatRupiah(sum)),
+	}
+	b.charts.put(chatID, "pie", cached)
+	return cached, true, nil
+}
+
+// barChartMonths is how many months /chart bar monthly shows by default, and
+// at most so that no month abbreviation appears twice.
+const barChartMonths = 12
+
+// barChartWidth is the length of the bar of the month with the most spending
+// in renderMonthlyBarChart.
+const barChartWidth = 15
+
+// MonthTotal is the spending of the month starting at Month.
+type MonthTotal struct {
+	Month time.Time
+	Total int
+}
+
+// getMonthlyTotals sums the spending of each of the last months months, this
+// one included, oldest first.
+func (b *Bot) getMonthlyTotals(ctx context.Context, months int) ([]MonthTotal, error) {
+	now := time.Now()
+	first := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.Local)
+	rows, err := b.getEntriesBetween(ctx, first, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly totals: %w", err)
+	}
+
+	totals := make([]MonthTotal, months)
+	for i := range totals {
+		totals[i].Month = first.AddDate(0, i, 0)
+	}
+	for _, row := range rows {
+		i := (row.Date.Year()-first.Year())*12 + int(row.Date.Month()-first.Month())
+		totals[i].Total += row.Nominal
+	}
+	return totals, nil
+}
+
+// renderMonthlyBarChart draws totals as horizontal bars scaled to the month
+// with the most spending, one line per month.
+func renderMonthlyBarChart(totals []MonthTotal) string {
+	highest := 0
+	for _, month := range totals {
+		highest = max(highest, month.Total)
+	}
+
+	var chart strings.Builder
+	for _, month := range totals {
+		label := monthNames[month.Month.Month()-1][:3]
+		if month.Total <= 0 {
+			chart.WriteString(fmt.Sprintf("%s | - (tidak ada)\n", label))
+			continue
+		}
+		width := max(1, month.Total*barChartWidth/highest)
+		chart.WriteString(fmt.Sprintf("%s | %s Rp %s\n", label, strings.Repeat("█", width), formatRupiah(month.Total)))
+	}
+	return chart.String()
+}
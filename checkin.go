@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// locationWindow is how soon after an expense a shared location is taken as
+// where it was spent, without /checkin.
+const locationWindow = 5 * time.Second
+
+// checkinTimeout is how long /checkin waits for the location.
+const checkinTimeout = 5 * time.Minute
+
+// recordedEntry is the row of an entry and when it was recorded.
+type recordedEntry struct {
+	row int
+	at  time.Time
+}
+
+func (b *Bot) setLastRecorded(chatID int64, row int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastRecorded[chatID] = recordedEntry{row: row, at: time.Now()}
+}
+
+// takeCheckin returns and forgets whether chatID is waiting on a location
+// after /checkin.
+func (b *Bot) takeCheckin(chatID int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	expires, ok := b.pendingCheckin[chatID]
+	delete(b.pendingCheckin, chatID)
+	return ok && time.Now().Before(expires)
+}
+
+// lastEntryOf returns the row of the last entry recorded in chatID, or 0 when
+// there is none.
+func (b *Bot) lastEntryOf(ctx context.Context, chatID int64) (int, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return 0, err
+	}
+	id := strconv.FormatInt(chatID, 10)
+	for i := len(rows) - 1; i >= 0; i-- {
+		if refChatID(rows[i].Ref) == id {
+			return rows[i].RowNum, nil
+		}
+	}
+	return 0, nil
+}
+
+// saveLocation stores where the entry in row was spent as "lat,lon".
+func (b *Bot) saveLocation(ctx context.Context, row int, location *tgbotapi.Location) error {
+	value := fmt.Sprintf("%.6f,%.6f", location.Latitude, location.Longitude)
+	if err := storeWithContext(ctx, b.store).Update(b.expenseRange(b.sheetConfig.cell(columnLetter(locationColumn), row)), [][]interface{}{{value}}); err != nil {
+		return fmt.Errorf("failed to save location of row %d: %w", row, err)
+	}
+	return nil
+}
+
+// handleLocation tags an entry with a shared location: the last entry of the
+// chat after /checkin, or the entry recorded just before otherwise.
+func (b *Bot) handleLocation(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	checkin := b.takeCheckin(chatID)
+
+	b.mu.RLock()
+	recent, ok := b.lastRecorded[chatID]
+	b.mu.RUnlock()
+
+	row := 0
+	switch {
+	case ok && time.Since(recent.at) <= locationWindow:
+		row = recent.row
+	case checkin:
+		var err error
+		row, err = b.lastEntryOf(ctx, chatID)
+		if err != nil {
+			log.Printf("failed to find last entry of %d: %v", chatID, err)
+			b.sendMessage(tgbotapi.NewMessage(chatID, "❌ Gagal mencari entri terakhir"))
+			return
+		}
+		if row == 0 {
+			b.sendMessage(tgbotapi.NewMessage(chatID, "Belum ada entri untuk ditandai lokasinya"))
+			return
+		}
+	default:
+		// A location shared on its own is not about an entry
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📍 Lokasi disimpan untuk entri #%d", row))
+	if err := b.saveLocation(ctx, row, message.Location); err != nil {
+		log.Printf("%v", err)
+		msg.Text = "❌ Gagal menyimpan lokasi"
+	}
+	if checkin {
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(true)
+	}
+	b.sendMessage(msg)
+}
+
+// askCheckin asks chatID to share their location for their last entry.
+func (b *Bot) askCheckin(chatID int64) {
+	b.mu.Lock()
+	b.pendingCheckin[chatID] = time.Now().Add(checkinTimeout)
+	b.mu.Unlock()
+
+	msg := tgbotapi.NewMessage(chatID, "📍 Kirim lokasimu untuk ditandai pada entri terakhir")
+	keyboard := tgbotapi.NewReplyKeyboard(tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonLocation("📍 Kirim lokasi")))
+	keyboard.OneTimeKeyboard = true
+	msg.ReplyMarkup = keyboard
+	b.sendMessage(msg)
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// clearPreviewSize is how many entries /clear category shows before asking
+// for confirmation.
+const clearPreviewSize = 5
+
+func (c SheetConfig) hasCategory(raw []interface{}, category string) bool {
+	row, err := c.parseRow(raw)
+	return err == nil && strings.EqualFold(row.Category, category)
+}
+
+// previewCategory counts chatID's entries of category and lists the last
+// few.
+func (b *Bot) previewCategory(ctx context.Context, chatID int64, category string) (int, string, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+
+	owner := strconv.FormatInt(chatID, 10)
+	var entries []string
+	for _, row := range rows {
+		if refChatID(row.Ref) == owner && strings.EqualFold(row.Category, category) {
+			entries = append(entries, fmt.Sprintf("📅%s - 💰%d | 📚%s", row.Date.Format("02-01-2006"), row.Nominal, row.Description))
+		}
+	}
+
+	preview := entries[max(0, len(entries)-clearPreviewSize):]
+	return len(entries), strings.Join(preview, "\n"), nil
+}
+
+// clearCategory deletes chatID's entries of category, ignoring case, and
+// returns how many were deleted. The rows are removed from the bottom up,
+// so the rows still to be deleted keep their numbers.
+func (b *Bot) clearCategory(ctx context.Context, chatID int64, category string) (int, error) {
+	raw, err := storeWithContext(ctx, b.store).Get(b.expenseRange("A:" + b.sheetConfig.lastColumn()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	owned := entryOwnedBy(chatID)
+	var rows []int
+	for i := len(raw) - 1; i > 0; i-- { // Skip header
+		if owned(raw[i]) && b.sheetConfig.hasCategory(raw[i], category) {
+			rows = append(rows, i+1)
+		}
+	}
+	if err := b.deleteEntryRows(ctx, rows); err != nil {
+		return 0, fmt.Errorf("failed to delete category %q: %w", category, err)
+	}
+	return len(rows), nil
+}
+
+// rowSpan is a range of sheet rows waiting on /delete range confirmation.
+type rowSpan struct {
+	start, end int
+}
+
+var errInvalidRowRange = errors.New("invalid row range")
+
+// previewRowRange counts the entries in rows start to end, both included,
+// and lists the last few. The range must start after the header, end at a
+// row that exists and hold only entries of chatID.
+func (b *Bot) previewRowRange(ctx context.Context, chatID int64, start, end int) (int, string, error) {
+	if start < 2 || start > end {
+		return 0, "", errInvalidRowRange
+	}
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	if len(rows) == 0 || end > rows[len(rows)-1].RowNum {
+		return 0, "", errInvalidRowRange
+	}
+
+	owner := strconv.FormatInt(chatID, 10)
+	var entries []string
+	for _, row := range rows {
+		if row.RowNum >= start && row.RowNum <= end {
+			if refChatID(row.Ref) != owner {
+				return 0, "", errRowsNotOwned
+			}
+			entries = append(entries, fmt.Sprintf("#%d 📅%s - 💰%d | 🎯%s | 📚%s", row.RowNum, row.Date.Format("02-01-2006"), row.Nominal, row.Category, row.Description))
+		}
+	}
+
+	preview := entries[max(0, len(entries)-clearPreviewSize):]
+	return len(entries), strings.Join(preview, "\n"), nil
+}
+
+// errRowsNotOwned is returned by previewRowRange and deleteRowRange when
+// the range holds entries of another chat.
+var errRowsNotOwned = errors.New("rows belong to another chat")
+
+// deleteRowRange deletes the entries in rows start to end, both included,
+// with a single DeleteDimensionRequest. Every entry in the range must be
+// chatID's; empty rows left by earlier deletions go with them.
+func (b *Bot) deleteRowRange(ctx context.Context, chatID int64, start, end int) error {
+	if start < 2 || start > end {
+		return errInvalidRowRange
+	}
+	raw, err := storeWithContext(ctx, b.store).Get(b.expenseRange(fmt.Sprintf("A%d:%s%d", start, b.sheetConfig.lastColumn(), end)))
+	if err != nil {
+		return fmt.Errorf("failed to read rows %d-%d: %w", start, end, err)
+	}
+	owned := entryOwnedBy(chatID)
+	for _, row := range raw {
+		if len(trimEmptyCells(row)) > 0 && !owned(row) {
+			return errRowsNotOwned
+		}
+	}
+
+	rows := make([]int, 0, end-start+1)
+	for row := start; row <= end; row++ {
+		rows = append(rows, row)
+	}
+	if err := b.deleteEntryRows(ctx, rows); err != nil {
+		return fmt.Errorf("failed to delete rows %d-%d: %w", start, end, err)
+	}
+	return nil
+}
+
+// deleteEntryRows removes rows from the expense tab, moving the entries
+// below them up, and renumbers everything that refers to an entry by its
+// row: the No column, pins, links, /undo history, edits in progress and the
+// rows recorded from messages.
+func (b *Bot) deleteEntryRows(ctx context.Context, rows []int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tab, err := parseA1Range(b.expenseRange("A1"))
+	if err != nil {
+		return err
+	}
+	if err := storeWithContext(ctx, b.store).DeleteRows(tab.sheet, rows); err != nil {
+		return err
+	}
+
+	deleted := make(map[int]bool, len(rows))
+	first := rows[0]
+	for _, row := range rows {
+		deleted[row] = true
+		first = min(first, row)
+	}
+	// shift returns the row an entry moved to, or 0 when it was deleted.
+	shift := func(row int) int {
+		if deleted[row] {
+			return 0
+		}
+		moved := row
+		for d := range deleted {
+			if d < row {
+				moved--
+			}
+		}
+		return moved
+	}
+
+	if err := b.renumberEntries(ctx, first); err != nil {
+		return err
+	}
+	for _, tab := range []struct {
+		title, lastCol string
+		header         []interface{}
+	}{
+		{pinnedSheet, "D", pinnedHeader},
+		{linksSheet, "F", linksHeader},
+	} {
+		if err := b.shiftRowNumbers(ctx, tab.title, tab.lastCol, tab.header, shift); err != nil {
+			return err
+		}
+	}
+
+	b.mu.Lock()
+	// Rows recorded from messages are looked up again by their message ref
+	clear(b.messageRowMap)
+	for chatID, session := range b.editingState {
+		if session.row = shift(session.row); session.row == 0 {
+			delete(b.editingState, chatID)
+		} else {
+			b.editingState[chatID] = session
+		}
+	}
+	for chatID, recent := range b.lastRecorded {
+		if recent.row = shift(recent.row); recent.row == 0 {
+			delete(b.lastRecorded, chatID)
+		} else {
+			b.lastRecorded[chatID] = recent
+		}
+	}
+	b.mu.Unlock()
+
+	b.historyMu.Lock()
+	for chatID, history := range b.operationHistory {
+		kept := history[:0]
+		for _, entry := range history {
+			if entry.Sheet == "" {
+				if entry.Row = shift(entry.Row); entry.Row == 0 {
+					continue
+				}
+			}
+			kept = append(kept, entry)
+		}
+		b.operationHistory[chatID] = kept
+	}
+	b.historyMu.Unlock()
+	return nil
+}
+
+// renumberEntries rewrites the No column from row first down, after rows
+// above were deleted.
+func (b *Bot) renumberEntries(ctx context.Context, first int) error {
+	store := storeWithContext(ctx, b.store)
+	col := b.sheetConfig.RowNumCol
+	raw, err := store.Get(b.expenseRange(col + ":" + col))
+	if err != nil {
+		return fmt.Errorf("failed to read entry numbers: %w", err)
+	}
+
+	var updates []RangeValues
+	for i := first - 1; i < len(raw); i++ {
+		if len(raw[i]) == 0 || fmt.Sprintf("%v", raw[i][0]) == strconv.Itoa(i+1) {
+			continue
+		}
+		updates = append(updates, RangeValues{Range: b.expenseRange(b.sheetConfig.cell(col, i+1)), Values: [][]interface{}{{i + 1}}})
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	if err := store.BatchUpdate(updates); err != nil {
+		return fmt.Errorf("failed to renumber entries: %w", err)
+	}
+	return nil
+}
+
+// shiftRowNumbers moves the entry rows in column B of the tab title, such
+// as the pinned rows, with shift, and removes the rows of deleted entries.
+func (b *Bot) shiftRowNumbers(ctx context.Context, title, lastCol string, header []interface{}, shift func(int) int) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, title, header); err != nil {
+		return err
+	}
+	rows, err := store.Get(sheetRange(title, "A:"+lastCol))
+	if err != nil {
+		return fmt.Errorf("failed to read sheet %q: %w", title, err)
+	}
+
+	var updates []RangeValues
+	for i, row := range rows {
+		if i == 0 || len(row) < 2 { // Skip header
+			continue
+		}
+		old, err := strconv.Atoi(fmt.Sprintf("%v", row[1]))
+		if err != nil {
+			continue
+		}
+		moved := shift(old)
+		switch {
+		case moved == old:
+			continue
+		case moved == 0:
+			blank := make([]interface{}, len(row))
+			for j := range blank {
+				blank[j] = ""
+			}
+			updates = append(updates, RangeValues{Range: sheetRange(title, fmt.Sprintf("A%d", i+1)), Values: [][]interface{}{blank}})
+		default:
+			updates = append(updates, RangeValues{Range: sheetRange(title, fmt.Sprintf("B%d", i+1)), Values: [][]interface{}{{moved}}})
+		}
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	if err := store.BatchUpdate(updates); err != nil {
+		return fmt.Errorf("failed to update rows of sheet %q: %w", title, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDeleteRowRange(t *testing.T) {
+	b, _ := newTestBot(t)
+	for _, text := range []string{"10rb, Makanan, Sarapan", "20rb, Makanan, Makan siang", "30rb, Transport, Ojek", "40rb, Makanan, Makan malam"} {
+		b.handleUpdate(context.Background(), textUpdate(42, text))
+	}
+	b.handleUpdate(context.Background(), textUpdate(7, "50rb, Belanja, Sabun"))
+
+	if err := b.deleteRowRange(context.Background(), 42, 3, 6); !errors.Is(err, errRowsNotOwned) {
+		t.Fatalf("deleting another chat's entry: got error %v, want errRowsNotOwned", err)
+	}
+	if err := b.deleteRowRange(context.Background(), 42, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := b.getRows(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Sarapan", "Makan malam", "Sabun"}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		if row.Description != want[i] || row.RowNum != i+2 {
+			t.Errorf("entry %d: got %q at row %d, want %q at row %d", i, row.Description, row.RowNum, want[i], i+2)
+		}
+	}
+
+	raw, err := b.store.Get(b.expenseRange("A:A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(raw); i++ {
+		if got := raw[i][0]; got != i+1 {
+			t.Errorf("row %d is numbered %v, want %d", i+1, got, i+1)
+		}
+	}
+}
+
+func TestClearCategory(t *testing.T) {
+	b, _ := newTestBot(t)
+	b.handleUpdate(context.Background(), textUpdate(42, "10rb, Makanan, Sarapan"))
+	b.handleUpdate(context.Background(), textUpdate(7, "20rb, Makanan, Roti"))
+	b.handleUpdate(context.Background(), textUpdate(42, "30rb, Transport, Ojek"))
+	b.handleUpdate(context.Background(), textUpdate(42, "40rb, makanan, Makan malam"))
+
+	count, err := b.clearCategory(context.Background(), 42, "Makanan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("got %d entries deleted, want 2", count)
+	}
+
+	rows, err := b.getRows(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Roti", "Ojek"}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(rows), len(want))
+	}
+	for i, row := range rows {
+		if row.Description != want[i] || row.RowNum != i+2 {
+			t.Errorf("entry %d: got %q at row %d, want %q at row %d", i, row.Description, row.RowNum, want[i], i+2)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// botCommands lists the commands shown in the Telegram command menu, with
+// their Indonesian and English descriptions.
+var botCommands = []struct {
+	command string
+	id, en  string
+}{
+	{"start", "Mulai bot", "Start the bot"},
+	{"help", "Tampilkan bantuan", "Show help"},
+	{"summary", "Tampilkan total pengeluaran bulan ini", "Show this month's spending total"},
+	{"account", "Pindah akun atau lihat daftar akun", "Switch accounts or list them"},
+	{"subscribe", "Ajak chat lain mencatat pengeluaran bersama", "Invite another chat to a joint expense sheet"},
+	{"unsubscribe", "Berhenti mencatat pengeluaran bersama", "Stop sharing the joint expense sheet"},
+	{"total", "Tampilkan total seluruh pengeluaran", "Show all-time spending total"},
+	{"today", "Tampilkan pengeluaran hari ini", "Show today's spending"},
+	{"weekly", "Tampilkan pengeluaran minggu ini", "Show this week's spending"},
+	{"week", "Tampilkan pengeluaran minggu tertentu", "Show spending of a given week"},
+	{"stats", "Bandingkan minggu ini dengan minggu lalu", "Compare this week with last week"},
+	{"this_week_vs_budget", "Minggu ini vs budget mingguan", "This week vs the weekly budget"},
+	{"weekend", "Tampilkan pengeluaran akhir pekan ini", "Show this weekend's spending"},
+	{"monthly", "Tampilkan pengeluaran bulan ini", "Show this month's spending"},
+	{"report", "Laporan lengkap satu bulan", "Full report of a month"},
+	{"monthly_report", "Kirim laporan bulanan lewat email", "Email the monthly report"},
+	{"monthly_summary_to_sheet", "Tulis ringkasan bulanan ke tab Summary", "Write a monthly summary to the Summary tab"},
+	{"trend", "Tampilkan tren pengeluaran 30 hari", "Show the 30-day spending trend"},
+	{"compare", "Bandingkan dua kategori per bulan", "Compare two categories month by month"},
+	{"year_vs_year", "Bandingkan pengeluaran dua tahun", "Compare the spending of two years"},
+	{"random", "Tips keuangan sesuai pengeluaranmu", "A financial tip based on your spending"},
+	{"forecast", "Proyeksi pengeluaran akhir bulan", "Projected end-of-month spending"},
+	{"cost_per_day", "Rata-rata harian suatu kategori", "Average daily cost of a category"},
+	{"leaderboard", "Siapa yang paling banyak belanja di grup bulan ini", "Who spent the most in the group this month"},
+	{"chart", "Tampilkan grafik pengeluaran per kategori atau per bulan", "Show a spending chart by category or by month"},
+	{"share", "Gambar ringkasan bulan ini untuk dibagikan", "Shareable image of this month's summary"},
+	{"last", "Tampilkan data terakhir", "Show the last entry"},
+	{"history", "Tampilkan 5 transaksi terakhir", "Show the last 5 transactions"},
+	{"log", "10 perubahan terakhir pada datamu", "The last 10 changes to your data"},
+	{"pin", "Tandai entri penting", "Pin an important entry"},
+	{"unpin", "Lepas pin sebuah entri", "Unpin an entry"},
+	{"pins", "Tampilkan entri yang di-pin", "Show pinned entries"},
+	{"link", "Tautkan pesan ke sebuah entri", "Link a message to an entry"},
+	{"view", "Tampilkan entri beserta pesan tertautnya", "Show an entry with its linked message"},
+	{"edit", "Edit entri terakhir atau berdasarkan nomor", "Edit a recent entry or one by its number"},
+	{"cancel", "Batalkan edit yang sedang berjalan", "Cancel the edit in progress"},
+	{"remove", "Hapus entri terakhir", "Remove the last entry"},
+	{"undo", "Batalkan aksi terakhir", "Undo the last action"},
+	{"merge", "Gabungkan dua kategori", "Merge two categories"},
+	{"clear", "Hapus semua entri sebuah kategori", "Delete all entries of a category"},
+	{"checkin", "Tandai lokasi entri terakhir", "Tag your last entry with a location"},
+	{"copy_month", "Salin entri satu bulan ke bulan ini", "Copy a month's entries to this month"},
+	{"pending", "Entri salinan yang menunggu konfirmasi", "Copied entries waiting for confirmation"},
+	{"template", "Template pengeluaran rutin", "Templates for recurring expenses"},
+	{"delete", "Hapus entri dalam rentang nomor", "Delete the entries in a range of numbers"},
+	{"alias", "Buat singkatan kategori", "Define category shortcuts"},
+	{"convert", "Konversi mata uang", "Convert currencies"},
+	{"tax", "Estimasi pajak bulan ini", "Estimate this month's tax"},
+	{"installment", "Bagi pembelian menjadi cicilan", "Split a purchase into installments"},
+	{"save", "Catat tabungan", "Record savings"},
+	{"reminder", "Atur pengingat", "Set up reminders"},
+	{"test_reminder", "Kirim contoh pengingat sekarang", "Send a sample reminder now"},
+	{"digest", "Atur ringkasan pagi", "Set up a morning digest"},
+	{"week_report", "Laporan minggu lalu setiap Senin pagi", "Last week's report every Monday morning"},
+	{"goal", "Atur target bulanan per kategori", "Set monthly category goals"},
+	{"limit", "Atur batas pengeluaran bulanan", "Set a monthly spending limit"},
+	{"whoami", "Tampilkan pengaturan dan statistik kamu", "Show your settings and statistics"},
+	{"lang", "Ganti bahasa", "Change language"},
+	{"backup", "Backup data ke spreadsheet cadangan", "Back up data to the backup spreadsheet"},
+	{"migrate", "Pindahkan data kamu ke spreadsheet sendiri", "Move your data to your own spreadsheet"},
+}
+
+// registerCommands registers botCommands so Telegram shows them in the
+// slash-command menu. Descriptions are in English when lang is "en".
+func registerCommands(bot *tgbotapi.BotAPI, lang string) error {
+	english := strings.HasPrefix(lang, "en")
+
+	commands := make([]tgbotapi.BotCommand, 0, len(botCommands))
+	for _, c := range botCommands {
+		description := c.id
+		if english {
+			description = c.en
+		}
+		commands = append(commands, tgbotapi.BotCommand{Command: c.command, Description: description})
+	}
+
+	_, err := bot.Request(tgbotapi.NewSetMyCommands(commands...))
+	return err
+}
+
+// startText is the /start reply for users who finished onboarding: intro
+// followed by botCommands, described in the language of lang.
+func startText(intro, lang string) string {
+	var text strings.Builder
+	text.WriteString(intro)
+	for _, c := range botCommands {
+		if c.command == "start" {
+			continue
+		}
+		description := c.id
+		if strings.HasPrefix(lang, "en") {
+			description = c.en
+		}
+		text.WriteString(fmt.Sprintf("\n/%s - %s", c.command, description))
+	}
+	return text.String()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// compareMonths is how many months /compare categories shows by default.
+const compareMonths = 6
+
+// compareCategoriesMonthly lays out the monthly spending of cat1 and cat2
+// over the last months months, this one included, as a fixed-width table
+// ending with their totals and the difference between them.
+func (b *Bot) compareCategoriesMonthly(ctx context.Context, cat1, cat2 string, months int) (string, error) {
+	now := time.Now()
+	first := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.Local)
+	rows, err := b.getEntriesBetween(ctx, first, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to compare categories: %w", err)
+	}
+
+	spent := make(map[string][2]int) // "2006-01" to the totals of cat1 and cat2
+	for _, row := range rows {
+		month := row.Date.Format("2006-01")
+		totals := spent[month]
+		switch normalizeCategory(row.Category) {
+		case cat1:
+			totals[0] += row.Nominal
+		case cat2:
+			totals[1] += row.Nominal
+		}
+		spent[month] = totals
+	}
+
+	var table bytes.Buffer
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Bulan\t%s\t%s\t\n", cat1, cat2)
+	total1, total2 := 0, 0
+	for month := first; !month.After(now); month = month.AddDate(0, 1, 0) {
+		totals := spent[month.Format("2006-01")]
+		total1 += totals[0]
+		total2 += totals[1]
+		fmt.Fprintf(w, "%s %s\t%s\t%s\t\n", monthNames[month.Month()-1][:3], month.Format("06"),
+			formatRupiah(totals[0]), formatRupiah(totals[1]))
+	}
+	fmt.Fprintf(w, "Total\t%s\t%s\t\n", formatRupiah(total1), formatRupiah(total2))
+	fmt.Fprintf(w, "Selisih\t%s\t\t\n", formatRupiah(total1-total2))
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to format comparison: %w", err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📊 %s vs %s, %d bulan terakhir\n\n", html.EscapeString(cat1), html.EscapeString(cat2), months))
+	result.WriteString("<pre>" + html.EscapeString(table.String()) + "</pre>")
+	switch {
+	case total1 > total2:
+		result.WriteString(fmt.Sprintf("\n%s Rp %s lebih besar dari %s", html.EscapeString(cat1), formatRupiah(total1-total2), html.EscapeString(cat2)))
+	case total2 > total1:
+		result.WriteString(fmt.Sprintf("\n%s Rp %s lebih besar dari %s", html.EscapeString(cat2), formatRupiah(total2-total1), html.EscapeString(cat1)))
+	}
+	return result.String(), nil
+}
+
+// yearDelta shows how much later differs from earlier, with ▲ for more
+// spending and ▼ for less.
+func yearDelta(earlier, later int) string {
+	switch {
+	case later > earlier:
+		return "▲ " + formatRupiah(later-earlier)
+	case later < earlier:
+		return "▼ " + formatRupiah(earlier-later)
+	default:
+		return "="
+	}
+}
+
+// compareYears lays out the spending of each month of y1 and y2 side by side
+// as a fixed-width table, with the change from y1 to y2 and a total row. The
+// entries are read once and split by year in memory.
+func (b *Bot) compareYears(ctx context.Context, y1, y2 int) (string, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to compare years: %w", err)
+	}
+
+	var spent [2][12]int
+	for _, row := range rows {
+		switch row.Date.Year() {
+		case y1:
+			spent[0][row.Date.Month()-1] += row.Nominal
+		case y2:
+			spent[1][row.Date.Month()-1] += row.Nominal
+		}
+	}
+
+	var table bytes.Buffer
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "Bulan\t%d\t%d\tSelisih\t\n", y1, y2)
+	total1, total2 := 0, 0
+	for month := range 12 {
+		total1 += spent[0][month]
+		total2 += spent[1][month]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", monthNames[month][:3],
+			formatRupiah(spent[0][month]), formatRupiah(spent[1][month]), yearDelta(spent[0][month], spent[1][month]))
+	}
+	fmt.Fprintf(w, "Total\t%s\t%s\t%s\t\n", formatRupiah(total1), formatRupiah(total2), yearDelta(total1, total2))
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to format year comparison: %w", err)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📊 Pengeluaran %d vs %d\n\n", y1, y2))
+	result.WriteString("<pre>" + html.EscapeString(table.String()) + "</pre>")
+	if total1 > 0 {
+		change := (total2 - total1) * 100 / total1
+		switch {
+		case change > 0:
+			result.WriteString(fmt.Sprintf("\n%d naik %d%% dari %d", y2, change, y1))
+		case change < 0:
+			result.WriteString(fmt.Sprintf("\n%d turun %d%% dari %d", y2, -change, y1))
+		}
+	}
+	return result.String(), nil
+}
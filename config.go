@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds the settings the bot is started with. The toml tags are the
+// keys of the --config file, see bot.toml.example.
+type Config struct {
+	BotToken            string  `toml:"bot_token"`
+	SpreadsheetID       string  `toml:"spreadsheet_id"`
+	BackupSpreadsheetID string  `toml:"backup_spreadsheet_id"`
+	CredentialsBase64   string  `toml:"google_credentials_base64"`
+	Mode                string  `toml:"mode"` // "polling" or "webhook"
+	WebhookURL          string  `toml:"webhook_url"`
+	Port                string  `toml:"port"`
+	Store               string  `toml:"store"` // "sheets" or "sqlite"
+	SQLitePath          string  `toml:"sqlite_path"`
+	Lang                string  `toml:"lang"`
+	AdminChatIDs        []int64 `toml:"admin_chat_ids"`
+
+	// EncryptDescriptions turns on AES-256-GCM encryption of the
+	// description of new entries, with a key derived from EncryptionKey.
+	EncryptDescriptions bool   `toml:"encrypt_descriptions"`
+	EncryptionKey       string `toml:"encryption_key"`
+
+	// TLSEnabled makes the webhook server serve HTTPS itself with a Let's
+	// Encrypt certificate for the host of WebhookURL, cached in CertDir.
+	TLSEnabled bool   `toml:"tls_enabled"`
+	CertDir    string `toml:"cert_dir"`
+
+	// WorkerCount is how many updates are handled at the same time.
+	WorkerCount int `toml:"worker_count"`
+
+	// SheetsTimeoutSeconds is how long a Google Sheets request may take
+	// before it is given up.
+	SheetsTimeoutSeconds int `toml:"sheets_timeout_seconds"`
+
+	// ReceiptFolderID is the Google Drive folder receipt photos are uploaded
+	// to. Receipts are not saved when it is empty.
+	ReceiptFolderID string `toml:"receipt_folder_id"`
+
+	// OpenAIAPIKey is used to transcribe voice messages with Whisper. Voice
+	// messages are not supported when it is empty.
+	OpenAIAPIKey string `toml:"openai_api_key"`
+
+	// InlineMode answers "@bot <term>" typed in any chat with the user's
+	// matching entries. Inline mode must also be turned on for the bot with
+	// /setinline in @BotFather.
+	InlineMode bool `toml:"inline_mode"`
+
+	// SendGridAPIKey and ReportEmailFrom are used to email monthly reports
+	// with /monthly_report email. Emails are not supported when either is
+	// empty.
+	SendGridAPIKey  string `toml:"sendgrid_api_key"`
+	ReportEmailFrom string `toml:"report_email_from"`
+
+	// ExportSQLite is set when the bot is started as
+	// "chatkeutelegolang --export-sqlite" to copy the spreadsheet into
+	// SQLitePath instead of running.
+	ExportSQLite bool `toml:"-"`
+
+	// MigrateSheets is set when the bot is started with --migrate-sheets to
+	// apply the pending schema migrations, see migrations, and exit.
+	MigrateSheets bool `toml:"-"`
+
+	// DryRun is set by the --dry-run flag. Commands are processed as usual
+	// but writes are only logged.
+	DryRun bool `toml:"-"`
+}
+
+// LoadConfig reads the configuration from the file given with --config, if
+// any, and then from the environment, which overrides the file. The returned
+// error lists every missing or invalid variable at once.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	if path := flagValue("--config"); path != "" {
+		if _, err := toml.DecodeFile(path, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+	}
+
+	env := func(name string, value *string) {
+		if v := os.Getenv(name); v != "" {
+			*value = v
+		}
+	}
+	env("BOT_TOKEN", &cfg.BotToken)
+	env("SPREADSHEET_ID", &cfg.SpreadsheetID)
+	env("BACKUP_SPREADSHEET_ID", &cfg.BackupSpreadsheetID)
+	env("GOOGLE_CREDENTIALS_BASE64", &cfg.CredentialsBase64)
+	env("MODE", &cfg.Mode)
+	env("WEBHOOK_URL", &cfg.WebhookURL)
+	env("PORT", &cfg.Port)
+	env("STORE", &cfg.Store)
+	env("SQLITE_PATH", &cfg.SQLitePath)
+	env("LANG", &cfg.Lang)
+	env("ENCRYPTION_KEY", &cfg.EncryptionKey)
+	env("RECEIPT_FOLDER_ID", &cfg.ReceiptFolderID)
+	env("OPENAI_API_KEY", &cfg.OpenAIAPIKey)
+	env("CERT_DIR", &cfg.CertDir)
+	env("SENDGRID_API_KEY", &cfg.SendGridAPIKey)
+	env("REPORT_EMAIL_FROM", &cfg.ReportEmailFrom)
+	if v := os.Getenv("ENCRYPT_DESCRIPTIONS"); v != "" {
+		cfg.EncryptDescriptions = v == "true"
+	}
+	if v := os.Getenv("TLS_ENABLED"); v != "" {
+		cfg.TLSEnabled = v == "true"
+	}
+	if v := os.Getenv("INLINE_MODE"); v != "" {
+		cfg.InlineMode = v == "true"
+	}
+	cfg.ExportSQLite = hasFlag("--export-sqlite")
+	cfg.DryRun = hasFlag("--dry-run")
+	cfg.MigrateSheets = hasFlag("--migrate-sheets")
+	if cfg.Mode == "" {
+		cfg.Mode = "polling"
+	}
+	if cfg.Store == "" {
+		cfg.Store = "sheets"
+	}
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = "chatkeu.db"
+	}
+	if cfg.CertDir == "" {
+		cfg.CertDir = "certs"
+	}
+	if cfg.WorkerCount == 0 {
+		cfg.WorkerCount = 4
+	}
+	if cfg.SheetsTimeoutSeconds == 0 {
+		cfg.SheetsTimeoutSeconds = 10
+	}
+
+	var missing, invalid []string
+	require := func(name, value string) {
+		if value == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	require("BOT_TOKEN", cfg.BotToken)
+	if cfg.Store == "sheets" || cfg.ExportSQLite {
+		require("SPREADSHEET_ID", cfg.SpreadsheetID)
+		require("GOOGLE_CREDENTIALS_BASE64", cfg.CredentialsBase64)
+	}
+
+	if cfg.EncryptDescriptions {
+		require("ENCRYPTION_KEY", cfg.EncryptionKey)
+	}
+
+	switch cfg.Mode {
+	case "polling":
+	case "webhook":
+		require("WEBHOOK_URL", cfg.WebhookURL)
+		require("PORT", cfg.Port)
+	default:
+		invalid = append(invalid, fmt.Sprintf("MODE=%q (use polling or webhook)", cfg.Mode))
+	}
+
+	if cfg.Store != "sheets" && cfg.Store != "sqlite" {
+		invalid = append(invalid, fmt.Sprintf("STORE=%q (use sheets or sqlite)", cfg.Store))
+	}
+
+	if workerCount := os.Getenv("WORKER_COUNT"); workerCount != "" {
+		count, err := strconv.Atoi(workerCount)
+		if err != nil || count < 1 {
+			invalid = append(invalid, fmt.Sprintf("WORKER_COUNT=%q (use a number of at least 1)", workerCount))
+		}
+		cfg.WorkerCount = count
+	} else if cfg.WorkerCount < 1 {
+		invalid = append(invalid, fmt.Sprintf("worker count %d must be at least 1", cfg.WorkerCount))
+	}
+
+	if timeout := os.Getenv("SHEETS_TIMEOUT_SECONDS"); timeout != "" {
+		seconds, err := strconv.Atoi(timeout)
+		if err != nil || seconds < 1 {
+			invalid = append(invalid, fmt.Sprintf("SHEETS_TIMEOUT_SECONDS=%q (use a number of at least 1)", timeout))
+		}
+		cfg.SheetsTimeoutSeconds = seconds
+	} else if cfg.SheetsTimeoutSeconds < 1 {
+		invalid = append(invalid, fmt.Sprintf("sheets timeout %d must be at least 1 second", cfg.SheetsTimeoutSeconds))
+	}
+
+	if adminChatIDs := os.Getenv("ADMIN_CHAT_IDS"); adminChatIDs != "" {
+		cfg.AdminChatIDs = nil
+		for _, id := range strings.Split(adminChatIDs, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			chatID, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("ADMIN_CHAT_IDS contains %q which is not a chat ID", id))
+				continue
+			}
+			cfg.AdminChatIDs = append(cfg.AdminChatIDs, chatID)
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing required environment variables: "+strings.Join(missing, ", "))
+	}
+	if len(invalid) > 0 {
+		problems = append(problems, "invalid environment variables: "+strings.Join(invalid, "; "))
+	}
+	if len(problems) > 0 {
+		return cfg, fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	return cfg, nil
+}
+
+// flagValue returns the value of a flag given as "--name value" or
+// "--name=value", or "" when the bot was started without it.
+func flagValue(name string) string {
+	args := os.Args[1:]
+	for i, arg := range args {
+		if arg == name && i+1 < len(args) {
+			return args[i+1]
+		}
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// hasFlag reports whether the bot was started with the given flag.
+func hasFlag(name string) bool {
+	for _, arg := range os.Args[1:] {
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SheetsTimeout is SheetsTimeoutSeconds as a duration.
+func (cfg Config) SheetsTimeout() time.Duration {
+	return time.Duration(cfg.SheetsTimeoutSeconds) * time.Second
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// exchangeRateURL returns the latest rates of the currency appended to it.
+const exchangeRateURL = "https://open.er-api.com/v6/latest/"
+
+// exchangeRateTTL is how long the rates of a currency are reused. The API
+// only updates them once a day.
+const exchangeRateTTL = time.Hour
+
+var exchangeRateClient = &http.Client{Timeout: 10 * time.Second}
+
+// rateCache holds the exchange rates fetched per base currency.
+type rateCache struct {
+	mu    sync.Mutex
+	rates map[string]cachedRates
+}
+
+type cachedRates struct {
+	rates   map[string]float64
+	fetched time.Time
+}
+
+// exchangeRate returns how much one unit of from is worth in to.
+func (c *rateCache) exchangeRate(from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	c.mu.Lock()
+	cached, ok := c.rates[from]
+	c.mu.Unlock()
+	if !ok || time.Since(cached.fetched) >= exchangeRateTTL {
+		rates, err := fetchExchangeRates(from)
+		if err != nil {
+			return 0, err
+		}
+		cached = cachedRates{rates: rates, fetched: time.Now()}
+
+		c.mu.Lock()
+		if c.rates == nil {
+			c.rates = make(map[string]cachedRates)
+		}
+		c.rates[from] = cached
+		c.mu.Unlock()
+	}
+
+	rate, ok := cached.rates[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown currency %s", to)
+	}
+	return rate, nil
+}
+
+func fetchExchangeRates(base string) (map[string]float64, error) {
+	resp, err := exchangeRateClient.Get(exchangeRateURL + base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Result string             `json:"result"`
+		Rates  map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+	if body.Result != "success" {
+		return nil, fmt.Errorf("unknown currency %s", base)
+	}
+	return body.Rates, nil
+}
+
+func (b *Bot) pendingConversion(chatID int64) (int, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	nominal, ok := b.pendingConversions[chatID]
+	return nominal, ok
+}
+
+func (b *Bot) setPendingConversion(chatID int64, nominal int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pendingConversions[chatID] = nominal
+}
+
+func (b *Bot) clearPendingConversion(chatID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pendingConversions, chatID)
+}
+
+// parseAmount parses an amount written with a comma before the decimals,
+// falling back to the shorthands of normalizeNominal such as 10rb.
+func parseAmount(s string) (float64, bool) {
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.ReplaceAll(s, ".", ""), ",", "."), 64)
+	if err != nil {
+		nominal := normalizeNominal(s)
+		return float64(nominal), nominal > 0
+	}
+	return amount, amount > 0
+}
+
+// formatDecimal formats value with dots between thousands and up to
+// decimals digits after the comma, e.g. 1234.5 as "1.234,5".
+func formatDecimal(value float64, decimals int) string {
+	scale := math.Pow(10, float64(decimals))
+	scaled := int64(math.Round(math.Abs(value) * scale))
+	whole, fraction := scaled/int64(scale), scaled%int64(scale)
+
+	result := formatRupiah(int(whole))
+	if value < 0 {
+		result = "-" + result
+	}
+	if fraction > 0 {
+		result += "," + strings.TrimRight(fmt.Sprintf("%0*d", decimals, fraction), "0")
+	}
+	return result
+}
+
+// formatMoney formats an amount of currency, Rupiah the way the bot shows
+// them everywhere else.
+func formatMoney(amount float64, currency string) string {
+	if currency == "IDR" {
+		return "Rp " + formatRupiah(int(math.Round(amount)))
+	}
+	return formatDecimal(amount, 2) + " " + currency
+}
+
+// formatRate shows small rates with more digits so they do not round to 0.
+func formatRate(rate float64) string {
+	if rate < 1 {
+		return formatDecimal(rate, 6)
+	}
+	return formatDecimal(rate, 2)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks an encrypted description. Descriptions without it
+// were written before encryption was turned on and are read as-is.
+const encryptedPrefix = "enc:"
+
+// descriptionCipher encrypts descriptions with AES-256-GCM. The chat ID of
+// the entry's owner is bound as additional data, so an encrypted description
+// cannot be moved to another user's entry.
+type descriptionCipher struct {
+	aead cipher.AEAD
+}
+
+// newDescriptionCipher derives the AES-256 key from key with SHA-256.
+func newDescriptionCipher(key string) (*descriptionCipher, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &descriptionCipher{aead: aead}, nil
+}
+
+func (c *descriptionCipher) encrypt(chatID, text string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(text), []byte(chatID))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *descriptionCipher) decrypt(chatID, value string) (string, error) {
+	if !strings.HasPrefix(value, encryptedPrefix) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode description: %w", err)
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errors.New("encrypted description is too short")
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	text, err := c.aead.Open(nil, nonce, ciphertext, []byte(chatID))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt description: %w", err)
+	}
+	return string(text), nil
+}
+
+// refChatID returns the chat ID part of a messageRef.
+func refChatID(ref string) string {
+	chatID, _, _ := strings.Cut(ref, ":")
+	return chatID
+}
+
+// sealDescription encrypts the description of an entry owned by chatID when
+// encryption is turned on.
+func (b *Bot) sealDescription(chatID, text string) (string, error) {
+	if !b.encryptDescriptions || b.cipher == nil {
+		return text, nil
+	}
+	return b.cipher.encrypt(chatID, text)
+}
+
+// openDescription returns the readable form of a description cell owned by
+// chatID. Descriptions that cannot be decrypted are shown as locked.
+func (b *Bot) openDescription(chatID string, value interface{}) string {
+	text := fmt.Sprintf("%v", value)
+	if !strings.HasPrefix(text, encryptedPrefix) {
+		return text
+	}
+	if b.cipher == nil {
+		return "🔒"
+	}
+	text, err := b.cipher.decrypt(chatID, text)
+	if err != nil {
+		return "🔒"
+	}
+	return text
+}
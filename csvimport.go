@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// maxCSVSize is the largest CSV body /validate-csv accepts.
+const maxCSVSize = 1 << 20
+
+// CSVRow is a line of an expense CSV that can be imported.
+type CSVRow struct {
+	Line        int    `json:"line"`
+	Nominal     int    `json:"nominal"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	Date        string `json:"date,omitempty"` // DD-MM-YYYY, empty for today
+}
+
+// CSVError is a line of an expense CSV that cannot be imported.
+type CSVError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// CSVValidation is the result of checking an expense CSV.
+type CSVValidation struct {
+	Valid        []CSVRow   `json:"valid"`
+	Invalid      []CSVError `json:"invalid"`
+	ValidCount   int        `json:"valid_count"`
+	InvalidCount int        `json:"invalid_count"`
+}
+
+// parseCSVRecord checks a CSV record laid out like an expense message:
+// Nominal, Kategori, Keterangan and an optional Tanggal.
+func parseCSVRecord(record []string) (CSVRow, error) {
+	if len(record) != 3 && len(record) != 4 {
+		return CSVRow{}, fmt.Errorf("expected 3 or 4 columns, got %d", len(record))
+	}
+
+	nominalStr := strings.TrimSpace(record[0])
+	nominal := normalizeNominal(nominalStr)
+	if nominal <= 0 {
+		return CSVRow{}, fmt.Errorf("invalid nominal %q", nominalStr)
+	}
+	category := strings.TrimSpace(record[1])
+	if category == "" {
+		return CSVRow{}, errors.New("category is empty")
+	}
+
+	row := CSVRow{Nominal: nominal, Category: normalizeCategory(category), Description: strings.TrimSpace(record[2])}
+	if len(record) == 4 {
+		dateStr := strings.TrimSpace(record[3])
+		date, err := parseEntryDate(dateStr)
+		if errors.Is(err, errFutureDate) {
+			return CSVRow{}, fmt.Errorf("date %q is in the future", dateStr)
+		}
+		if err != nil {
+			return CSVRow{}, fmt.Errorf("invalid date %q, use DD-MM-YYYY", dateStr)
+		}
+		row.Date = date.Format("02-01-2006")
+	}
+	return row, nil
+}
+
+// validateExpenseCSV checks every line of an expense CSV. A first line
+// starting with "Nominal" is taken as a header and skipped.
+func validateExpenseCSV(r io.Reader) (CSVValidation, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // the column count is checked per line
+	reader.TrimLeadingSpace = true
+
+	result := CSVValidation{Valid: []CSVRow{}, Invalid: []CSVError{}}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			result.Invalid = append(result.Invalid, CSVError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if line == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "nominal") {
+			continue
+		}
+
+		row, err := parseCSVRecord(record)
+		if err != nil {
+			result.Invalid = append(result.Invalid, CSVError{Line: line, Error: err.Error()})
+			continue
+		}
+		row.Line = line
+		result.Valid = append(result.Valid, row)
+	}
+
+	result.ValidCount, result.InvalidCount = len(result.Valid), len(result.Invalid)
+	return result, nil
+}
+
+// handleValidateCSV checks the expense CSV in the request body without
+// writing anything, so a file can be fixed before it is imported.
+func handleValidateCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, err := validateExpenseCSV(http.MaxBytesReader(w, r.Body, maxCSVSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("failed to write CSV validation: %v", err)
+	}
+}
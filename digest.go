@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// digestAverageDays is how many days before yesterday its total is
+// compared against.
+const digestAverageDays = 7
+
+// digestDue reports whether pref's morning digest should be sent at now.
+func digestDue(pref UserPreference, now time.Time) bool {
+	return pref.DigestTime != "" && now.In(pref.location()).Format("15:04") == pref.DigestTime
+}
+
+func (b *Bot) sendDueDigests(ctx context.Context, now time.Time) {
+	b.mu.RLock()
+	prefs := make([]UserPreference, 0, len(b.prefs))
+	for _, pref := range b.prefs {
+		prefs = append(prefs, pref)
+	}
+	b.mu.RUnlock()
+
+	for _, pref := range prefs {
+		if !digestDue(pref, now) {
+			continue
+		}
+		digest, err := b.getDigest(ctx, pref, now)
+		if err != nil {
+			log.Printf("failed to build digest of %d: %v", pref.ChatID, err)
+			continue
+		}
+		if _, err := b.sendMessage(tgbotapi.NewMessage(pref.ChatID, digest)); err != nil {
+			log.Printf("failed to send digest to %d: %v", pref.ChatID, err)
+		}
+	}
+}
+
+// getDigest builds the morning briefing of pref's user: yesterday's total
+// against the average of the days before, this month's top category and how
+// much of the monthly limit has been used.
+func (b *Bot) getDigest(ctx context.Context, pref UserPreference, now time.Time) (string, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	now = now.In(pref.location())
+	yesterday := now.AddDate(0, 0, -1).Format("02-01-2006")
+	previousDays := make(map[string]bool, digestAverageDays)
+	for i := 2; i <= digestAverageDays+1; i++ {
+		previousDays[now.AddDate(0, 0, -i).Format("02-01-2006")] = true
+	}
+
+	yesterdayTotal, previousTotal, monthTotal := 0, 0, 0
+	categories := make(map[string]int)
+	for _, row := range rows {
+		switch dateStr := row.Date.Format("02-01-2006"); {
+		case dateStr == yesterday:
+			yesterdayTotal += row.Nominal
+		case previousDays[dateStr]:
+			previousTotal += row.Nominal
+		}
+
+		if row.Date.Year() == now.Year() && row.Date.Month() == now.Month() {
+			monthTotal += row.Nominal
+			categories[row.Category] += row.Nominal
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("☀️ Selamat pagi, %s!\n\n", pref.Name))
+	result.WriteString(fmt.Sprintf("📅 Pengeluaran kemarin: Rp %s\n", formatRupiah(yesterdayTotal)))
+
+	average := previousTotal / digestAverageDays
+	switch {
+	case average == 0:
+		result.WriteString(fmt.Sprintf("📊 Belum ada pengeluaran dalam %d hari sebelumnya\n", digestAverageDays))
+	case yesterdayTotal > average:
+		result.WriteString(fmt.Sprintf("📈 %d%% di atas rata-rata %d hari (Rp %s)\n",
+			(yesterdayTotal-average)*100/average, digestAverageDays, formatRupiah(average)))
+	case yesterdayTotal < average:
+		result.WriteString(fmt.Sprintf("📉 %d%% di bawah rata-rata %d hari (Rp %s)\n",
+			(average-yesterdayTotal)*100/average, digestAverageDays, formatRupiah(average)))
+	default:
+		result.WriteString(fmt.Sprintf("➖ Sama dengan rata-rata %d hari (Rp %s)\n", digestAverageDays, formatRupiah(average)))
+	}
+
+	topCategory, topTotal := "", 0
+	for category, total := range categories {
+		if total > topTotal || (total == topTotal && category < topCategory) {
+			topCategory, topTotal = category, total
+		}
+	}
+	if topTotal > 0 {
+		result.WriteString(fmt.Sprintf("🏆 Kategori terbesar bulan ini: %s (Rp %s)\n", topCategory, formatRupiah(topTotal)))
+	}
+
+	if pref.MonthlyLimit > 0 {
+		result.WriteString(fmt.Sprintf("🎯 Batas bulanan terpakai: %d%% (Rp %s dari Rp %s)\n",
+			monthTotal*100/pref.MonthlyLimit, formatRupiah(monthTotal), formatRupiah(pref.MonthlyLimit)))
+	} else {
+		result.WriteString(fmt.Sprintf("💰 Total bulan ini: Rp %s\n", formatRupiah(monthTotal)))
+	}
+	return result.String(), nil
+}
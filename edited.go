@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (b *Bot) setMessageRow(chatID int64, messageID, row int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.messageRowMap[chatID] == nil {
+		b.messageRowMap[chatID] = make(map[int]int)
+	}
+	b.messageRowMap[chatID][messageID] = row
+}
+
+// messageRow returns the row recorded from a message, looking it up in the
+// sheet when the message was sent before the bot last started.
+func (b *Bot) messageRow(ctx context.Context, message *tgbotapi.Message) (int, error) {
+	b.mu.RLock()
+	row, ok := b.messageRowMap[message.Chat.ID][message.MessageID]
+	b.mu.RUnlock()
+	if ok {
+		return row, nil
+	}
+
+	row, err := b.rowByMessageRef(ctx, messageRef(message))
+	if err != nil || row == 0 {
+		return 0, err
+	}
+	b.setMessageRow(message.Chat.ID, message.MessageID, row)
+	return row, nil
+}
+
+// handleEditedMessage updates the entry recorded from a message the user
+// has since edited. Edits of other messages are ignored.
+func (b *Bot) handleEditedMessage(ctx context.Context, message *tgbotapi.Message) {
+	chatId := message.Chat.ID
+
+	parts := strings.Split(message.Text, ",")
+	if len(parts) < 3 || strings.HasPrefix(message.Text, "/") {
+		return
+	}
+
+	row, err := b.messageRow(ctx, message)
+	if err != nil {
+		log.Printf("failed to find entry of edited message: %v", err)
+		return
+	}
+	if row == 0 {
+		return
+	}
+
+	nominal := normalizeNominal(strings.TrimSpace(parts[0]))
+	budget := b.expandAlias(ctx, chatId, strings.TrimSpace(parts[1]))
+	keterangan := strings.TrimSpace(parts[2])
+
+	change, err := b.editEntry(ctx, row, nominal, budget, keterangan)
+	if err != nil {
+		b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal memperbarui entri dari pesan yang diedit"))
+		return
+	}
+	b.pushHistory(chatId, change)
+
+	entry, _ := b.getEntryByNumber(ctx, row)
+	msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✏️ Entri #%d diperbarui sesuai pesan yang diedit:\n%s", row, entry))
+	msg.ReplyToMessageID = message.MessageID
+	b.sendMessage(msg)
+}
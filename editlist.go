@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// /edit without a row number lists the latest entries to pick from,
+// editListPageSize at a time.
+const (
+	editListEntries     = 10
+	editListPageSize    = 5
+	editListDescription = 20 // characters of the description shown on a button
+)
+
+// editListKeyboard lists page of the latest entries, newest first, as
+// buttons that start editing the entry. It returns nil when there are no
+// entries.
+func (b *Bot) editListKeyboard(ctx context.Context, page int) (*tgbotapi.InlineKeyboardMarkup, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	latest := rows[max(0, len(rows)-editListEntries):]
+	pages := (len(latest) + editListPageSize - 1) / editListPageSize
+	page = max(0, min(page, pages-1))
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i := page * editListPageSize; i < min(len(latest), (page+1)*editListPageSize); i++ {
+		row := latest[len(latest)-1-i]
+		label := fmt.Sprintf("#%d · Rp %s · %s", row.RowNum, formatRupiah(row.Nominal), truncateRunes(row.Description, editListDescription))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("edit_row:%d", row.RowNum)),
+		))
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("« Sebelumnya", fmt.Sprintf("edit_page:%d", page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("Berikutnya »", fmt.Sprintf("edit_page:%d", page+1)))
+	}
+	if len(nav) > 0 {
+		keyboard = append(keyboard, nav)
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return &markup, nil
+}
+
+// truncateRunes shortens s to at most n characters, marking the cut with an
+// ellipsis.
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// startEditing shows the entry at rowNumber and waits for its new values.
+func (b *Bot) startEditing(ctx context.Context, chatID int64, rowNumber int) {
+	entry, err := b.getEntryByNumber(ctx, rowNumber)
+	if err != nil {
+		b.sendMessage(tgbotapi.NewMessage(chatID, "❌ Entri tidak ditemukan"))
+		return
+	}
+
+	b.setEditing(chatID, rowNumber)
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("✏️ Edit entri #%d:\n%s\n\nKirim data baru dalam format:\nNominal, Kategori, Keterangan\nContoh: 10rb, Makanan, Makan Siang di Kantin", rowNumber, entry))
+	b.sendMessage(msg)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Mailer sends emails with one attachment.
+type Mailer interface {
+	Send(to, subject, body string, attachment Attachment) error
+}
+
+// Attachment is a file attached to an email.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+var mailClient = &http.Client{Timeout: 30 * time.Second}
+
+// SendGridMailer sends emails with the SendGrid v3 API.
+type SendGridMailer struct {
+	apiKey string
+	from   string
+}
+
+func NewSendGridMailer(apiKey, from string) *SendGridMailer {
+	return &SendGridMailer{apiKey: apiKey, from: from}
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+}
+
+type sendGridMessage struct {
+	Personalizations []struct {
+		To []sendGridAddress `json:"to"`
+	} `json:"personalizations"`
+	From        sendGridAddress      `json:"from"`
+	Subject     string               `json:"subject"`
+	Content     []sendGridContent    `json:"content"`
+	Attachments []sendGridAttachment `json:"attachments"`
+}
+
+func (m *SendGridMailer) Send(to, subject, body string, attachment Attachment) error {
+	message := sendGridMessage{
+		From:    sendGridAddress{Email: m.from},
+		Subject: subject,
+		Content: []sendGridContent{{Type: "text/plain", Value: body}},
+		Attachments: []sendGridAttachment{{
+			Content:     base64.StdEncoding.EncodeToString(attachment.Data),
+			Type:        attachment.ContentType,
+			Filename:    attachment.Name,
+			Disposition: "attachment",
+		}},
+	}
+	message.Personalizations = make([]struct {
+		To []sendGridAddress `json:"to"`
+	}, 1)
+	message.Personalizations[0].To = []sendGridAddress{{Email: to}}
+
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, sendGridURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build email request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := mailClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// SendGrid answers 202 Accepted with an empty body, and a JSON list of
+	// errors otherwise.
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to send email: %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// reportEmailHour is the hour, in the user's timezone, of the first day of
+// the month at which last month's report is emailed.
+const reportEmailHour = 8
+
+// emailMonthlyReport emails the report of month to the address to, as a PDF
+// attachment.
+func (b *Bot) emailMonthlyReport(ctx context.Context, to string, month time.Time) error {
+	report, err := b.getMonthlyReport(ctx, month)
+	if err != nil {
+		return err
+	}
+	title := fmt.Sprintf("Laporan Pengeluaran %s %d", monthNames[report.Month.Month()-1], report.Month.Year())
+	attachment := Attachment{
+		Name:        fmt.Sprintf("laporan-%s.pdf", report.Month.Format("2006-01")),
+		ContentType: "application/pdf",
+		Data:        report.pdf(),
+	}
+	return b.mailer.Send(to, title, report.format(), attachment)
+}
+
+// sendReportEmails emails last month's report to the users who saved an
+// address with /monthly_report email, once on the first day of every month.
+func (b *Bot) sendReportEmails(ctx context.Context, now time.Time) {
+	if b.mailer == nil {
+		return
+	}
+
+	b.mu.RLock()
+	prefs := make([]UserPreference, 0, len(b.prefs))
+	for _, pref := range b.prefs {
+		prefs = append(prefs, pref)
+	}
+	b.mu.RUnlock()
+
+	for _, pref := range prefs {
+		local := now.In(pref.location())
+		if pref.ReportEmail == "" || local.Day() != 1 || local.Hour() != reportEmailHour {
+			continue
+		}
+
+		// The email may be attempted every minute of the hour, send it once
+		month := local.Format("2006-01")
+		b.mu.Lock()
+		sent := b.reportEmailMonth[pref.ChatID] == month
+		b.reportEmailMonth[pref.ChatID] = month
+		b.mu.Unlock()
+		if sent {
+			continue
+		}
+
+		if err := b.emailMonthlyReport(ctx, pref.ReportEmail, local.AddDate(0, -1, 0)); err != nil {
+			log.Printf("failed to email monthly report to %d: %v", pref.ChatID, err)
+		}
+	}
+}
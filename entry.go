@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// expenseHeader is the header row of the main data tab. Pesan holds the
+// Telegram message an entry was recorded from, see messageRef, Struk the URL
+// of its receipt photo, see attachReceipt, Pengirim who sent the message, see
+// senderName, and Lokasi where it was spent, see saveLocation.
+var expenseHeader = []interface{}{"No", "Tanggal", "Nominal", "Kategori", "Keterangan", "Pesan", "Struk", "Pengirim", "Lokasi"}
+
+// messageRef identifies a Telegram message by its chat and the time it was
+// sent, which stays the same when the message is edited.
+func messageRef(message *tgbotapi.Message) string {
+	return fmt.Sprintf("%d:%d", message.Chat.ID, message.Date)
+}
+
+// senderName is how the sender of a message is shown on /leaderboard: their
+// username, or their name when they have none.
+func senderName(user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// appendData adds an entry dated date, recorded from the message ref sent by
+// sender, and returns how to undo it. sender is empty for entries the bot
+// records by itself. The entry goes to the tab of the active account of the
+// chat of ref, see entrySheet. Entries are added one at a time, at the row
+// after the last one, so that two chats recording at once do not get the
+// same row.
+func (b *Bot) appendData(ctx context.Context, nominal int, budget, keterangan string, date time.Time, ref, sender string) (HistoryEntry, error) {
+	sheet, err := b.entrySheet(ctx, refChatID(ref))
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+
+	b.appendMu.Lock()
+	defer b.appendMu.Unlock()
+
+	store := storeWithContext(ctx, b.store)
+	col := b.sheetConfig.RowNumCol
+	rows, err := store.Get(b.tabRange(sheet, col+":"+col))
+	if err != nil {
+		return HistoryEntry{}, fmt.Errorf("failed to get row count: %w", err)
+	}
+	nextRow := len(rows) + 1
+
+	// Dates are stored in DD-MM-YYYY format
+	entryDate := date.Format("02-01-2006")
+
+	keterangan, err = b.sealDescription(refChatID(ref), keterangan)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+
+	values := [][]interface{}{b.sheetConfig.newRow(nextRow, entryDate, nominal, normalizeCategory(budget), keterangan, ref, sender)}
+	if err := store.Update(b.tabRange(sheet, fmt.Sprintf("A%d", nextRow)), values); err != nil {
+		return HistoryEntry{}, err
+	}
+	b.logAudit(ctx, refChatID(ref), auditAppend, nextRow, "", auditEntryValue(nominal, normalizeCategory(budget), keterangan))
+	return HistoryEntry{Action: "tambah", Row: nextRow, Sheet: sheet}, nil
+}
+
+// errFutureDate is returned by parseEntryDate for dates after tomorrow.
+var errFutureDate = errors.New("date is in the future")
+
+// parseEntryDate parses the optional DD-MM-YYYY date of a new entry. Dates
+// more than a day ahead are rejected, a day of slack is left for timezones.
+func parseEntryDate(s string) (time.Time, error) {
+	date, err := time.ParseInLocation("02-01-2006", normalizeDateString(s), time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if date.After(tomorrow) {
+		return time.Time{}, errFutureDate
+	}
+	return date, nil
+}
+
+// removeLastEntry clears the last entry and returns how to undo it.
+func (b *Bot) removeLastEntry(ctx context.Context) (HistoryEntry, error) {
+	store := storeWithContext(ctx, b.store)
+	col := b.sheetConfig.RowNumCol
+	rows, err := store.Get(b.expenseRange(col + ":" + col))
+	if err != nil {
+		return HistoryEntry{}, fmt.Errorf("failed to get row count: %w", err)
+	}
+
+	if len(rows) < 2 {
+		return HistoryEntry{}, fmt.Errorf("no entries to remove")
+	}
+
+	lastRow := len(rows)
+	previous, err := b.rowSnapshot(ctx, lastRow)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+
+	if err := store.Clear(b.expenseRange(b.sheetConfig.rowRange(lastRow))); err != nil {
+		return HistoryEntry{}, err
+	}
+	oldValue, owner := b.auditRowValue(previous)
+	b.logAudit(ctx, owner, auditDelete, lastRow, oldValue, "")
+	return HistoryEntry{Action: "hapus", Row: lastRow, Previous: previous}, nil
+}
+
+// editEntry overwrites an entry and returns how to undo it.
+func (b *Bot) editEntry(ctx context.Context, rowNumber int, nominal int, budget, keterangan string) (HistoryEntry, error) {
+	previous, err := b.rowSnapshot(ctx, rowNumber)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+
+	// Get current date in DD-MM-YYYY format
+	currentDate := time.Now().Format("02-01-2006")
+
+	// The entry keeps its owner, so the description is sealed for them
+	owner := ""
+	if len(previous) > refColumn {
+		owner = refChatID(fmt.Sprintf("%v", previous[refColumn]))
+	}
+	keterangan, err = b.sealDescription(owner, keterangan)
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+
+	// Every field but the message ref and the receipt is rewritten
+	c := b.sheetConfig
+	updates := []RangeValues{
+		{Range: b.expenseRange(c.cell(c.RowNumCol, rowNumber)), Values: [][]interface{}{{rowNumber}}},
+		{Range: b.expenseRange(c.cell(c.DateCol, rowNumber)), Values: [][]interface{}{{currentDate}}},
+		{Range: b.expenseRange(c.cell(c.NominalCol, rowNumber)), Values: [][]interface{}{{nominal}}},
+		{Range: b.expenseRange(c.cell(c.CategoryCol, rowNumber)), Values: [][]interface{}{{normalizeCategory(budget)}}},
+		{Range: b.expenseRange(c.cell(c.DescriptionCol, rowNumber)), Values: [][]interface{}{{keterangan}}},
+	}
+	if err := storeWithContext(ctx, b.store).BatchUpdate(updates); err != nil {
+		return HistoryEntry{}, err
+	}
+	oldValue, _ := b.auditRowValue(previous)
+	b.logAudit(ctx, owner, auditEdit, rowNumber, oldValue, auditEntryValue(nominal, normalizeCategory(budget), keterangan))
+	return HistoryEntry{Action: "edit", Row: rowNumber, Previous: previous}, nil
+}
+
+// rowByMessageRef finds the row of the entry recorded from the message ref,
+// or 0 when there is none.
+func (b *Bot) rowByMessageRef(ctx context.Context, ref string) (int, error) {
+	col := columnLetter(refColumn)
+	rows, err := storeWithContext(ctx, b.store).Get(b.expenseRange(col + ":" + col))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get message references: %w", err)
+	}
+	for i, row := range rows {
+		if i > 0 && len(row) > 0 && fmt.Sprintf("%v", row[0]) == ref {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (b *Bot) getEntryByNumber(ctx context.Context, rowNumber int) (string, error) {
+	raw, err := storeWithContext(ctx, b.store).Get(b.expenseRange(b.sheetConfig.rowRange(rowNumber)))
+	if err != nil {
+		return "", fmt.Errorf("failed to get entry: %w", err)
+	}
+
+	if len(raw) == 0 {
+		return "", fmt.Errorf("entry not found")
+	}
+
+	row, err := b.sheetConfig.parseRow(raw[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid entry format: %w", err)
+	}
+	row.RowNum = rowNumber
+	row.Description = b.openDescription(refChatID(row.Ref), row.Description)
+
+	return row.format(), nil
+}
+
+// normalizeCategory title-cases a category so that "makanan", "Makanan" and
+// "MAKANAN" are all stored as "Makanan".
+func normalizeCategory(category string) string {
+	category = strings.Join(strings.Fields(category), " ")
+	return cases.Title(language.Indonesian).String(strings.ToLower(category))
+}
+
+// normalizeCategories rewrites the category column of every existing entry
+// with normalizeCategory and returns how many entries were changed.
+func (b *Bot) normalizeCategories(ctx context.Context) (int, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var updates []RangeValues
+	for _, row := range rows {
+		if normalized := normalizeCategory(row.Category); normalized != row.Category {
+			updates = append(updates, RangeValues{
+				Range:  b.expenseRange(b.sheetConfig.cell(b.sheetConfig.CategoryCol, row.RowNum)),
+				Values: [][]interface{}{{normalized}},
+			})
+		}
+	}
+
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	if err := storeWithContext(ctx, b.store).BatchUpdate(updates); err != nil {
+		return 0, fmt.Errorf("failed to update categories: %w", err)
+	}
+	return len(updates), nil
+}
+
+// categoryRows returns the sheet row numbers of the entries whose category
+// matches the given one, ignoring case.
+func (b *Bot) categoryRows(ctx context.Context, category string) ([]int, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []int
+	for _, row := range rows {
+		if strings.EqualFold(row.Category, category) {
+			matches = append(matches, row.RowNum)
+		}
+	}
+	return matches, nil
+}
+
+func (b *Bot) countCategory(ctx context.Context, category string) (int, error) {
+	rows, err := b.categoryRows(ctx, category)
+	return len(rows), err
+}
+
+// mergeCategories renames every entry of category from to category to and
+// returns how many entries were changed.
+func (b *Bot) mergeCategories(ctx context.Context, from, to string) (int, error) {
+	rows, err := b.categoryRows(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	updates := make([]RangeValues, 0, len(rows))
+	for _, row := range rows {
+		updates = append(updates, RangeValues{
+			Range:  b.expenseRange(b.sheetConfig.cell(b.sheetConfig.CategoryCol, row)),
+			Values: [][]interface{}{{to}},
+		})
+	}
+	if err := storeWithContext(ctx, b.store).BatchUpdate(updates); err != nil {
+		return 0, fmt.Errorf("failed to merge categories: %w", err)
+	}
+	return len(rows), nil
+}
+
+// normalizeNominal parses an amount such as "10rb", "1,5jt" or "Rp 1.500.000,00".
+// Dots are thousand separators and a comma starts the decimal part, as is
+// usual in Indonesia. Amounts are rounded to whole rupiah.
+func normalizeNominal(nominal string) int {
+	nominal = strings.ToLower(strings.ReplaceAll(nominal, " ", ""))
+	nominal = strings.TrimPrefix(nominal, "rp")
+	nominal = strings.ReplaceAll(nominal, ".", "") // remove dot
+
+	multiplier := 1
+	switch {
+	case strings.Contains(nominal, "jt"):
+		nominal = strings.ReplaceAll(nominal, "jt", "")
+		multiplier = 1000000
+	case strings.Contains(nominal, "rb"):
+		nominal = strings.ReplaceAll(nominal, "rb", "")
+		multiplier = 1000
+	case strings.Contains(nominal, "k"):
+		nominal = strings.ReplaceAll(nominal, "k", "")
+		multiplier = 1000
+	}
+
+	if !strings.Contains(nominal, ",") {
+		result, err := strconv.Atoi(nominal)
+		if err != nil {
+			log.Printf("Error converting nominal value: %v", err)
+			return 0
+		}
+		return result * multiplier
+	}
+
+	value, err := strconv.ParseFloat(strings.Replace(nominal, ",", ".", 1), 64)
+	if err != nil {
+		log.Printf("Error converting nominal value: %v", err)
+		return 0
+	}
+	return int(math.Round(value * float64(multiplier)))
+}
+
+// formatRupiah formats nominal with dots between thousands, e.g. 1.500.000
+// or -1.500.000.
+func formatRupiah(nominal int) string {
+	var result strings.Builder
+
+	// The sign is written first so it is not counted as a digit. Negating
+	// as unsigned keeps the most negative int from overflowing.
+	abs := uint64(nominal)
+	if nominal < 0 {
+		result.WriteString("-")
+		abs = -abs
+	}
+	str := strconv.FormatUint(abs, 10)
+	length := len(str)
+
+	for i := 0; i < length; i++ {
+		if (length-i)%3 == 0 && i != 0 {
+			result.WriteString(".")
+		}
+		result.WriteByte(str[i])
+	}
+
+	return result.String()
+}
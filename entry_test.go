@@ -0,0 +1,42 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatRupiah(t *testing.T) {
+	tests := []struct {
+		nominal int
+		want    string
+	}{
+		{-1500000, "-1.500.000"},
+		{0, "0"},
+		{1500000, "1.500.000"},
+		{math.MinInt, "-9.223.372.036.854.775.808"},
+	}
+	for _, tt := range tests {
+		if got := formatRupiah(tt.nominal); got != tt.want {
+			t.Errorf("formatRupiah(%d) = %q, want %q", tt.nominal, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeNominal(t *testing.T) {
+	tests := []struct {
+		nominal string
+		want    int
+	}{
+		{"1.500.000", 1500000},
+		{"1.500", 1500},
+		{"500.000", 500000},
+		{"10.000,50", 10001},
+		{"1,5jt", 1500000},
+		{"Rp 1.500.000,00", 1500000},
+	}
+	for _, tt := range tests {
+		if got := normalizeNominal(tt.nominal); got != tt.want {
+			t.Errorf("normalizeNominal(%q) = %d, want %d", tt.nominal, got, tt.want)
+		}
+	}
+}
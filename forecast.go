@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// dailyBurnRate is the average daily spending of rows from the first of the
+// month of targetDate up to and including targetDate.
+func dailyBurnRate(rows []Row, targetDate time.Time) float64 {
+	start := time.Date(targetDate.Year(), targetDate.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 0, 0, 0, 0, time.Local)
+
+	spent := 0
+	for _, row := range rows {
+		if !row.Date.Before(start) && !row.Date.After(end) {
+			spent += row.Nominal
+		}
+	}
+	return float64(spent) / float64(targetDate.Day())
+}
+
+// forecastMonthlySpend projects the spending of the month of targetDate:
+// what was spent up to targetDate plus the daily burn rate so far for every
+// remaining day of the month.
+func forecastMonthlySpend(rows []Row, targetDate time.Time) int {
+	rate := dailyBurnRate(rows, targetDate)
+	daysInMonth := time.Date(targetDate.Year(), targetDate.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
+	return int(math.Round(rate * float64(daysInMonth)))
+}
+
+// getForecast describes where this month's spending is heading and, when
+// days is above zero, how much is likely to be spent in the next days days.
+func (b *Bot) getForecast(ctx context.Context, chatID int64, days int) (string, error) {
+	pref, _ := b.getPreference(chatID)
+	now := time.Now().In(pref.location())
+
+	rows, err := b.getEntriesBetween(ctx, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local), now)
+	if err != nil {
+		return "", fmt.Errorf("failed to get forecast: %w", err)
+	}
+	if len(rows) == 0 {
+		return "Belum ada pengeluaran bulan ini untuk dijadikan dasar proyeksi", nil
+	}
+
+	rate := dailyBurnRate(rows, now)
+	projected := forecastMonthlySpend(rows, now)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📈 Proyeksi akhir bulan: Rp %s (berdasarkan rata-rata Rp %s/hari)\n",
+		formatRupiah(projected), formatRupiah(int(math.Round(rate)))))
+	if days > 0 {
+		result.WriteString(fmt.Sprintf("🔮 Proyeksi %d hari ke depan: Rp %s\n", days, formatRupiah(int(math.Round(rate*float64(days))))))
+	}
+
+	if pref.MonthlyLimit > 0 {
+		if projected > pref.MonthlyLimit {
+			result.WriteString(fmt.Sprintf("\n⚠️ Dengan laju ini, pengeluaran akan melebihi budget Rp %s sebesar Rp %s",
+				formatRupiah(pref.MonthlyLimit), formatRupiah(projected-pref.MonthlyLimit)))
+		} else {
+			result.WriteString(fmt.Sprintf("\n✅ Masih di bawah budget Rp %s (sisa Rp %s)",
+				formatRupiah(pref.MonthlyLimit), formatRupiah(pref.MonthlyLimit-projected)))
+		}
+	}
+	return result.String(), nil
+}
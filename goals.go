@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const goalsSheet = "Goals"
+
+var goalsHeader = []interface{}{"ChatID", "Kategori", "Target"}
+
+// goalReportHour is the hour, in the user's timezone, of the last day of the
+// month the goal report is sent at.
+const goalReportHour = 23
+
+// goalWarningPercent is from how much of its target a category is shown as
+// at budget rather than under it.
+const goalWarningPercent = 90
+
+// GoalProgress is a category's spending in a month against its target.
+type GoalProgress struct {
+	Category string
+	Target   int
+	Spent    int
+}
+
+func (g GoalProgress) status() string {
+	switch {
+	case g.Spent > g.Target:
+		return "❌"
+	case g.Spent*100 >= g.Target*goalWarningPercent:
+		return "⚠️"
+	default:
+		return "✅"
+	}
+}
+
+// getGoals returns the monthly target of each category chatID set a goal for.
+func (b *Bot) getGoals(ctx context.Context, chatID int64) (map[string]int, error) {
+	if err := b.ensureTab(ctx, goalsSheet, goalsHeader); err != nil {
+		return nil, err
+	}
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(goalsSheet, "A:C"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	goals := make(map[string]int)
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 || fmt.Sprintf("%v", row[0]) != id { // Skip header
+			continue
+		}
+		if target, err := strconv.Atoi(fmt.Sprintf("%v", row[2])); err == nil && target > 0 {
+			goals[fmt.Sprintf("%v", row[1])] = target
+		}
+	}
+	return goals, nil
+}
+
+// setGoal sets the monthly target of category for chatID. A target of 0
+// removes the goal.
+func (b *Bot) setGoal(ctx context.Context, chatID int64, category string, target int) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, goalsSheet, goalsHeader); err != nil {
+		return err
+	}
+	rows, err := store.Get(sheetRange(goalsSheet, "A:B"))
+	if err != nil {
+		return fmt.Errorf("failed to get goals: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	row := 0
+	for i, r := range rows {
+		if i > 0 && len(r) > 1 && fmt.Sprintf("%v", r[0]) == id && strings.EqualFold(fmt.Sprintf("%v", r[1]), category) {
+			row = i + 1
+			break
+		}
+	}
+
+	switch {
+	case target == 0 && row == 0:
+		return nil
+	case target == 0:
+		err = store.Clear(sheetRange(goalsSheet, fmt.Sprintf("A%d:C%d", row, row)))
+	case row > 0:
+		err = store.Update(sheetRange(goalsSheet, fmt.Sprintf("A%d", row)), [][]interface{}{{id, category, target}})
+	default:
+		err = store.Append(sheetRange(goalsSheet, "A1"), [][]interface{}{{id, category, target}})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save goal: %w", err)
+	}
+	return nil
+}
+
+// getMonthGoalProgress compares the spending of each goal category of chatID
+// in the given month with its target.
+func (b *Bot) getMonthGoalProgress(ctx context.Context, chatID int64, year int, month time.Month) ([]GoalProgress, error) {
+	goals, err := b.getGoals(ctx, chatID)
+	if err != nil || len(goals) == 0 {
+		return nil, err
+	}
+
+	totals, err := b.getCategoryTotals(ctx, time.Date(year, month, 1, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]GoalProgress, 0, len(goals))
+	for category, target := range goals {
+		progress = append(progress, GoalProgress{Category: category, Target: target, Spent: totals[category]})
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].Category < progress[j].Category
+	})
+	return progress, nil
+}
+
+func formatGoalProgress(progress []GoalProgress, year int, month time.Month) string {
+	if len(progress) == 0 {
+		return "🎯 Belum ada target kategori. Gunakan /goal <kategori> <nominal>\nContoh: /goal Makanan 1,5jt"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("🎯 Target Kategori %02d-%d:\n\n", month, year))
+	for _, goal := range progress {
+		result.WriteString(fmt.Sprintf("%s %s: Rp %s dari Rp %s (%d%%)\n",
+			goal.status(), goal.Category, formatRupiah(goal.Spent), formatRupiah(goal.Target), goal.Spent*100/goal.Target))
+	}
+	result.WriteString(fmt.Sprintf("\n✅ di bawah target  ⚠️ di atas %d%% target  ❌ melewati target", goalWarningPercent))
+	return result.String()
+}
+
+// sendGoalReports sends every user with goals their progress on the last day
+// of the month, once a month.
+func (b *Bot) sendGoalReports(ctx context.Context, now time.Time) {
+	b.mu.RLock()
+	prefs := make([]UserPreference, 0, len(b.prefs))
+	for _, pref := range b.prefs {
+		prefs = append(prefs, pref)
+	}
+	b.mu.RUnlock()
+
+	for _, pref := range prefs {
+		local := now.In(pref.location())
+		if local.Hour() != goalReportHour || local.AddDate(0, 0, 1).Day() != 1 {
+			continue
+		}
+
+		// The report may be attempted every minute of the hour, send it once
+		month := local.Format("2006-01")
+		b.mu.Lock()
+		sent := b.goalReportMonth[pref.ChatID] == month
+		b.goalReportMonth[pref.ChatID] = month
+		b.mu.Unlock()
+		if sent {
+			continue
+		}
+
+		progress, err := b.getMonthGoalProgress(ctx, pref.ChatID, local.Year(), local.Month())
+		if err != nil {
+			log.Printf("failed to get goal progress of %d: %v", pref.ChatID, err)
+			continue
+		}
+		if len(progress) == 0 {
+			continue
+		}
+
+		text := "📅 Bulan ini segera berakhir!\n\n" + formatGoalProgress(progress, local.Year(), local.Month())
+		if _, err := b.sendMessage(tgbotapi.NewMessage(pref.ChatID, text)); err != nil {
+			log.Printf("failed to send goal report to %d: %v", pref.ChatID, err)
+		}
+	}
+}
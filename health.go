@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	healthCheckInterval = 5 * time.Minute
+	healthRetryDelay    = 30 * time.Second // first retry after a failure, doubled on each failure
+	healthAlertAfter    = 2                // consecutive failures before admins are alerted
+)
+
+// startHealthCheck checks that the store can be reached every
+// healthCheckInterval, retrying sooner after a failure, and tells the admins
+// when it goes down and when it recovers.
+func (b *Bot) startHealthCheck() {
+	go func() {
+		failures := 0
+		alerted := false
+		delay := healthCheckInterval
+
+		for {
+			time.Sleep(delay)
+
+			err := b.store.Ping()
+			if err == nil {
+				if alerted {
+					b.notifyAdmins("✅ Koneksi ke spreadsheet pulih kembali")
+				}
+				failures, alerted, delay = 0, false, healthCheckInterval
+				continue
+			}
+
+			failures++
+			log.Printf("health check failed (%d in a row): %v", failures, err)
+			if failures >= healthAlertAfter && !alerted {
+				b.notifyAdmins("🚨 Spreadsheet tidak bisa diakses: " + err.Error())
+				alerted = true
+			}
+
+			delay = healthRetryDelay << (failures - 1)
+			if delay > healthCheckInterval || delay <= 0 {
+				delay = healthCheckInterval
+			}
+		}
+	}()
+}
+
+func (b *Bot) notifyAdmins(text string) {
+	for chatID := range b.adminChatIDs {
+		if _, err := b.sendMessage(tgbotapi.NewMessage(chatID, text)); err != nil {
+			log.Printf("failed to notify admin %d: %v", chatID, err)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package main
+
+// commandHelp holds the detailed help of each command for /help <command>.
+// The command listing of /help itself is Messages.Help.
+var commandHelp = map[string]string{
+	"start": "👋 /start\n\n" +
+		"Saat pertama kali dipakai, bot memandu kamu mengisi nama, zona waktu, dan pengingat. " +
+		"Setelah itu /start menampilkan daftar perintah yang tersedia.",
+
+	"summary": "📊 /summary\n\n" +
+		"Menampilkan total pengeluaran bulan ini. Gunakan /total untuk total sejak awal pencatatan.\n\n" +
+		"Untuk total satu kategori saja:\n" +
+		"   /summary category Makanan\n\n" +
+		"Untuk grafik pengeluaran harian 30 hari terakhir:\n" +
+		"   /summary chart\n\n" +
+		"Jika kamu punya beberapa akun, /summary menjumlahkan semuanya. Untuk total satu akun saja:\n" +
+		"   /summary bisnis",
+
+	"account": "👛 /account <nama>\n\n" +
+		"Memisahkan pengeluaran, misalnya pribadi dan bisnis. Setelah pindah akun, " +
+		"pengeluaran berikutnya dicatat di tab tersendiri, contoh Bisnis_<chat ID>. " +
+		"Akun awal adalah personal, yang dicatat di tab utama.\n\n" +
+		"Nama akun hanya boleh huruf dan angka, maksimal 20 karakter. " +
+		"/undo tetap bisa dipakai, tapi /edit, /remove, /checkin dan edit pesan hanya berlaku untuk akun personal.\n\n" +
+		"Contoh:\n" +
+		"   /account bisnis - pindah ke akun bisnis\n" +
+		"   /account personal - kembali ke akun awal\n" +
+		"   /account list - daftar akun dan total bulan ini",
+
+	"subscribe": "🤝 /subscribe <chat ID>\n\n" +
+		"Mengajak chat lain, misalnya pasangan atau teman kos, mencatat pengeluaran bersama. " +
+		"Chat yang diajak menerima atau menolak lewat tombol, dan ajakan berlaku 24 jam. " +
+		"Setelah diterima, pengeluaran kalian berdua dicatat di tab milik yang mengajak, contoh Shared-<chat ID>-1.\n\n" +
+		"Chat ID bisa dilihat dengan /whoami. " +
+		"/undo tetap bisa dipakai, tapi /edit, /remove, /checkin dan edit pesan hanya berlaku untuk tab utama.\n\n" +
+		"Contoh:\n" +
+		"   /subscribe 123456789",
+
+	"unsubscribe": "🤝 /unsubscribe\n\n" +
+		"Berhenti mencatat pengeluaran bersama. Pengeluaran berikutnya kembali dicatat di akunmu sendiri, " +
+		"dan semua entri di tab bersama disalin ke sana sebagai entrimu.",
+
+	"total": "📊 /total\n\n" +
+		"Menampilkan total seluruh pengeluaran yang tercatat di spreadsheet.",
+
+	"today": "📅 /today\n\n" +
+		"Menampilkan pengeluaran hari ini: total, jumlah transaksi, dan rincian per kategori " +
+		"diurutkan dari yang terbesar.",
+
+	"weekly": "📊 /weekly\n\n" +
+		"Menampilkan semua pengeluaran minggu ini beserta totalnya.\n\n" +
+		"Target mingguan:\n" +
+		"   /weekly target 700rb - Atur target pengeluaran per minggu (Minggu-Sabtu)\n" +
+		"   /weekly target 0 - Hapus target\n" +
+		"   /weekly status - Lihat kemajuan minggu ini\n\n" +
+		"Saat pengeluaran minggu ini melewati 75% dan 100% target, peringatan ditambahkan ke konfirmasi pencatatan.",
+
+	"week": "📊 /week <tahun>-<minggu>\n\n" +
+		"Menampilkan pengeluaran pada minggu tertentu menurut penomoran minggu ISO (Senin-Minggu).\n\n" +
+		"Contoh:\n" +
+		"   /week 2024-03 - Minggu ke-3 tahun 2024\n\n" +
+		"Hanya minggu dalam 52 minggu terakhir yang bisa ditampilkan.",
+
+	"stats": "📊 /stats weekly_comparison\n\n" +
+		"Membandingkan pengeluaran minggu ini dengan minggu lalu per kategori, diurutkan dari perubahan terbesar.\n\n" +
+		"Contoh baris: Makanan: Rp 150.000 → Rp 120.000 (▼20%)\n" +
+		"Tanda — berarti kategori itu tidak ada pengeluarannya pada minggu tersebut.",
+
+	"this_week_vs_budget": "📅 /this_week_vs_budget\n\n" +
+		"Membandingkan pengeluaran minggu ini (Minggu-Sabtu) dengan budget mingguan, " +
+		"yaitu batas bulanan dibagi jumlah minggu di bulan ini (jumlah hari / 7).\n\n" +
+		"Contoh: batas Rp 3.100.000 di bulan 31 hari menjadi budget Rp 700.000 per minggu.\n" +
+		"Atur batas bulanan dulu dengan /limit monthly <nominal>.",
+
+	"weekend": "🗓 /weekend\n\n" +
+		"Menampilkan pengeluaran hari Sabtu dan Minggu pada minggu ini (Senin-Minggu), " +
+		"total akhir pekan, dan perbandingannya dengan hari kerja.\n\n" +
+		"Perbandingan memakai rata-rata per hari, karena hari kerja ada 5 hari sedangkan akhir pekan 2 hari.",
+
+	"monthly": "📊 /monthly\n\n" +
+		"Menampilkan semua pengeluaran bulan ini beserta totalnya.\n\n" +
+		"Gunakan /monthly breakdown untuk mengelompokkan pengeluaran per minggu: " +
+		"Minggu 1 (tgl 1-7), Minggu 2 (8-14), Minggu 3 (15-21), dan Minggu 4+ (22 sampai akhir bulan), " +
+		"masing-masing dengan subtotalnya.",
+
+	"report": "📑 /report monthly [tahun-bulan]\n\n" +
+		"Menampilkan laporan satu bulan: total, jumlah transaksi, rata-rata harian, " +
+		"perbandingan dengan bulan sebelumnya, total dan persentase tiap kategori, " +
+		"serta 3 pengeluaran terbesar. Tanpa bulan, laporan dibuat untuk bulan ini.\n\n" +
+		"Contoh:\n" +
+		"   /report monthly\n" +
+		"   /report monthly 2024-07\n\n" +
+		"Gunakan /report email off untuk berhenti menerima laporan bulanan lewat email, lihat /help monthly_report.",
+
+	"monthly_report": "📧 /monthly_report email <alamat>\n\n" +
+		"Mengirim laporan bulan ini ke alamat email tersebut sebagai lampiran PDF, " +
+		"lalu laporan bulan sebelumnya dikirim otomatis setiap tanggal 1 pukul 08:00.\n\n" +
+		"Gunakan /report email off untuk berhenti.\n\n" +
+		"Contoh:\n" +
+		"   /monthly_report email nama@contoh.com",
+
+	"monthly_summary_to_sheet": "📑 /monthly_summary_to_sheet [tahun-bulan]\n\n" +
+		"Menulis ringkasan satu bulan (bulan ini jika tidak disebutkan) ke tab Summary di spreadsheet, " +
+		"satu kolom per bulan mulai dari kolom B: total, jumlah transaksi, rata-rata harian, total bulan lalu, dan rincian per kategori.\n\n" +
+		"Menjalankannya lagi untuk bulan yang sama akan memperbarui kolomnya, jadi tab Summary menjadi riwayat bulanan yang mudah dibagikan.\n\n" +
+		"Contoh:\n" +
+		"   /monthly_summary_to_sheet 2024-07",
+
+	"trend": "📈 /trend\n\n" +
+		"Menampilkan grafik garis sederhana dari rata-rata pengeluaran harian 7 hari terakhir, " +
+		"untuk setiap hari dalam 30 hari terakhir. Hari dengan rata-rata tertinggi dan terendah ikut ditampilkan.\n\n" +
+		"Rata-rata bergerak membuat tren lebih mudah dibaca daripada total per hari yang naik-turun.",
+
+	"compare": "⚖️ /compare categories <kategori1> <kategori2>\n\n" +
+		"Menampilkan tabel pengeluaran dua kategori per bulan selama 6 bulan terakhir, " +
+		"beserta total dan selisihnya.\n\n" +
+		"Contoh:\n" +
+		"   /compare categories Makanan Kopi\n" +
+		"   /compare categories Makan Siang, Ojek Online - Pisahkan dengan koma untuk kategori lebih dari satu kata",
+
+	"year_vs_year": "📊 /year_vs_year <tahun1> <tahun2>\n\n" +
+		"Membandingkan pengeluaran tiap bulan dari dua tahun dalam satu tabel, dengan selisihnya " +
+		"(▲ lebih besar di tahun kedua, ▼ lebih kecil) dan total setahun di baris terakhir.\n\n" +
+		"Contoh:\n" +
+		"   /year_vs_year 2023 2024",
+
+	"random": "💡 /random\n\n" +
+		"Memberi satu tips keuangan acak untuk kategori dengan pengeluaran terbesar minggu ini. " +
+		"Jika belum ada tips untuk kategori itu, atau belum ada pengeluaran minggu ini, tips umum yang dipilih.",
+
+	"forecast": "🔮 /forecast [hari]\n\n" +
+		"Memproyeksikan total pengeluaran akhir bulan dari rata-rata harian sejak tanggal 1 bulan ini. " +
+		"Jika batas bulanan sudah diatur dengan /limit, proyeksi dibandingkan dengan batas tersebut.\n\n" +
+		"Isi [hari] untuk melihat juga proyeksi pengeluaran beberapa hari ke depan, contoh: /forecast 7.",
+
+	"cost_per_day": "☕ /cost_per_day <kategori> [hari]\n\n" +
+		"Menampilkan rata-rata pengeluaran harian satu kategori selama 30 hari terakhir, " +
+		"atau sebanyak [hari] jika diisi, contoh: /cost_per_day Kopi 60.\n\n" +
+		"Rata-rata dihitung dari total dibagi jumlah hari, termasuk hari tanpa pengeluaran. " +
+		"Jumlah hari yang benar-benar ada pengeluarannya ikut ditampilkan.",
+
+	"leaderboard": "🏆 /leaderboard\n\n" +
+		"Khusus grup: menampilkan anggota yang mencatat pengeluaran di grup bulan ini, " +
+		"diurutkan dari total terbesar, lengkap dengan jumlah transaksinya.\n\n" +
+		"Entri yang dicatat sebelum pengirimnya disimpan ditampilkan sebagai (tanpa nama).",
+
+	"chart": "🥧 /chart pie\n\n" +
+		"Mengirim gambar diagram lingkaran pengeluaran bulan ini per kategori, lengkap dengan persentasenya.\n\n" +
+		"Gambar yang sama dipakai ulang selama 5 menit, jadi entri baru baru terlihat setelahnya.\n\n" +
+		"📊 /chart bar monthly [bulan]\n\n" +
+		"Menampilkan total pengeluaran tiap bulan sebagai grafik batang, 12 bulan terakhir atau sebanyak bulan yang disebutkan (maksimal 12).\n\n" +
+		"Contoh:\n" +
+		"   /chart bar monthly 6",
+
+	"share": "📤 /share\n\n" +
+		"Membuat gambar ringkasan pengeluaran bulan ini (total dan 5 kategori terbesar) yang bisa dibagikan ke media sosial.\n\n" +
+		"Gambar tidak memuat nama, keterangan, atau data pribadi lain selain nama kategori.",
+
+	"last": "🕘 /last [jumlah]\n\n" +
+		"Menampilkan entri terakhir yang dicatat, termasuk nomornya untuk dipakai di /edit.\n\n" +
+		"Dengan jumlah (1 sampai 50), menampilkan sebanyak itu transaksi terakhir seperti /history, " +
+		"dikirim per 10 transaksi agar pesannya tidak terlalu panjang.\n\n" +
+		"Contoh:\n" +
+		"   /last 20",
+
+	"history": "🧾 /history\n\n" +
+		"Menampilkan 5 transaksi terakhir. Entri yang di-pin ditandai dengan 📌. " +
+		"Gunakan /last <jumlah> untuk menampilkan lebih banyak.",
+
+	"log": "📜 /log\n\n" +
+		"Menampilkan 10 perubahan terakhir yang dilakukan bot pada datamu: entri yang ditambah, diedit, " +
+		"atau dihapus beserta isi sebelum dan sesudahnya, serta perubahan pengaturan.",
+
+	"pin": "📌 /pin <nomor> [label]\n\n" +
+		"Menandai entri penting, misalnya pembayaran besar atau rutin, agar mudah dilihat lagi.\n\n" +
+		"Contoh:\n" +
+		"   /pin 7 Bayar kos\n" +
+		"   /pins - Tampilkan semua entri yang di-pin\n" +
+		"   /unpin 7 - Hapus pin entri #7",
+
+	"link": "🔗 /link <nomor>\n\n" +
+		"Menautkan pesan, misalnya tangkapan layar, invoice, atau pesan yang diteruskan, ke sebuah entri. " +
+		"Teruskan pesannya ke bot, lalu balas pesan itu dengan /link <nomor>. " +
+		"Pengirim asli pesan dan fotonya, jika ada, ikut disimpan.\n\n" +
+		"Contoh:\n" +
+		"   /link 7\n" +
+		"   /view 7 - Tampilkan entri #7 beserta pesan tertautnya",
+
+	"edit": "✏️ /edit [nomor]\n\n" +
+		"Mengubah entri berdasarkan nomornya. Nomor entri bisa dilihat di /last. " +
+		"Tanpa nomor, bot menampilkan 10 entri terakhir untuk dipilih.\n\n" +
+		"Contoh:\n" +
+		"   /edit 7\n" +
+		"Lalu kirim data baru dalam format: Nominal, Kategori, Keterangan\n" +
+		"   15rb, Makanan, Makan Malam\n\n" +
+		"Catatan:\n" +
+		"• Tanggal entri ikut diperbarui menjadi hari ini.\n" +
+		"• Jika format data baru salah, bot akan meminta ulang sampai formatnya benar.\n" +
+		"• Kirim /cancel untuk membatalkan, edit juga batal sendiri setelah 10 menit.\n" +
+		"• Kamu juga bisa langsung mengedit pesan pengeluaran di Telegram, entrinya akan ikut diperbarui.",
+
+	"remove": "🗑 /remove\n\n" +
+		"Menghapus entri terakhir dan menampilkan data yang dihapus. " +
+		"Hanya entri paling akhir yang bisa dihapus dengan perintah ini.",
+
+	"undo": "↩️ /undo\n\n" +
+		"Membatalkan aksi terakhir kamu: menambah, mengedit, atau menghapus entri. " +
+		"Bisa dipakai berulang kali untuk mundur hingga 20 aksi.\n\n" +
+		"Catatan: riwayat aksi hilang saat bot dimulai ulang.",
+
+	"merge": "🔀 /merge <kategori_lama> <kategori_baru>\n\n" +
+		"Mengubah semua entri dengan kategori lama menjadi kategori baru.\n\n" +
+		"Contoh:\n" +
+		"   /merge Kopi Coffee\n\n" +
+		"Catatan:\n" +
+		"• Nama kategori tidak membedakan huruf besar/kecil.\n" +
+		"• Bot akan menampilkan jumlah entri yang terdampak dan meminta konfirmasi terlebih dahulu.\n" +
+		"• Nama kategori harus satu kata.",
+
+	"clear": "🗑 /clear category <kategori>\n\n" +
+		"Menghapus semua entri dengan kategori tertentu, dari seluruh waktu. Bot menampilkan jumlah dan contoh entri " +
+		"lalu meminta konfirmasi sebelum menghapus.\n\n" +
+		"Contoh:\n" +
+		"   /clear category Hiburan\n\n" +
+		"Penghapusan ini tidak bisa dibatalkan dengan /undo.",
+
+	"checkin": "📍 /checkin\n\n" +
+		"Meminta lokasimu lalu menyimpannya di kolom Lokasi pada entri terakhir yang kamu catat.\n\n" +
+		"Lokasi yang dikirim dalam 5 detik setelah mencatat pengeluaran langsung disimpan untuk entri itu, tanpa /checkin.",
+
+	"copy_month": "📋 /copy_month <tahun>-<bulan>\n\n" +
+		"Menyalin semua entri kamu dari bulan tertentu (bulan lalu jika tidak disebutkan) ke bulan ini, pada tanggal yang sama " +
+		"(atau tanggal terakhir jika bulan ini lebih pendek). Cocok untuk pengeluaran tetap seperti sewa dan langganan.\n\n" +
+		"Salinan belum dicatat dan belum dihitung di total. Konfirmasi atau hapus masing-masing lewat tombol, " +
+		"atau lihat lagi dengan /pending.\n\n" +
+		"Contoh:\n" +
+		"   /copy_month 2024-06",
+
+	"template": "📋 /template\n\n" +
+		"Menyimpan pengeluaran yang sering dicatat agar bisa dicatat ulang dengan satu perintah.\n\n" +
+		"Contoh:\n" +
+		"   /template add kos 700rb, Rumah, Bayar kos - Simpan template bernama kos\n" +
+		"   /template use kos - Catat pengeluaran dari template untuk hari ini\n" +
+		"   /template list - Lihat semua template\n" +
+		"   /template delete kos - Hapus template\n\n" +
+		"Menyimpan template dengan nama yang sama akan menggantinya.",
+
+	"delete": "🗑 /delete range <nomor_awal> <nomor_akhir>\n\n" +
+		"Menghapus semua entri dari nomor awal sampai nomor akhir, misalnya untuk membersihkan data uji coba. " +
+		"Bot menampilkan entri yang akan dihapus dan meminta konfirmasi terlebih dahulu.\n\n" +
+		"Contoh:\n" +
+		"   /delete range 10 15\n\n" +
+		"Catatan:\n" +
+		"• Nomor entri bisa dilihat di /last.\n" +
+		"• Penghapusan tidak bisa dibatalkan dengan /undo.",
+
+	"alias": "🔤 /alias <singkatan> <kategori>\n\n" +
+		"Membuat singkatan untuk kategori, sehingga \"15rb, mkn, Nasi Goreng\" dicatat dengan kategori Makanan.\n\n" +
+		"Contoh:\n" +
+		"   /alias mkn Makanan\n" +
+		"   /alias list - Tampilkan semua alias\n" +
+		"   /alias delete mkn - Hapus alias\n\n" +
+		"Singkatan tidak membedakan huruf besar/kecil.",
+
+	"convert": "💱 /convert <nominal> <dari> <ke>\n\n" +
+		"Mengonversi mata uang dengan kurs terbaru. Kode mata uang mengikuti standar tiga huruf, contoh USD, SGD, EUR, IDR.\n\n" +
+		"Contoh:\n" +
+		"   /convert 100 USD IDR\n" +
+		"   /convert 1.500.000 IDR SGD\n\n" +
+		"Jika hasilnya dalam Rupiah, tekan tombol \"Catat sebagai pengeluaran?\" lalu kirim kategori dan keterangannya " +
+		"untuk langsung mencatatnya.",
+
+	"installment": "💳 /installment <nominal> <bulan> <kategori> <keterangan>\n\n" +
+		"Membagi pembelian besar menjadi cicilan bulanan. Cicilan pertama dicatat hari ini, " +
+		"sisanya dicatat otomatis pada tanggal yang sama setiap bulan.\n\n" +
+		"Contoh:\n" +
+		"   /installment 3,6jt 12 Elektronik HP baru\n" +
+		"   /installment status - Tampilkan sisa cicilan\n\n" +
+		"Catatan: kategori harus satu kata, sisa pembagian ditambahkan ke cicilan pertama.",
+
+	"tax": "🧾 /tax\n\n" +
+		"Menghitung estimasi pajak bulan ini dari pengeluaran kategori yang kamu tandai kena pajak.\n\n" +
+		"Atur tarif per kategori dengan /tax config <kategori>=<tarif>.\n\n" +
+		"Contoh:\n" +
+		"   /tax config Jasa=11%\n" +
+		"   /tax config Jasa=0 - Hapus tarif kategori Jasa\n" +
+		"   /tax - Lihat estimasi pajak bulan ini",
+
+	"save": "🐷 /save <nominal> [catatan]\n\n" +
+		"Mencatat uang yang berhasil kamu tabung.\n\n" +
+		"Contoh:\n" +
+		"   /save 500rb Dana darurat\n" +
+		"   /save status\n\n" +
+		"/save status menampilkan total tabungan, total pengeluaran, dan tingkat tabungan bulan ini, " +
+		"dihitung dari tabungan / (tabungan + pengeluaran).",
+
+	"backup": "💾 /backup\n\n" +
+		"Menyalin seluruh isi spreadsheet ke spreadsheet cadangan (BACKUP_SPREADSHEET_ID). " +
+		"Backup juga berjalan otomatis setiap tengah malam. " +
+		"Isi spreadsheet cadangan sebelumnya akan ditimpa.",
+
+	"migrate": "📦 /migrate <ID atau link spreadsheet>\n\n" +
+		"Menyalin semua entri kamu dari spreadsheet bersama ke spreadsheet milikmu sendiri, " +
+		"di bawah isi tab pertamanya. Bagikan spreadsheet itu ke email service account bot sebagai editor terlebih dahulu.\n\n" +
+		"Setelah selesai, bot menanyakan apakah entri yang sudah disalin dihapus dari spreadsheet bersama. " +
+		"Entri baru tetap dicatat di spreadsheet bersama.",
+
+	"whoami": "🪪 /whoami\n\n" +
+		"Menampilkan chat ID, nama, zona waktu, pengingat, dan batas bulanan kamu, " +
+		"beserta jumlah entri bulan ini, jumlah entri sepanjang waktu, dan tanggal entri pertama.",
+
+	"reset": "🗑 /reset\n\n" +
+		"Menghapus semua data kamu: entri yang kamu catat, tabungan, pin, alias, dan pengaturan. " +
+		"Bot akan mengirim kode 6 digit yang harus kamu ketik ulang dalam 2 menit untuk mengonfirmasi.\n\n" +
+		"Catatan: entri yang dicatat sebelum bot menyimpan kolom Pesan tidak bisa dikenali sebagai milikmu dan tidak ikut terhapus.",
+
+	"lang": "🌐 /lang <id|en>\n\n" +
+		"Mengganti bahasa balasan bot: id untuk Bahasa Indonesia, en untuk English.\n\n" +
+		"Catatan: belum semua balasan tersedia dalam bahasa Inggris.",
+
+	"reminder": "🔔 /reminder\n\n" +
+		"Mengubah seberapa sering bot mengingatkan kamu:\n" +
+		"• Harian: ringkasan pengeluaran hari ini, setiap hari pukul 20:00.\n" +
+		"• Mingguan: pengeluaran minggu ini, setiap Minggu pukul 20:00.\n" +
+		"• Bulanan: pengeluaran dan tabungan bulan ini, di hari terakhir setiap bulan pukul 20:00.\n\n" +
+		"Jam pengingat mengikuti zona waktu yang kamu pilih saat /start.\n\n" +
+		"Jadwal diperiksa setiap awal menit, jadi pengingat terkirim dalam satu menit setelah pukul 20:00. " +
+		"Jika bot sedang mati pada jam itu, pengingat saat itu dilewati dan tidak dikirim belakangan.\n\n" +
+		"Kirim /test_reminder untuk langsung menerima contoh pengingat sesuai pilihanmu.\n\n" +
+		"Sedang liburan? Jeda pengingat tanpa mengubah jenisnya:\n" +
+		"   /reminder pause 7 - Jeda pengingat selama 7 hari\n" +
+		"   /reminder resume - Lanjutkan pengingat lebih awal",
+
+	"digest": "☀️ /digest <jam>\n\n" +
+		"Mengirim ringkasan pagi setiap hari pada jam yang kamu pilih, berisi pengeluaran kemarin " +
+		"dibandingkan rata-rata 7 hari sebelumnya, kategori terbesar bulan ini, dan pemakaian batas bulanan.\n\n" +
+		"Contoh:\n" +
+		"   /digest 07:00\n" +
+		"   /digest - Lihat ringkasan sekarang\n" +
+		"   /digest off - Matikan ringkasan pagi\n\n" +
+		"Jam mengikuti zona waktu yang kamu pilih saat /start.",
+
+	"week_report": "📅 /week_report on|off\n\n" +
+		"Mengirim laporan pengeluaran minggu lalu (Minggu-Sabtu) per kategori setiap Senin pukul 08:00, " +
+		"mengikuti zona waktu yang kamu pilih saat /start.\n\n" +
+		"Ketik /week_report tanpa argumen untuk melihat apakah laporan sedang aktif.",
+
+	"goal": "🎯 /goal <kategori> <nominal>\n\n" +
+		"Mengatur target pengeluaran bulanan per kategori. Lihat kemajuannya dengan /monthly goals; " +
+		"di hari terakhir setiap bulan pukul 23:00 bot juga mengirim laporannya.\n\n" +
+		"✅ di bawah 90% target, ⚠️ 90-100% target, ❌ melewati target.\n\n" +
+		"Contoh:\n" +
+		"   /goal Makanan 1,5jt\n" +
+		"   /goal Makanan 0 - Hapus target\n" +
+		"   /monthly goals - Lihat kemajuan bulan ini",
+
+	"limit": "🎯 /limit monthly <nominal>\n\n" +
+		"Mengatur batas pengeluaran bulanan. Bot akan memberi peringatan saat pengeluaran bulan ini " +
+		"mencapai 75%, 90%, dan 100% dari batas, masing-masing sekali per bulan.\n\n" +
+		"Contoh:\n" +
+		"   /limit monthly 3jt\n" +
+		"   /limit - Lihat batas saat ini\n" +
+		"   /limit monthly 0 - Hapus batas\n\n" +
+		"/monthly target <nominal> melakukan hal yang sama, contoh: /monthly target 3jt. " +
+		"Target per kategori diatur terpisah dengan /goal.",
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxHistory is how many operations /undo can go back per chat.
+const maxHistory = 20
+
+// HistoryEntry is a change to the expense tab that /undo can revert.
+type HistoryEntry struct {
+	Action   string        // shown to the user, e.g. "tambah"
+	Row      int           // sheet row that was changed
+	Sheet    string        // tab of Row, "" for the main tab, see accountSheet
+	Previous []interface{} // row content before the change, nil if the row was empty
+}
+
+func (b *Bot) pushHistory(chatID int64, entry HistoryEntry) {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	history := append(b.operationHistory[chatID], entry)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	b.operationHistory[chatID] = history
+}
+
+func (b *Bot) popHistory(chatID int64) (HistoryEntry, bool) {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	history := b.operationHistory[chatID]
+	if len(history) == 0 {
+		return HistoryEntry{}, false
+	}
+	entry := history[len(history)-1]
+	b.operationHistory[chatID] = history[:len(history)-1]
+	return entry, true
+}
+
+// rowSnapshot returns the content of a row of the expense tab.
+func (b *Bot) rowSnapshot(ctx context.Context, rowNumber int) ([]interface{}, error) {
+	rows, err := storeWithContext(ctx, b.store).Get(b.expenseRange(b.sheetConfig.rowRange(rowNumber)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get row %d: %w", rowNumber, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows[0], nil
+}
+
+// undo reverts the last change chatID made and returns it.
+func (b *Bot) undo(ctx context.Context, chatID int64) (HistoryEntry, bool, error) {
+	store := storeWithContext(ctx, b.store)
+	entry, ok := b.popHistory(chatID)
+	if !ok {
+		return entry, false, nil
+	}
+
+	var err error
+	if entry.Previous == nil {
+		err = store.Clear(b.tabRange(entry.Sheet, b.sheetConfig.rowRange(entry.Row)))
+	} else {
+		err = store.Update(b.tabRange(entry.Sheet, fmt.Sprintf("A%d", entry.Row)), [][]interface{}{entry.Previous})
+	}
+	if err != nil {
+		// Keep the entry so the user can try again.
+		b.pushHistory(chatID, entry)
+		return entry, true, fmt.Errorf("failed to undo %s of row %d: %w", entry.Action, entry.Row, err)
+	}
+	return entry, true, nil
+}
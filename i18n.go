@@ -0,0 +1,68 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// defaultLanguage is used for users who have not picked a language.
+const defaultLanguage = "id"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// Messages holds the bot replies of one language, loaded from
+// locales/<language>.json. Replies not listed here are still Indonesian only.
+type Messages struct {
+	StartIntro      string `json:"start_intro"`
+	Help            string `json:"help"`
+	DataAdded       string `json:"data_added"` // date, nominal, category, description, month total
+	DateToday       string `json:"date_today"`
+	AddFailed       string `json:"add_failed"`
+	FormatError     string `json:"format_error"`
+	EditFormatError string `json:"edit_format_error"`
+	InvalidDate     string `json:"invalid_date"` // date
+	FutureDate      string `json:"future_date"`  // date
+	CommandUnknown  string `json:"command_unknown"`
+	NeedStart       string `json:"need_start"`
+	LanguageChanged string `json:"language_changed"`
+	LanguageUsage   string `json:"language_usage"`
+}
+
+// loadMessages reads every file under locales/, keyed by language.
+func loadMessages() (map[string]Messages, error) {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locales: %w", err)
+	}
+
+	messages := make(map[string]Messages, len(files))
+	for _, file := range files {
+		data, err := localeFiles.ReadFile("locales/" + file.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale %s: %w", file.Name(), err)
+		}
+
+		var m Messages
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse locale %s: %w", file.Name(), err)
+		}
+		messages[strings.TrimSuffix(file.Name(), ".json")] = m
+	}
+
+	if _, ok := messages[defaultLanguage]; !ok {
+		return nil, fmt.Errorf("missing default locale %s.json", defaultLanguage)
+	}
+	return messages, nil
+}
+
+// msg returns the messages in the language chatID picked.
+func (b *Bot) msg(chatID int64) Messages {
+	pref, _ := b.getPreference(chatID)
+	if m, ok := b.messages[pref.Language]; ok {
+		return m
+	}
+	return b.messages[defaultLanguage]
+}
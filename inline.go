@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// inlineResultLimit is how many entries an inline query answers with.
+const inlineResultLimit = 5
+
+// inlineCacheSeconds is how long Telegram may reuse the answer to an inline
+// query. It is short so that new entries show up quickly.
+const inlineCacheSeconds = 10
+
+// searchEntries returns the entries recorded in the private chat of userID
+// whose category or description contains term, ignoring case, newest first
+// and at most limit. An empty term matches every entry.
+func (b *Bot) searchEntries(ctx context.Context, userID int64, term string, limit int) ([]Row, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatInt(userID, 10)
+	term = strings.ToLower(strings.TrimSpace(term))
+	var matches []Row
+	for i := len(rows) - 1; i >= 0 && len(matches) < limit; i-- {
+		row := rows[i]
+		if refChatID(row.Ref) != id {
+			continue
+		}
+		if strings.Contains(strings.ToLower(row.Category), term) || strings.Contains(strings.ToLower(row.Description), term) {
+			matches = append(matches, row)
+		}
+	}
+	return matches, nil
+}
+
+// handleInlineQuery answers "@bot <term>" typed in any chat with the user's
+// own entries matching term. The answer is personal, so one user's entries
+// are never shown to another.
+func (b *Bot) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) {
+	if !b.inlineQueries {
+		return
+	}
+
+	rows, err := b.searchEntries(ctx, query.From.ID, query.Query, inlineResultLimit)
+	if err != nil {
+		log.Printf("failed to search entries of %d: %v", query.From.ID, err)
+		return
+	}
+
+	results := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		title := fmt.Sprintf("Rp %s - %s", formatRupiah(row.Nominal), row.Category)
+		article := tgbotapi.NewInlineQueryResultArticle(strconv.Itoa(row.RowNum), title, row.format())
+		article.Description = fmt.Sprintf("%s | %s", row.Date.Format("02-01-2006"), row.Description)
+		results = append(results, article)
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheSeconds,
+		IsPersonal:    true,
+	}
+	if _, err := b.api.Request(answer); err != nil {
+		log.Printf("failed to answer inline query of %d: %v", query.From.ID, err)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const recurringSheet = "Recurring"
+
+var recurringHeader = []interface{}{"ChatID", "JatuhTempo", "Nominal", "Kategori", "Keterangan", "Cicilan", "Status"}
+
+// installmentRecorded marks an installment already copied to the expenses.
+const installmentRecorded = "Tercatat"
+
+// addMonths adds months to date, keeping the day of month where it exists
+// and using the last day of shorter months otherwise.
+func addMonths(date time.Time, months int) time.Time {
+	first := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), lastDay)-1)
+}
+
+// createInstallmentPlan splits nominal over months monthly installments in
+// the Recurring tab, the first one due today. The remainder of the division
+// goes to the first installment.
+func (b *Bot) createInstallmentPlan(ctx context.Context, chatID int64, nominal, months int, category, description string) error {
+	if err := b.ensureTab(ctx, recurringSheet, recurringHeader); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	each := nominal / months
+
+	id := strconv.FormatInt(chatID, 10)
+	description, err := b.sealDescription(id, description)
+	if err != nil {
+		return err
+	}
+
+	values := make([][]interface{}, 0, months)
+	for i := 0; i < months; i++ {
+		amount := each
+		if i == 0 {
+			amount += nominal - each*months
+		}
+		values = append(values, []interface{}{
+			id, addMonths(today, i).Format("02-01-2006"), amount, normalizeCategory(category), description,
+			fmt.Sprintf("%d/%d", i+1, months), "",
+		})
+	}
+
+	if err := storeWithContext(ctx, b.store).Append(sheetRange(recurringSheet, "A1"), values); err != nil {
+		return fmt.Errorf("failed to write installments: %w", err)
+	}
+	return nil
+}
+
+// postDueInstallments records every installment due by now as an expense.
+func (b *Bot) postDueInstallments(ctx context.Context, now time.Time) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, recurringSheet, recurringHeader); err != nil {
+		return err
+	}
+
+	rows, err := store.Get(sheetRange(recurringSheet, "A:G"))
+	if err != nil {
+		return fmt.Errorf("failed to get installments: %w", err)
+	}
+
+	for i, row := range rows {
+		if i == 0 || len(row) < 6 { // Skip header
+			continue
+		}
+		if len(row) > 6 && fmt.Sprintf("%v", row[6]) == installmentRecorded {
+			continue
+		}
+
+		due, err := time.ParseInLocation("02-01-2006", fmt.Sprintf("%v", row[1]), time.Local)
+		if err != nil || due.After(now) {
+			continue
+		}
+
+		nominal, _ := strconv.Atoi(fmt.Sprintf("%v", row[2]))
+		description := fmt.Sprintf("%s (cicilan %v)", b.openDescription(fmt.Sprintf("%v", row[0]), row[4]), row[5])
+		ref := fmt.Sprintf("%v:cicilan", row[0])
+		if _, err := b.appendData(ctx, nominal, fmt.Sprintf("%v", row[3]), description, due, ref, ""); err != nil {
+			return err
+		}
+		if err := store.Update(sheetRange(recurringSheet, fmt.Sprintf("G%d", i+1)), [][]interface{}{{installmentRecorded}}); err != nil {
+			return fmt.Errorf("failed to mark installment as recorded: %w", err)
+		}
+	}
+	return nil
+}
+
+// getInstallmentStatus lists the installments of chatID not recorded yet.
+func (b *Bot) getInstallmentStatus(ctx context.Context, chatID int64) (string, error) {
+	if err := b.ensureTab(ctx, recurringSheet, recurringHeader); err != nil {
+		return "", err
+	}
+
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(recurringSheet, "A:G"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get installments: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	total := 0
+	var entries []string
+	for i, row := range rows {
+		if i == 0 || len(row) < 6 || fmt.Sprintf("%v", row[0]) != id { // Skip header
+			continue
+		}
+		if len(row) > 6 && fmt.Sprintf("%v", row[6]) == installmentRecorded {
+			continue
+		}
+
+		nominal, _ := strconv.Atoi(fmt.Sprintf("%v", row[2]))
+		total += nominal
+		entries = append(entries, fmt.Sprintf("📅%v - Rp %s | 🎯%v | 📚%v (%v)", row[1], formatRupiah(nominal), row[3], b.openDescription(id, row[4]), row[5]))
+	}
+
+	if len(entries) == 0 {
+		return "💳 Tidak ada cicilan yang tersisa", nil
+	}
+	return fmt.Sprintf("💳 Sisa Cicilan (%d kali, Rp %s):\n\n%s", len(entries), formatRupiah(total), strings.Join(entries, "\n")), nil
+}
+
+func (b *Bot) runInstallmentJob(ctx context.Context, now time.Time) {
+	if err := b.postDueInstallments(ctx, now); err != nil {
+		log.Printf("failed to record due installments: %v", err)
+	}
+}
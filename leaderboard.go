@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unknownSender labels the entries recorded before the sender was kept.
+const unknownSender = "(tanpa nama)"
+
+// SenderTotal is the spending one member of a group chat recorded.
+type SenderTotal struct {
+	Sender  string
+	Total   int
+	Entries int
+}
+
+// getLeaderboard sums this month's entries recorded in chatID by sender,
+// biggest spender first.
+func (b *Bot) getLeaderboard(ctx context.Context, chatID int64) ([]SenderTotal, error) {
+	rows, err := b.getEntriesBetween(ctx, monthStart(), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	totals := make(map[string]*SenderTotal)
+	var senders []*SenderTotal
+	for _, row := range rows {
+		if refChatID(row.Ref) != id {
+			continue
+		}
+		sender := row.Sender
+		if sender == "" {
+			sender = unknownSender
+		}
+		total, ok := totals[sender]
+		if !ok {
+			total = &SenderTotal{Sender: sender}
+			totals[sender] = total
+			senders = append(senders, total)
+		}
+		total.Total += row.Nominal
+		total.Entries++
+	}
+
+	sort.SliceStable(senders, func(i, j int) bool {
+		return senders[i].Total > senders[j].Total
+	})
+	leaderboard := make([]SenderTotal, len(senders))
+	for i, sender := range senders {
+		leaderboard[i] = *sender
+	}
+	return leaderboard, nil
+}
+
+// formatLeaderboard lists the spenders of this month, one line each.
+func formatLeaderboard(leaderboard []SenderTotal) string {
+	if len(leaderboard) == 0 {
+		return "Belum ada pengeluaran di grup ini bulan ini"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("🏆 Pengeluaran terbanyak %s:\n\n", time.Now().Format("01-2006")))
+	for i, sender := range leaderboard {
+		result.WriteString(fmt.Sprintf("%d. %s - Rp %s (%d transaksi)\n", i+1, sender.Sender, formatRupiah(sender.Total), sender.Entries))
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
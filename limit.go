@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Percentages of the monthly limit at which the user is warned.
+const (
+	limitThresholdNotice  = 75
+	limitThresholdWarning = 90
+	limitThresholdReached = 100
+)
+
+// limitThresholds is ordered from the highest threshold.
+var limitThresholds = []int{limitThresholdReached, limitThresholdWarning, limitThresholdNotice}
+
+// setMonthlyLimit sets the monthly spending limit of chatID, who must have
+// preferences already. A limit of 0 removes it.
+func (b *Bot) setMonthlyLimit(ctx context.Context, chatID int64, limit int) error {
+	pref, ok := b.getPreference(chatID)
+	if !ok {
+		return fmt.Errorf("no preferences for %d", chatID)
+	}
+	pref.MonthlyLimit = limit
+	return b.saveUserPreference(ctx, pref)
+}
+
+// setWeeklyLimit sets the weekly spending target of chatID, who must have
+// preferences already. A limit of 0 removes it.
+func (b *Bot) setWeeklyLimit(ctx context.Context, chatID int64, limit int) error {
+	pref, ok := b.getPreference(chatID)
+	if !ok {
+		return fmt.Errorf("no preferences for %d", chatID)
+	}
+	pref.WeeklyLimit = limit
+	return b.saveUserPreference(ctx, pref)
+}
+
+// getWeekTotal sums the spending of the current Sunday to Saturday week.
+func (b *Bot) getWeekTotal(ctx context.Context) (int, error) {
+	weekStart, weekEnd := weekBounds(0)
+	rows, err := b.getEntriesBetween(ctx, weekStart, weekEnd.AddDate(0, 0, -1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get week total: %w", err)
+	}
+	total := 0
+	for _, row := range rows {
+		total += row.Nominal
+	}
+	return total, nil
+}
+
+// weeklyLimitWarning returns the warning for the highest threshold of limit
+// that the week's total crossed, going from before to after, or an empty
+// string when none was crossed.
+func weeklyLimitWarning(before, after, limit int) string {
+	for _, threshold := range []int{limitThresholdReached, limitThresholdNotice} {
+		if after*100 < threshold*limit || before*100 >= threshold*limit {
+			continue
+		}
+		if threshold >= limitThresholdReached {
+			return fmt.Sprintf("🚨 Pengeluaran minggu ini (Rp %s) sudah melewati target mingguan Rp %s!",
+				formatRupiah(after), formatRupiah(limit))
+		}
+		return fmt.Sprintf("⚠️ Pengeluaran minggu ini sudah mencapai %d%% dari target mingguan (Rp %s dari Rp %s)",
+			threshold, formatRupiah(after), formatRupiah(limit))
+	}
+	return ""
+}
+
+// checkWeeklyLimit returns the warning to add to the confirmation of expense
+// when it pushes this week's spending past a threshold of chatID's weekly
+// target, or an empty string.
+func (b *Bot) checkWeeklyLimit(ctx context.Context, chatID int64, expense newExpense) string {
+	pref, ok := b.getPreference(chatID)
+	if !ok || pref.WeeklyLimit <= 0 {
+		return ""
+	}
+
+	weekStart, weekEnd := weekBounds(0)
+	date := time.Date(expense.date.Year(), expense.date.Month(), expense.date.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(weekStart) || !date.Before(weekEnd) {
+		return ""
+	}
+
+	total, err := b.getWeekTotal(ctx)
+	if err != nil {
+		log.Printf("failed to check weekly limit of %d: %v", chatID, err)
+		return ""
+	}
+	return weeklyLimitWarning(total-expense.nominal, total, pref.WeeklyLimit)
+}
+
+// getWeeklyStatus shows this week's spending against chatID's weekly target.
+func (b *Bot) getWeeklyStatus(ctx context.Context, chatID int64) (string, error) {
+	pref, _ := b.getPreference(chatID)
+	if pref.WeeklyLimit <= 0 {
+		return "❌ Target mingguan belum diatur. Atur dulu dengan /weekly target <nominal>", nil
+	}
+
+	total, err := b.getWeekTotal(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	_, weekEnd := weekBounds(0)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysLeft := int(weekEnd.Sub(today).Hours() / 24)
+	percent := total * 100 / pref.WeeklyLimit
+	return fmt.Sprintf("Minggu ini: Rp %s / Rp %s [%s] %d%% (%d hari tersisa)",
+		formatRupiah(total), formatRupiah(pref.WeeklyLimit), progressBar(percent), percent, daysLeft), nil
+}
+
+// checkMonthlyLimit warns chatID when this month's spending has crossed a
+// threshold of their monthly limit. Each threshold is warned about once a
+// month.
+func (b *Bot) checkMonthlyLimit(ctx context.Context, chatID int64) {
+	pref, ok := b.getPreference(chatID)
+	if !ok || pref.MonthlyLimit <= 0 {
+		return
+	}
+
+	now := time.Now().In(pref.location())
+	total, err := b.getMonthlyTotal(ctx, now)
+	if err != nil {
+		log.Printf("failed to check monthly limit of %d: %v", chatID, err)
+		return
+	}
+
+	percent := total * 100 / pref.MonthlyLimit
+	crossed := 0
+	for _, threshold := range limitThresholds {
+		if percent >= threshold {
+			crossed = threshold
+			break
+		}
+	}
+	if crossed == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	if month := now.Format("2006-01"); month != b.limitWarningMonth {
+		b.limitWarnings = make(map[int64]int)
+		b.limitWarningMonth = month
+	}
+	warned := b.limitWarnings[chatID] >= crossed
+	if !warned {
+		b.limitWarnings[chatID] = crossed
+	}
+	b.mu.Unlock()
+	if warned {
+		return
+	}
+
+	var text string
+	if crossed >= limitThresholdReached {
+		text = fmt.Sprintf("🚨 Pengeluaran bulan ini (Rp %s) sudah melewati batas bulanan Rp %s!",
+			formatRupiah(total), formatRupiah(pref.MonthlyLimit))
+	} else {
+		text = fmt.Sprintf("⚠️ Pengeluaran bulan ini sudah mencapai %d%% dari batas bulanan (Rp %s dari Rp %s)",
+			crossed, formatRupiah(total), formatRupiah(pref.MonthlyLimit))
+	}
+	b.sendMessage(tgbotapi.NewMessage(chatID, text))
+}
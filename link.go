@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const linksSheet = "Links"
+
+var linksHeader = []interface{}{"ChatID", "RowNumber", "MessageID", "ForwardFrom", "PhotoFileID", "LinkedAt"}
+
+// Link is a Telegram message, like a forwarded invoice or screenshot,
+// attached to an entry with /link.
+type Link struct {
+	Row         int
+	MessageID   int
+	ForwardFrom string // who the message was forwarded from, empty when it was not forwarded
+	PhotoFileID string // largest size of the message's photo, empty when there is none
+	LinkedAt    string
+	sheetRow    int // row of the link in the Links tab
+}
+
+// newLink describes message as a link to the entry row.
+func newLink(row int, message *tgbotapi.Message) Link {
+	link := Link{Row: row, MessageID: message.MessageID, LinkedAt: time.Now().Format("02-01-2006")}
+	switch {
+	case message.ForwardFrom != nil:
+		link.ForwardFrom = strings.TrimSpace(message.ForwardFrom.FirstName + " " + message.ForwardFrom.LastName)
+		if message.ForwardFrom.UserName != "" {
+			link.ForwardFrom += " (@" + message.ForwardFrom.UserName + ")"
+		}
+	case message.ForwardFromChat != nil:
+		link.ForwardFrom = message.ForwardFromChat.Title
+	case message.ForwardSenderName != "":
+		link.ForwardFrom = message.ForwardSenderName
+	}
+	if photos := message.Photo; len(photos) > 0 {
+		link.PhotoFileID = photos[len(photos)-1].FileID // largest size last
+	}
+	return link
+}
+
+// getLink returns the message chatID linked to the entry row, if any.
+func (b *Bot) getLink(ctx context.Context, chatID int64, row int) (Link, bool, error) {
+	if err := b.ensureTab(ctx, linksSheet, linksHeader); err != nil {
+		return Link{}, false, err
+	}
+
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(linksSheet, "A:F"))
+	if err != nil {
+		return Link{}, false, fmt.Errorf("failed to get links: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	cell := func(values []interface{}, col int) string {
+		if col < len(values) {
+			return fmt.Sprintf("%v", values[col])
+		}
+		return ""
+	}
+	for i, values := range rows {
+		if i == 0 || len(values) < 3 || cell(values, 0) != id || cell(values, 1) != strconv.Itoa(row) { // Skip header
+			continue
+		}
+
+		messageID, err := strconv.Atoi(cell(values, 2))
+		if err != nil {
+			continue
+		}
+		return Link{
+			Row:         row,
+			MessageID:   messageID,
+			ForwardFrom: cell(values, 3),
+			PhotoFileID: cell(values, 4),
+			LinkedAt:    cell(values, 5),
+			sheetRow:    i + 1,
+		}, true, nil
+	}
+	return Link{}, false, nil
+}
+
+// linkMessage links a message to an entry for chatID, replacing the
+// message linked to it before.
+func (b *Bot) linkMessage(ctx context.Context, chatID int64, link Link) error {
+	store := storeWithContext(ctx, b.store)
+	existing, found, err := b.getLink(ctx, chatID, link.Row)
+	if err != nil {
+		return err
+	}
+
+	values := [][]interface{}{{strconv.FormatInt(chatID, 10), link.Row, link.MessageID, link.ForwardFrom, link.PhotoFileID, link.LinkedAt}}
+	if found {
+		err = store.Update(sheetRange(linksSheet, fmt.Sprintf("A%d", existing.sheetRow)), values)
+	} else {
+		err = store.Append(sheetRange(linksSheet, "A1"), values)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save link: %w", err)
+	}
+	return nil
+}
+
+// format describes the linked message under an entry in /view.
+func (l Link) format() string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("🔗 Pesan tertaut (ID %d, %s)\n", l.MessageID, l.LinkedAt))
+	if l.ForwardFrom != "" {
+		result.WriteString(fmt.Sprintf("↪️ Diteruskan dari: %s\n", l.ForwardFrom))
+	}
+	if l.PhotoFileID != "" {
+		result.WriteString("🖼 Berisi foto\n")
+	}
+	return strings.TrimRight(result.String(), "\n")
+}
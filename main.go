@@ -2,628 +2,418 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
-	"strconv"
-	"strings"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/joho/godotenv"
+	"golang.org/x/crypto/acme/autocert"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
 )
 
-var (
-	botToken          string
-	spreadsheetID     string
-	credentialsBase64 string
-	mode              string
-	editingState      = make(map[int64]int) // Map to store which entry user is editing
-)
+var config Config
 
-func init() {
-	if os.Getenv("RAILWAY_ENVIRONMENT") == "" {
-		err := godotenv.Load()
-		if err != nil {
-			log.Println("Skipping .env loading, not found.")
-		}
-	}
+// shutdownTimeout is how long the webhook server waits for requests in
+// progress when the bot shuts down.
+const shutdownTimeout = 10 * time.Second
 
-	botToken = os.Getenv("BOT_TOKEN")
-	spreadsheetID = os.Getenv("SPREADSHEET_ID")
-	credentialsBase64 = os.Getenv("GOOGLE_CREDENTIALS_BASE64")
-	mode = os.Getenv("MODE")
-	if mode == "" {
-		mode = "polling"
-	}
+// updateTimeout is how long an update may take to be handled, from when it
+// is received.
+const updateTimeout = 30 * time.Second
 
-	if botToken == "" || spreadsheetID == "" || credentialsBase64 == "" {
-		log.Fatal("One or more required environment variables are not set.")
-	}
-}
+// version is set at build time with -ldflags "-X main.version=v1.2.3". The
+// VERSION environment variable is used when it is not.
+var version string
 
-func main() {
-	bot, err := tgbotapi.NewBotAPI(botToken)
-	if err != nil {
-		log.Panicf("failed to create bot API client: %v", err)
+func buildVersion() string {
+	if version != "" {
+		return version
 	}
-	bot.Debug = true
-
-	ctx := context.Background()
-	srv, err := authorize(ctx)
-	if err != nil {
-		log.Fatalf("failed to authorize with Google Sheets: %v", err)
+	if v := os.Getenv("VERSION"); v != "" {
+		return v
 	}
+	return "dev"
+}
 
-	switch mode {
-	case "webhook":
-		runWebhook(bot, srv)
-	default:
-		runPolling(bot, srv)
-	}
+// logStartup logs a JSON banner identifying the running deployment.
+func logStartup(bot *Bot, cfg Config) {
+	slog.New(slog.NewJSONHandler(os.Stderr, nil)).Info("bot started",
+		"username", "@"+bot.api.Self.UserName,
+		"mode", cfg.Mode,
+		"version", buildVersion(),
+		"go", runtime.Version(),
+		"pid", os.Getpid(),
+	)
 }
 
-func runWebhook(bot *tgbotapi.BotAPI, srv *sheets.Service) {
-	webhookURL := os.Getenv("WEBHOOK_URL")
-	port := os.Getenv("PORT")
-	if webhookURL == "" || port == "" {
-		log.Fatal("WEBHOOK_URL or PORT not set")
+func main() {
+	if os.Getenv("RAILWAY_ENVIRONMENT") == "" {
+		err := godotenv.Load()
+		if err != nil {
+			log.Println("Skipping .env loading, not found.")
+		}
 	}
 
-	webhookConfig, err := tgbotapi.NewWebhook(webhookURL)
-	if err != nil {
-		log.Fatalf("Failed to create webhook config: %v", err)
-	}
-	_, err = bot.Request(webhookConfig)
+	// The config is loaded here rather than in init so that the tests of
+	// this package run without a BOT_TOKEN.
+	var err error
+	config, err = LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to set webhook: %v", err)
+		log.Fatal(err)
 	}
+	cfg := config
 
-	log.Printf("📡 Running in Webhook mode... Listening on %s", port)
+	// ctx is cancelled on SIGINT or SIGTERM, stopping the Sheets requests
+	// still running so that the bot can exit promptly.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	http.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
-		var update tgbotapi.Update
-		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
-			log.Printf("Error decoding update: %v", err)
-			return
+	var store SheetStore
+	usesSheets := cfg.Store == "sheets" || cfg.ExportSQLite
+	if usesSheets {
+		store = NewGoogleSheetStore(ctx, getSheetService, cfg.SpreadsheetID, cfg.SheetsTimeout())
+	} else {
+		store, err = NewSQLiteStore(cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("failed to open SQLite store: %v", err)
 		}
-		log.Printf("Received update: %+v", update)
-		handleUpdate(bot, srv, update)
-	})
-
-	log.Fatal(http.ListenAndServe(":"+port, nil))
-}
-
-func runPolling(bot *tgbotapi.BotAPI, srv *sheets.Service) {
-	log.Println("🔁 Running in Polling mode...")
-	bot.Request(tgbotapi.DeleteWebhookConfig{})
-
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = 60
-
-	updates := bot.GetUpdatesChan(updateConfig)
-	for update := range updates {
-		handleUpdate(bot, srv, update)
 	}
-}
 
-func handleUpdate(bot *tgbotapi.BotAPI, srv *sheets.Service, update tgbotapi.Update) {
-	if update.Message == nil {
+	if cfg.ExportSQLite {
+		rows, err := exportToSQLite(store, cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("failed to export to SQLite: %v", err)
+		}
+		log.Printf("Exported %d rows to %s", rows, cfg.SQLitePath)
 		return
 	}
 
-	chatId := update.Message.Chat.ID
-	text := update.Message.Text
-
-	// Check if user is in editing state
-	if editingRow, isEditing := editingState[chatId]; isEditing {
-		// User is in editing state, expect new data
-		parts := strings.Split(text, ",")
-		if len(parts) == 3 {
-			nominalStr := strings.TrimSpace(parts[0])
-			budget := strings.TrimSpace(parts[1])
-			keterangan := strings.TrimSpace(parts[2])
-
-			normalizedNominal := normalizeNominal(nominalStr)
-			err := editEntry(srv, editingRow, normalizedNominal, budget, keterangan)
-			if err != nil {
-				bot.Send(tgbotapi.NewMessage(chatId, "❌ Gagal mengedit data."))
-				delete(editingState, chatId)
-				return
-			}
-
-			// Show the edited entry
-			editedEntry, _ := getEntryByNumber(srv, editingRow)
-			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Data berhasil diedit:\n%s", editedEntry))
-			bot.Send(msg)
-			delete(editingState, chatId)
-			return
-		} else {
-			bot.Send(tgbotapi.NewMessage(chatId, "Format salah🙅🏻‍♂️. Gunakan: Nominal, Kategori, Keterangan\nContoh: 10rb, Makanan, Makan Siang di Kantin"))
-			return
+	if cfg.MigrateSheets {
+		applied, err := runMigrations(store)
+		if err != nil {
+			log.Fatalf("failed to migrate: %v", err)
 		}
+		log.Printf("Applied %d migrations", applied)
+		return
 	}
 
-	// Handle commands
-	if strings.HasPrefix(text, "/") {
-		switch {
-		case text == "/start":
-			msg := tgbotapi.NewMessage(chatId, "👋 Hai! Saya adalah bot pencatat keuangan.\n\n"+
-				"📝 Untuk mencatat pengeluaran, kirim dalam format:\n"+
-				"Nominal, Kategori, Keterangan\n"+
-				"Contoh: 10rb, Makanan, Makan Siang di Kantin\n\n"+
-				"📋 Perintah yang tersedia:\n"+
-				"/help - Tampilkan bantuan\n"+
-				"/summary - Tampilkan total pengeluaran\n"+
-				"/weekly - Tampilkan pengeluaran minggu ini\n"+
-				"/monthly - Tampilkan pengeluaran bulan ini\n"+
-				"/last - Tampilkan data terakhir\n"+
-				"/remove - Hapus entri terakhir\n"+
-				"/edit - Edit entri berdasarkan nomor\n"+
-				"/history - Tampilkan 5 transaksi terakhir")
-			bot.Send(msg)
-			return
-
-		case text == "/help":
-			msg := tgbotapi.NewMessage(chatId, "📋 Cara menggunakan bot:\n\n"+
-				"1. Untuk mencatat pengeluaran:\n"+
-				"   Kirim dalam format: Nominal, Kategori, Keterangan\n"+
-				"   Contoh: 10rb, Makanan, Makan Siang di Kantin\n\n"+
-				"2. Perintah yang tersedia:\n"+
-				"   /start - Mulai bot\n"+
-				"   /help - Tampilkan bantuan ini\n"+
-				"   /summary - Tampilkan total pengeluaran\n"+
-				"   /weekly - Tampilkan pengeluaran minggu ini\n"+
-				"   /monthly - Tampilkan pengeluaran bulan ini\n"+
-				"   /last - Tampilkan data terakhir\n"+
-				"   /remove - Hapus entri terakhir\n"+
-				"   /edit <nomor> - Edit entri berdasarkan nomor\n"+
-				"   /history - Tampilkan 5 transaksi terakhir\n\n"+
-				"3. Format nominal:\n"+
-				"   - 10rb = 10.000\n"+
-				"   - 1jt = 1.000.000\n"+
-				"   - 100k = 100.000")
-			bot.Send(msg)
-			return
-
-		case text == "/edit " + text:
-			// Extract row number from command
-			rowNumberStr := strings.TrimPrefix(text, "/edit ")
-			rowNumber, err := strconv.Atoi(rowNumberStr)
-			if err != nil {
-				bot.Send(tgbotapi.NewMessage(chatId, "❌ Nomor entri tidak valid. Gunakan format: /edit <nomor>"))
-				return
-			}
-
-			// Get the entry to show what will be edited
-			entry, err := getEntryByNumber(srv, rowNumber)
-			if err != nil {
-				bot.Send(tgbotapi.NewMessage(chatId, "❌ Entri tidak ditemukan"))
-				return
-			}
-
-			// Store the row number in editing state
-			editingState[chatId] = rowNumber
-
-			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✏️ Edit entri #%d:\n%s\n\nKirim data baru dalam format:\nNominal, Kategori, Keterangan\nContoh: 10rb, Makanan, Makan Siang di Kantin", rowNumber, entry))
-			bot.Send(msg)
-			return
-
-		case text == "/summary":
-			summary := getSummary(srv)
-			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("📊 Total pengeluaran saat ini: Rp. %d", summary))
-			bot.Send(msg)
-			return
-
-		case text == "/weekly":
-			weeklySummary, err := getWeeklySummary(srv)
-			if err != nil {
-				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran mingguan")
-				bot.Send(msg)
-				return
-			}
-			msg := tgbotapi.NewMessage(chatId, weeklySummary)
-			bot.Send(msg)
-			return
-
-		case text == "/monthly":
-			monthlySummary, err := getMonthlySummary(srv)
-			if err != nil {
-				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data pengeluaran bulanan")
-				bot.Send(msg)
-				return
-			}
-			msg := tgbotapi.NewMessage(chatId, monthlySummary)
-			bot.Send(msg)
-			return
-
-		case text == "/last":
-			lastEntry, err := getLastEntry(srv)
-			if err != nil {
-				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data terakhir")
-				bot.Send(msg)
-				return
-			}
-			msg := tgbotapi.NewMessage(chatId, lastEntry)
-			bot.Send(msg)
-			return
-
-		case text == "/remove":
-			lastEntry, err := getLastEntry(srv)
-			if err != nil {
-				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil data terakhir")
-				bot.Send(msg)
-				return
-			}
-
-			err = removeLastEntry(srv)
-			if err != nil {
-				msg := tgbotapi.NewMessage(chatId, "❌ Gagal menghapus data terakhir")
-				bot.Send(msg)
-				return
-			}
-
-			msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("✅ Data berhasil dihapus:\n%s", lastEntry))
-			bot.Send(msg)
-			return
+	if cfg.DryRun {
+		log.Println("🧪 Dry run: writes are logged but not saved")
+		store = dryRunStore{store}
+	}
 
-		case text == "/history":
-			history, err := getLastFiveEntries(srv)
-			if err != nil {
-				msg := tgbotapi.NewMessage(chatId, "❌ Gagal mengambil riwayat transaksi")
-				bot.Send(msg)
-				return
-			}
-			msg := tgbotapi.NewMessage(chatId, history)
-			bot.Send(msg)
-			return
+	// The spreadsheet is checked before anything reads it or the scheduler
+	// starts, so a wrong ID or a missing share stops the bot right away.
+	if usesSheets {
+		checkSpreadsheet(store)
+	}
 
-		default:
-			msg := tgbotapi.NewMessage(chatId, "❌ Perintah tidak dikenali. Gunakan /help untuk melihat daftar perintah yang tersedia")
-			bot.Send(msg)
-			return
-		}
+	// Pending migrations run before anything reads the store. The bot
+	// still starts when they fail and tries again on the next start.
+	if _, err := runMigrations(store); err != nil {
+		log.Printf("failed to run migrations: %v", err)
 	}
 
-	// Handle data input
-	parts := strings.Split(text, ",")
-	if len(parts) == 3 {
-		nominalStr := strings.TrimSpace(parts[0])
-		budget := strings.TrimSpace(parts[1])
-		keterangan := strings.TrimSpace(parts[2])
+	bot, err := NewBot(cfg.BotToken, store)
+	if err != nil {
+		log.Panicf("%v", err)
+	}
+	bot.api.Debug = true
+	logStartup(bot, cfg)
+	for _, id := range cfg.AdminChatIDs {
+		bot.adminChatIDs[id] = true
+	}
 
-		normalizedNominal := normalizeNominal(nominalStr)
-		err := appendData(srv, normalizedNominal, budget, keterangan)
+	// The key is loaded even when encryption is off so that descriptions
+	// encrypted earlier stay readable.
+	if cfg.EncryptionKey != "" {
+		bot.cipher, err = newDescriptionCipher(cfg.EncryptionKey)
 		if err != nil {
-			bot.Send(tgbotapi.NewMessage(chatId, "❌Terjadi kesalahan saat menambahkan data."))
-			return
+			log.Panicf("%v", err)
 		}
-
-		summary := getSummary(srv)
-		response := fmt.Sprintf(
-			"✅Data berhasil ditambahkan ke Google Spreadsheet.\nKamu telah memasukkan:\n💰%d\n🎯%s\n📚%s\n\nTotal Nominal: Rp. %d",
-			normalizedNominal, budget, keterangan, summary,
-		)
-		bot.Send(tgbotapi.NewMessage(chatId, response))
-	} else {
-		bot.Send(tgbotapi.NewMessage(chatId, "Format salah🙅🏻‍♂️. Gunakan: Nominal, Kategori, Keterangan. \nContoh: 10rb, Makanan, Makan Siang di Kantin\n\nGunakan /help untuk melihat bantuan lengkap"))
+		bot.encryptDescriptions = cfg.EncryptDescriptions
 	}
-}
 
-func authorize(ctx context.Context) (*sheets.Service, error) {
-	decodedCreds, err := base64.StdEncoding.DecodeString(credentialsBase64)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	if err := bot.loadNamedRanges(ctx); err != nil {
+		log.Printf("failed to load named ranges: %v", err)
 	}
 
-	config, err := google.JWTConfigFromJSON(decodedCreds, sheets.SpreadsheetsScope)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	if _, err := bot.loadUserPreferences(ctx); err != nil {
+		log.Printf("failed to load user preferences: %v", err)
 	}
 
-	client := config.Client(ctx)
-	return sheets.NewService(ctx, option.WithHTTPClient(client))
-}
-
-func appendData(srv *sheets.Service, nominal int, budget, keterangan string) error {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, "A:A").Do()
-	if err != nil {
-		return fmt.Errorf("failed to get row count: %w", err)
-	}
-	nextRow := 1
-	if resp != nil && resp.Values != nil {
-		nextRow = len(resp.Values) + 1
+	if err := bot.loadSheetConfig(ctx); err != nil {
+		log.Printf("failed to load sheet config: %v", err)
 	}
 
-	// Get current date in DD-MM-YYYY format
-	currentDate := time.Now().Format("02-01-2006")
-
-	values := [][]interface{}{{nextRow, currentDate, nominal, budget, keterangan}}
-	valueRange := &sheets.ValueRange{Values: values}
-
-	_, err = srv.Spreadsheets.Values.Append(spreadsheetID, "A1", valueRange).ValueInputOption("USER_ENTERED").Do()
-	return err
-}
-
-func normalizeNominal(nominal string) int {
-	nominal = strings.ToLower(strings.ReplaceAll(nominal, " ", ""))
-	nominal = strings.ReplaceAll(nominal, ".", "") // remove dot
-	var result int
-	var err error
-
-	switch {
-	case strings.Contains(nominal, "jt"):
-		nominal = strings.ReplaceAll(nominal, "jt", "")
-		result, err = strconv.Atoi(nominal)
-		result *= 1000000
-	case strings.Contains(nominal, "rb"):
-		nominal = strings.ReplaceAll(nominal, "rb", "")
-		result, err = strconv.Atoi(nominal)
-		result *= 1000
-	case strings.Contains(nominal, "k"):
-		nominal = strings.ReplaceAll(nominal, "k", "")
-		result, err = strconv.Atoi(nominal)
-		result *= 1000
-	default:
-		result, err = strconv.Atoi(nominal)
+	// A missing header stops the bot before entries are written to the
+	// wrong columns. A mismatched one is only reported, the columns may have
+	// been renamed on purpose.
+	if err := bot.validateSheetSchema(ctx); errors.Is(err, errHeaderMismatch) {
+		log.Printf("⚠️ %v", err)
+	} else if err != nil {
+		log.Fatalf("%v", err)
 	}
 
-	if err != nil {
-		log.Printf("Error converting nominal value: %v", err)
-		return 0
+	if cfg.BackupSpreadsheetID != "" && usesSheets {
+		bot.backupStore = NewGoogleSheetStore(ctx, getSheetService, cfg.BackupSpreadsheetID, cfg.SheetsTimeout())
+		checkSpreadsheet(bot.backupStore)
+		if cfg.DryRun {
+			bot.backupStore = dryRunStore{bot.backupStore}
+		}
 	}
-	return result
-}
 
-func getSummary(srv *sheets.Service) int {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, "C:C").Do()
-	if err != nil {
-		log.Printf("failed to get summary: %v", err)
-		return 0
-	}
-	total := 0
-	for _, row := range resp.Values {
-		if len(row) > 0 {
-			switch v := row[0].(type) {
-			case string:
-				if val, err := strconv.Atoi(v); err == nil {
-					total += val
-				}
-			case float64:
-				total += int(v)
+	if usesSheets {
+		bot.openSpreadsheet = func(ctx context.Context, spreadsheetID string) (SheetStore, error) {
+			var store SheetStore = NewGoogleSheetStore(ctx, getSheetService, spreadsheetID, cfg.SheetsTimeout())
+			if err := store.Ping(); err != nil {
+				return nil, err
+			}
+			if cfg.DryRun {
+				store = dryRunStore{store}
 			}
+			return store, nil
 		}
 	}
-	return total
-}
-
-func getLastEntry(srv *sheets.Service) (string, error) {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, "A:E").Do()
-	if err != nil {
-		return "", fmt.Errorf("failed to get last entry: %w", err)
-	}
 
-	if resp == nil || resp.Values == nil || len(resp.Values) < 2 {
-		return "Belum ada data yang dimasukkan", nil
+	// Receipts are not uploaded in a dry run, the entries are only logged
+	if usesSheets && cfg.ReceiptFolderID != "" && !cfg.DryRun {
+		bot.receipts = NewDriveReceiptStore(getDriveService, cfg.ReceiptFolderID)
 	}
 
-	lastRow := resp.Values[len(resp.Values)-1]
-	if len(lastRow) < 5 {
-		return "Format data tidak valid", nil
+	if cfg.OpenAIAPIKey != "" {
+		bot.transcriber = NewWhisperTranscriber(cfg.OpenAIAPIKey)
 	}
-
-	rowNum := fmt.Sprintf("%v", lastRow[0])
-	date := fmt.Sprintf("%v", lastRow[1])
-	nominal := fmt.Sprintf("%v", lastRow[2])
-	budget := fmt.Sprintf("%v", lastRow[3])
-	keterangan := fmt.Sprintf("%v", lastRow[4])
-
-	return fmt.Sprintf("🕘 Data terakhir: #%s - 📅%s - 💰%s | 🎯%s | 📚%s", rowNum, date, nominal, budget, keterangan), nil
-}
-
-func getWeeklySummary(srv *sheets.Service) (string, error) {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, "A:E").Do()
-	if err != nil {
-		return "", fmt.Errorf("failed to get weekly summary: %w", err)
+	bot.inlineQueries = cfg.InlineMode
+	if cfg.SendGridAPIKey != "" && cfg.ReportEmailFrom != "" {
+		bot.mailer = NewSendGridMailer(cfg.SendGridAPIKey, cfg.ReportEmailFrom)
 	}
 
-	if resp == nil || resp.Values == nil || len(resp.Values) < 2 {
-		return "Belum ada data yang dimasukkan", nil
+	if err := registerCommands(bot.api, cfg.Lang); err != nil {
+		log.Printf("failed to register bot commands: %v", err)
 	}
 
-	now := time.Now()
-	weekStart := now.AddDate(0, 0, -int(now.Weekday()))
-	weekEnd := weekStart.AddDate(0, 0, 6)
+	bot.startScheduler(ctx)
+	bot.startHealthCheck()
+	bot.startDeadLetterQueue()
 
-	total := 0
-	var entries []string
+	queue := NewMessageQueue(bot, cfg.WorkerCount)
 
-	for _, row := range resp.Values[1:] { // Skip header
-		if len(row) < 5 {
-			continue
-		}
-
-		dateStr := fmt.Sprintf("%v", row[1])
-		date, err := time.Parse("02-01-2006", dateStr)
-		if err != nil {
-			continue
-		}
-
-		if date.After(weekStart) && date.Before(weekEnd.AddDate(0, 0, 1)) {
-			nominal, _ := strconv.Atoi(fmt.Sprintf("%v", row[2]))
-			total += nominal
-			entries = append(entries, fmt.Sprintf("📅%s - 💰%v | 🎯%v | 📚%v", dateStr, row[2], row[3], row[4]))
-		}
-	}
-
-	if len(entries) == 0 {
-		return "Tidak ada pengeluaran minggu ini", nil
+	switch cfg.Mode {
+	case "webhook":
+		runWebhook(ctx, bot, queue, cfg)
+	default:
+		runPolling(ctx, bot, queue)
 	}
 
-	result := fmt.Sprintf("📊 Pengeluaran Minggu Ini (Rp. %d):\n\n", total)
-	for _, entry := range entries {
-		result += entry + "\n"
-	}
-	return result, nil
+	log.Println("🛑 Shutting down, waiting for queued updates...")
+	queue.Stop()
 }
 
-func getMonthlySummary(srv *sheets.Service) (string, error) {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, "A:E").Do()
+// runWebhook serves Telegram updates until ctx is cancelled.
+func runWebhook(ctx context.Context, bot *Bot, queue *MessageQueue, cfg Config) {
+	webhookConfig, err := tgbotapi.NewWebhook(cfg.WebhookURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to get monthly summary: %w", err)
+		log.Fatalf("Failed to create webhook config: %v", err)
 	}
-
-	if resp == nil || resp.Values == nil || len(resp.Values) < 2 {
-		return "Belum ada data yang dimasukkan", nil
+	_, err = bot.api.Request(webhookConfig)
+	if err != nil {
+		log.Fatalf("Failed to set webhook: %v", err)
 	}
 
-	now := time.Now()
-	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
-	monthEnd := monthStart.AddDate(0, 1, -1)
+	log.Printf("📡 Running in Webhook mode... Listening on %s", cfg.Port)
 
-	total := 0
-	var entries []string
+	http.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+		var update tgbotapi.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			log.Printf("Error decoding update: %v", err)
+			return
+		}
+		log.Printf("Received update: %+v", update)
 
-	for _, row := range resp.Values[1:] { // Skip header
-		if len(row) < 5 {
-			continue
+		// Telegram gets its answer once the update was handled, or when
+		// the request is cancelled, which cancels the update too.
+		updateCtx, cancel := context.WithTimeout(r.Context(), updateTimeout)
+		defer cancel()
+		select {
+		case <-queue.Push(updateCtx, update):
+		case <-updateCtx.Done():
 		}
+	})
+	http.HandleFunc("/validate-csv", handleValidateCSV)
+
+	// Requests are cancelled with ctx, so the updates in flight are too.
+	// ListenAndServe returns as soon as Shutdown starts, so runWebhook waits
+	// for Shutdown to return: no handler may push to the queue once it is
+	// stopped.
+	server := &http.Server{Addr: ":" + cfg.Port, BaseContext: func(net.Listener) context.Context { return ctx }}
+	shutdown := make(chan struct{})
+	defer func() { <-shutdown }()
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("failed to shut down webhook server: %v", err)
+		}
+	}()
 
-		dateStr := fmt.Sprintf("%v", row[1])
-		date, err := time.Parse("02-01-2006", dateStr)
+	if cfg.TLSEnabled {
+		tlsConfig, err := webhookTLSConfig(cfg)
 		if err != nil {
-			continue
+			log.Fatalf("failed to set up TLS: %v", err)
 		}
-
-		if date.After(monthStart.AddDate(0, 0, -1)) && date.Before(monthEnd.AddDate(0, 0, 1)) {
-			nominal, _ := strconv.Atoi(fmt.Sprintf("%v", row[2]))
-			total += nominal
-			entries = append(entries, fmt.Sprintf("📅%s - 💰%v | 🎯%v | 📚%v", dateStr, row[2], row[3], row[4]))
+		server.TLSConfig = tlsConfig
+		if err := server.ListenAndServeTLS("", ""); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
 		}
+		return
 	}
 
-	if len(entries) == 0 {
-		return "Tidak ada pengeluaran bulan ini", nil
-	}
-
-	result := fmt.Sprintf("📊 Pengeluaran Bulan Ini (Rp. %d):\n\n", total)
-	for _, entry := range entries {
-		result += entry + "\n"
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
 	}
-	return result, nil
 }
 
-func removeLastEntry(srv *sheets.Service) error {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, "A:A").Do()
+// webhookTLSConfig returns a TLS config that gets and renews a Let's Encrypt
+// certificate for the host of the webhook URL, caching it in cfg.CertDir.
+func webhookTLSConfig(cfg Config) (*tls.Config, error) {
+	webhookURL, err := url.Parse(cfg.WebhookURL)
 	if err != nil {
-		return fmt.Errorf("failed to get row count: %w", err)
+		return nil, fmt.Errorf("failed to parse webhook URL: %w", err)
 	}
-
-	if resp == nil || resp.Values == nil || len(resp.Values) < 2 {
-		return fmt.Errorf("no entries to remove")
+	domain := webhookURL.Hostname()
+	if domain == "" || net.ParseIP(domain) != nil {
+		return nil, fmt.Errorf("webhook URL %q has no domain name to get a certificate for", cfg.WebhookURL)
+	}
+	if err := os.MkdirAll(cfg.CertDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create certificate directory: %w", err)
 	}
 
-	lastRow := len(resp.Values)
-	rangeToClear := fmt.Sprintf("A%d:E%d", lastRow, lastRow)
-
-	// Create a clear request
-	clearRequest := &sheets.ClearValuesRequest{}
-	_, err = srv.Spreadsheets.Values.Clear(spreadsheetID, rangeToClear, clearRequest).Do()
-	return err
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domain),
+		Cache:      autocert.DirCache(cfg.CertDir),
+	}
+	return manager.TLSConfig(), nil
 }
 
-func editEntry(srv *sheets.Service, rowNumber int, nominal int, budget, keterangan string) error {
-	// Get current date in DD-MM-YYYY format
-	currentDate := time.Now().Format("02-01-2006")
-
-	// Prepare the range to update (A:E columns of the specified row)
-	rangeToUpdate := fmt.Sprintf("A%d:E%d", rowNumber, rowNumber)
-	values := [][]interface{}{{rowNumber, currentDate, nominal, budget, keterangan}}
-	valueRange := &sheets.ValueRange{Values: values}
+// runPolling polls Telegram for updates until ctx is cancelled.
+func runPolling(ctx context.Context, bot *Bot, queue *MessageQueue) {
+	log.Println("🔁 Running in Polling mode...")
+	bot.api.Request(tgbotapi.DeleteWebhookConfig{})
 
-	_, err := srv.Spreadsheets.Values.Update(spreadsheetID, rangeToUpdate, valueRange).ValueInputOption("USER_ENTERED").Do()
-	return err
-}
+	updateConfig := tgbotapi.NewUpdate(0)
+	updateConfig.Timeout = 60
 
-func getEntryByNumber(srv *sheets.Service, rowNumber int) (string, error) {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, fmt.Sprintf("A%d:E%d", rowNumber, rowNumber)).Do()
-	if err != nil {
-		return "", fmt.Errorf("failed to get entry: %w", err)
+	updates := bot.api.GetUpdatesChan(updateConfig)
+	for {
+		select {
+		case <-ctx.Done():
+			bot.api.StopReceivingUpdates()
+			return
+		case update := <-updates:
+			updateCtx, cancel := context.WithTimeout(ctx, updateTimeout)
+			done := queue.Push(updateCtx, update)
+			go func() {
+				<-done
+				cancel()
+			}()
+		}
 	}
+}
 
-	if resp == nil || resp.Values == nil || len(resp.Values) == 0 {
-		return "", fmt.Errorf("entry not found")
-	}
+// The Google clients are created on first use rather than at startup.
+// Creating them only reads the credentials, so an error is a configuration
+// problem and is kept. Network errors happen on the requests themselves and
+// are retried with the next one.
+var (
+	googleOnce    sync.Once
+	sheetService  *sheets.Service
+	driveService  *drive.Service
+	googleInitErr error
+)
 
-	row := resp.Values[0]
-	if len(row) < 5 {
-		return "", fmt.Errorf("invalid entry format")
+func initGoogleServices() {
+	ctx := context.Background()
+	scopes := []string{sheets.SpreadsheetsScope}
+	if config.ReceiptFolderID != "" {
+		scopes = append(scopes, drive.DriveScope)
 	}
-
-	date := fmt.Sprintf("%v", row[1])
-	nominal := fmt.Sprintf("%v", row[2])
-	budget := fmt.Sprintf("%v", row[3])
-	keterangan := fmt.Sprintf("%v", row[4])
-
-	return fmt.Sprintf("📅%s - 💰%s | 🎯%s | 📚%s", date, nominal, budget, keterangan), nil
-}
-
-func getLastFiveEntries(srv *sheets.Service) (string, error) {
-	resp, err := srv.Spreadsheets.Values.Get(spreadsheetID, "A:E").Do()
+	client, err := authorize(ctx, config.CredentialsBase64, scopes...)
 	if err != nil {
-		return "", fmt.Errorf("failed to get entries: %w", err)
-	}
-
-	if resp == nil || resp.Values == nil || len(resp.Values) < 2 {
-		return "Belum ada data yang dimasukkan", nil
+		googleInitErr = fmt.Errorf("failed to authorize with Google: %w", err)
+		return
 	}
-
-	// Get the last 5 entries (skip header row)
-	startIdx := len(resp.Values) - 5
-	if startIdx < 1 {
-		startIdx = 1
+	sheetService, err = sheets.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		googleInitErr = fmt.Errorf("failed to create Google Sheets client: %w", err)
+		return
 	}
-	entries := resp.Values[startIdx:]
-
-	var result strings.Builder
-	result.WriteString("🧾 5 Transaksi Terakhir:\n\n")
-
-	for i, row := range entries {
-		if len(row) < 5 {
-			continue
+	if config.ReceiptFolderID != "" {
+		driveService, err = drive.NewService(ctx, option.WithHTTPClient(client))
+		if err != nil {
+			googleInitErr = fmt.Errorf("failed to create Google Drive client: %w", err)
 		}
+	}
+}
 
-		nominal := fmt.Sprintf("%v", row[2])
-		budget := fmt.Sprintf("%v", row[3])
-		keterangan := fmt.Sprintf("%v", row[4])
-
-		// Format nominal with thousand separator
-		nominalInt, _ := strconv.Atoi(nominal)
-		formattedNominal := formatRupiah(nominalInt)
+// getSheetService returns the Google Sheets client, creating it on the
+// first call.
+func getSheetService() (*sheets.Service, error) {
+	googleOnce.Do(initGoogleServices)
+	return sheetService, googleInitErr
+}
 
-		result.WriteString(fmt.Sprintf("%d. Rp %s - %s - %s\n", i+1, formattedNominal, budget, keterangan))
+// getDriveService returns the Google Drive client, creating it on the first
+// call. It is only available when RECEIPT_FOLDER_ID is set.
+func getDriveService() (*drive.Service, error) {
+	googleOnce.Do(initGoogleServices)
+	if googleInitErr == nil && driveService == nil {
+		return nil, errors.New("google drive is not configured")
 	}
+	return driveService, googleInitErr
+}
 
-	return result.String(), nil
+// checkSpreadsheet stops the bot when the spreadsheet behind store cannot
+// be opened, so a wrong ID or missing share is reported at startup instead
+// of on the first message.
+func checkSpreadsheet(store SheetStore) {
+	if err := store.Ping(); err != nil {
+		log.Fatalf("%v", err)
+	}
 }
 
-func formatRupiah(nominal int) string {
-	str := strconv.Itoa(nominal)
-	var result strings.Builder
-	length := len(str)
+// authorize returns an HTTP client for the Google APIs of scopes, signed in
+// as the service account of the base64 encoded credentials.
+func authorize(ctx context.Context, credentialsBase64 string, scopes ...string) (*http.Client, error) {
+	decodedCreds, err := base64.StdEncoding.DecodeString(credentialsBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
 
-	for i := 0; i < length; i++ {
-		if (length-i)%3 == 0 && i != 0 {
-			result.WriteString(".")
-		}
-		result.WriteByte(str[i])
+	config, err := google.JWTConfigFromJSON(decodedCreds, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
 	}
 
-	return result.String()
+	return config.Client(ctx), nil
 }
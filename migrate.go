@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// entryOwnedBy matches the expense rows recorded from chatID's messages. It
+// only looks at the message ref, so /reset also deletes rows of the user
+// that parseRow rejects.
+func entryOwnedBy(chatID int64) func(row []interface{}) bool {
+	prefix := strconv.FormatInt(chatID, 10) + ":"
+	return func(row []interface{}) bool {
+		return len(row) > refColumn && strings.HasPrefix(fmt.Sprintf("%v", row[refColumn]), prefix)
+	}
+}
+
+// spreadsheetIDFromLink accepts either a spreadsheet ID or a link to the
+// spreadsheet, as copied from the browser, and returns the ID.
+func spreadsheetIDFromLink(s string) string {
+	if _, rest, ok := strings.Cut(s, "/d/"); ok {
+		id, _, _ := strings.Cut(rest, "/")
+		return id
+	}
+	return s
+}
+
+// migrateData copies chatID's entries into the first tab of dst, below what
+// it already holds. It returns how many rows were copied and their message
+// refs, so that exactly those can be deleted afterwards. Descriptions are
+// written decrypted since dst belongs to the user.
+func (b *Bot) migrateData(ctx context.Context, dst SheetStore, chatID int64) (int, map[string]bool, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	existing, err := dst.Get("A:A")
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read destination spreadsheet: %w", err)
+	}
+	if len(existing) == 0 {
+		if err := dst.Update("A1", [][]interface{}{expenseHeader}); err != nil {
+			return 0, nil, fmt.Errorf("failed to write destination header: %w", err)
+		}
+		existing = [][]interface{}{expenseHeader}
+	} else if err := extendHeader(dst, "", expenseHeader); err != nil {
+		return 0, nil, err
+	}
+
+	prefix := strconv.FormatInt(chatID, 10) + ":"
+	refs := make(map[string]bool)
+	var values [][]interface{}
+	for _, row := range rows {
+		if !strings.HasPrefix(row.Ref, prefix) {
+			continue
+		}
+		values = append(values, []interface{}{
+			len(existing) + len(values) + 1, row.Date.Format("02-01-2006"), row.Nominal, row.Category, row.Description, row.Ref, row.Receipt, row.Sender, row.Location,
+		})
+		refs[row.Ref] = true
+	}
+	if len(values) == 0 {
+		return 0, refs, nil
+	}
+
+	if err := dst.Append("A1", values); err != nil {
+		return 0, nil, fmt.Errorf("failed to write migrated entries: %w", err)
+	}
+	return len(values), refs, nil
+}
+
+// deleteMigrated deletes the entries of chatID copied by migrateData. Entries
+// recorded since are kept.
+func (b *Bot) deleteMigrated(ctx context.Context, chatID int64, refs map[string]bool) (int, error) {
+	owned := entryOwnedBy(chatID)
+	return b.clearRows(ctx, "", b.sheetConfig.lastColumn(), func(raw []interface{}) bool {
+		row, err := b.sheetConfig.parseRow(raw)
+		return err == nil && owned(raw) && refs[row.Ref]
+	})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Named ranges the bot finds its data through. The Sheets API keeps a named
+// range on its tab when the tab is renamed or moved, so the bot keeps working
+// when a user reorganises the spreadsheet. They are created by migration 3.
+const (
+	expenseDataRange    = "EXPENSE_DATA"
+	preferenceDataRange = "PREFERENCE_DATA"
+)
+
+// defaultNamedRanges is where each named range is created, and what
+// namedRange falls back to while it does not exist.
+var defaultNamedRanges = map[string]string{
+	expenseDataRange:    "A:" + defaultSheetConfig.lastColumn(),
+	preferenceDataRange: sheetRange(preferencesSheet, "A:Z"),
+}
+
+// loadNamedRanges reads the named ranges of the store. It is called once at
+// startup, before anything reads the data they point at.
+func (b *Bot) loadNamedRanges(ctx context.Context) error {
+	ranges, err := storeWithContext(ctx, b.store).NamedRanges()
+	if err != nil {
+		return fmt.Errorf("failed to get named ranges: %w", err)
+	}
+	b.namedRanges = ranges
+
+	// The tab the preferences are on may have been renamed, it must not be
+	// created again under its old title.
+	if _, ok := ranges[preferenceDataRange]; ok {
+		b.mu.Lock()
+		b.readyTabs[preferencesSheet] = true
+		b.mu.Unlock()
+	}
+	return nil
+}
+
+// namedRange returns the A1 range of the named range name.
+func (b *Bot) namedRange(name string) string {
+	if a1, ok := b.namedRanges[name]; ok {
+		return a1
+	}
+	return defaultNamedRanges[name]
+}
+
+// rangeIn returns cells, such as "A5:G5", on the tab of the named range
+// name. Columns are not shifted: the layout of the expense tab is set by
+// SheetConfig.
+func (b *Bot) rangeIn(name, cells string) string {
+	a1 := b.namedRange(name)
+	i := strings.LastIndex(a1, "!")
+	if i < 0 {
+		return cells
+	}
+	return a1[:i+1] + cells
+}
+
+// namedRangeTabs maps the tabs found through a named range, by their default
+// title, to the name of the range. The expense tab has no title.
+var namedRangeTabs = map[string]string{
+	"":               expenseDataRange,
+	preferencesSheet: preferenceDataRange,
+}
+
+// tabRange returns cells on the tab title, found through its named range when
+// it has one.
+func (b *Bot) tabRange(title, cells string) string {
+	if name, ok := namedRangeTabs[title]; ok {
+		return b.rangeIn(name, cells)
+	}
+	return sheetRange(title, cells)
+}
+
+// expenseRange returns cells on the expense tab.
+func (b *Bot) expenseRange(cells string) string {
+	return b.rangeIn(expenseDataRange, cells)
+}
+
+// addNamedRanges creates the named ranges the store does not have yet.
+func addNamedRanges(store SheetStore) error {
+	existing, err := store.NamedRanges()
+	if err != nil {
+		return fmt.Errorf("failed to get named ranges: %w", err)
+	}
+	// The named range marks the tab as ready, so it gets its header here.
+	if err := store.EnsureSheet(preferencesSheet); err != nil {
+		return fmt.Errorf("failed to create sheet %q: %w", preferencesSheet, err)
+	}
+	rows, err := store.Get(sheetRange(preferencesSheet, "A1:Z1"))
+	if err != nil {
+		return fmt.Errorf("failed to read sheet %q: %w", preferencesSheet, err)
+	}
+	if len(rows) == 0 {
+		if err := store.Update(sheetRange(preferencesSheet, "A1"), [][]interface{}{preferencesHeader}); err != nil {
+			return fmt.Errorf("failed to write header of sheet %q: %w", preferencesSheet, err)
+		}
+	}
+	for _, name := range []string{expenseDataRange, preferenceDataRange} {
+		if _, ok := existing[name]; ok {
+			continue
+		}
+		if err := store.AddNamedRange(name, defaultNamedRanges[name]); err != nil {
+			return fmt.Errorf("failed to add named range %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// onboardingStep is the question the onboarding wizard is waiting on.
+type onboardingStep int
+
+const (
+	stepAskName onboardingStep = iota + 1
+	stepAskTimezone
+	stepAskReminder
+)
+
+// conversationState is the progress of a user through the onboarding wizard,
+// with the settings collected so far.
+type conversationState struct {
+	step onboardingStep
+	pref UserPreference
+}
+
+func (b *Bot) conversationState(chatID int64) (conversationState, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	state, ok := b.conversationStates[chatID]
+	return state, ok
+}
+
+func (b *Bot) setConversationState(chatID int64, state conversationState) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.conversationStates[chatID] = state
+}
+
+func (b *Bot) clearConversationState(chatID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.conversationStates, chatID)
+}
+
+// startOnboarding asks a new user the first question of the wizard.
+func (b *Bot) startOnboarding(chatID int64) {
+	b.setConversationState(chatID, conversationState{
+		step: stepAskName,
+		pref: UserPreference{ChatID: chatID},
+	})
+	b.sendMessage(tgbotapi.NewMessage(chatID, "👋 Hai! Saya adalah bot pencatat keuangan.\n\n"+
+		"Sebelum mulai, ada 3 pertanyaan singkat.\n\n"+
+		"1️⃣ Siapa nama kamu?"))
+}
+
+// handleOnboardingMessage handles a text reply sent during the wizard.
+func (b *Bot) handleOnboardingMessage(chatID int64, text string, state conversationState) {
+	if state.step != stepAskName {
+		b.sendMessage(tgbotapi.NewMessage(chatID, "👆 Pilih salah satu tombol di atas untuk melanjutkan"))
+		return
+	}
+
+	name := strings.TrimSpace(text)
+	if name == "" {
+		b.sendMessage(tgbotapi.NewMessage(chatID, "❌ Nama tidak boleh kosong. Siapa nama kamu?"))
+		return
+	}
+
+	state.pref.Name = name
+	state.step = stepAskTimezone
+	b.setConversationState(chatID, state)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, tz := range timezoneOptions {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tz.label, "onboard_tz:"+tz.name),
+		))
+	}
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Salam kenal, %s! 😊\n\n2️⃣ Kamu tinggal di zona waktu mana?", name))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.sendMessage(msg)
+}
+
+// handleOnboardingCallback handles the wizard's inline keyboard buttons.
+func (b *Bot) handleOnboardingCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	state, ok := b.conversationState(chatID)
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Sesi pengaturan sudah berakhir. Kirim /start untuk memulai lagi"))
+		return
+	}
+
+	switch {
+	case state.step == stepAskTimezone && strings.HasPrefix(data, "tz:"):
+		state.pref.Timezone = strings.TrimPrefix(data, "tz:")
+		state.step = stepAskReminder
+		b.setConversationState(chatID, state)
+
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🌏 Zona waktu: "+timezoneLabel(state.pref.Timezone)))
+		msg := tgbotapi.NewMessage(chatID, "3️⃣ Seberapa sering kamu ingin diingatkan untuk mencatat pengeluaran?")
+		msg.ReplyMarkup = reminderKeyboard("onboard_reminder:")
+		b.sendMessage(msg)
+
+	case state.step == stepAskReminder && strings.HasPrefix(data, "reminder:"):
+		state.pref.ReminderType = ReminderType(strings.TrimPrefix(data, "reminder:"))
+		if err := b.saveUserPreference(ctx, state.pref); err != nil {
+			log.Printf("failed to save preference of %d: %v", chatID, err)
+			b.sendMessage(tgbotapi.NewMessage(chatID, "❌ Gagal menyimpan pengaturan. Silakan pilih lagi"))
+			return
+		}
+		b.clearConversationState(chatID)
+
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🔔 Pengingat: "+state.pref.ReminderType.label()))
+		b.sendMessage(tgbotapi.NewMessage(chatID, welcomeCard(state.pref)))
+	}
+}
+
+func welcomeCard(pref UserPreference) string {
+	return fmt.Sprintf("🎉 Selamat datang, %s!\n\n"+
+		"⚙️ Pengaturan kamu:\n"+
+		"👤 Nama: %s\n"+
+		"🌏 Zona waktu: %s\n"+
+		"🔔 Pengingat: %s\n\n"+
+		"📝 Untuk mencatat pengeluaran, kirim dalam format:\n"+
+		"Nominal, Kategori, Keterangan\n"+
+		"Contoh: 10rb, Makanan, Makan Siang di Kantin\n\n"+
+		"🚀 Ayo mulai! Kirim pengeluaran pertamamu sekarang.\n"+
+		"Ketik /help untuk melihat semua perintah.",
+		pref.Name, pref.Name, timezoneLabel(pref.Timezone), pref.ReminderType.label())
+}
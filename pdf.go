@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// pdfLinesPerPage is how many lines of text fit on an A4 page of renderPDF.
+const pdfLinesPerPage = 50
+
+// pdfText turns s into a PDF string literal. The built-in fonts only cover
+// Latin-1, so other characters, such as the emoji of the chat messages, are
+// dropped, and so are the spaces they leave at either end.
+func pdfText(s string) string {
+	var result strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			result.WriteByte('\\')
+			result.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			result.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			result.WriteString(fmt.Sprintf("\\%03o", r))
+		}
+	}
+	return "(" + strings.TrimSpace(result.String()) + ")"
+}
+
+// renderPDF lays out lines as plain text in Helvetica on A4 pages, the first
+// line as the title, and returns the PDF document.
+func renderPDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	// Objects 1 to 3 are the catalog, the page tree and the font, followed
+	// by a page and its content for every page.
+	var objects []string
+	var kids []string
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 4+2*i))
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	)
+	for i, page := range pages {
+		var content strings.Builder
+		content.WriteString("BT\n/F1 11 Tf\n14 TL\n50 790 Td\n")
+		for j, line := range page {
+			if i == 0 && j == 0 {
+				content.WriteString(fmt.Sprintf("/F1 16 Tf\n%s Tj\n/F1 11 Tf\nT*\n", pdfText(line)))
+				continue
+			}
+			content.WriteString(pdfText(line) + " Tj\nT*\n")
+		}
+		content.WriteString("ET")
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return doc.Bytes()
+}
+
+// pdfArrows spells out the arrows of the report, which Helvetica lacks.
+var pdfArrows = strings.NewReplacer("▲", "+", "▼", "-")
+
+// pdf renders the report as a PDF document.
+func (r MonthlyReport) pdf() []byte {
+	return renderPDF(strings.Split(pdfArrows.Replace(strings.TrimRight(r.format(), "\n")), "\n"))
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// pendingSheet holds the entries copied by /copy_month until the user
+// confirms or deletes them. They are kept out of the main tab so that they
+// do not count in any total before they are confirmed.
+const pendingSheet = "Pending"
+
+var pendingHeader = []interface{}{"ChatID", "Tanggal", "Nominal", "Kategori", "Keterangan"}
+
+// pendingListSize is how many pending items one message offers buttons for.
+const pendingListSize = 10
+
+// copiedRefSuffix marks the message ref of an entry recorded from a pending
+// item, as there is no message it was recorded from.
+const copiedRefSuffix = "salinan"
+
+// PendingItem is an entry copied by /copy_month that is not recorded yet.
+type PendingItem struct {
+	Date        time.Time
+	Nominal     int
+	Category    string
+	Description string
+	sheetRow    int // row of the item in the Pending tab
+}
+
+// shiftToMonth moves date to the same day of month, or to its last day when
+// the month is shorter.
+func shiftToMonth(date, month time.Time) time.Time {
+	lastDay := time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.Local).Day()
+	return time.Date(month.Year(), month.Month(), min(date.Day(), lastDay), 0, 0, 0, 0, time.Local)
+}
+
+// getPendingItems returns the pending items of chatID in the order they were
+// copied.
+func (b *Bot) getPendingItems(ctx context.Context, chatID int64) ([]PendingItem, error) {
+	if err := b.ensureTab(ctx, pendingSheet, pendingHeader); err != nil {
+		return nil, err
+	}
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(pendingSheet, "A:E"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending items: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	var items []PendingItem
+	for i, row := range rows {
+		if i == 0 || len(row) < 4 || fmt.Sprintf("%v", row[0]) != id { // Skip header
+			continue
+		}
+		date, err := time.ParseInLocation("02-01-2006", fmt.Sprintf("%v", row[1]), time.Local)
+		if err != nil {
+			continue
+		}
+		nominal, err := strconv.Atoi(fmt.Sprintf("%v", row[2]))
+		if err != nil {
+			continue
+		}
+		item := PendingItem{Date: date, Nominal: nominal, Category: fmt.Sprintf("%v", row[3]), sheetRow: i + 1}
+		if len(row) > 4 {
+			item.Description = b.openDescription(id, row[4])
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// copyMonth copies the entries chatID recorded in month to the Pending tab,
+// dated on the same days of the current month, and returns how many were
+// copied.
+func (b *Bot) copyMonth(ctx context.Context, chatID int64, month time.Time) (int, error) {
+	if err := b.ensureTab(ctx, pendingSheet, pendingHeader); err != nil {
+		return 0, err
+	}
+
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	rows, err := b.getEntriesBetween(ctx, start, start.AddDate(0, 1, -1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy month: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	var values [][]interface{}
+	for _, row := range rows {
+		if refChatID(row.Ref) != id {
+			continue
+		}
+		description, err := b.sealDescription(id, row.Description)
+		if err != nil {
+			return 0, err
+		}
+		date := shiftToMonth(row.Date, time.Now())
+		values = append(values, []interface{}{id, date.Format("02-01-2006"), row.Nominal, row.Category, description})
+	}
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	if err := storeWithContext(ctx, b.store).Append(sheetRange(pendingSheet, "A1"), values); err != nil {
+		return 0, fmt.Errorf("failed to save pending items: %w", err)
+	}
+	return len(values), nil
+}
+
+// findPendingItem returns the pending item of chatID at sheetRow of the
+// Pending tab.
+func (b *Bot) findPendingItem(ctx context.Context, chatID int64, sheetRow int) (PendingItem, bool, error) {
+	items, err := b.getPendingItems(ctx, chatID)
+	if err != nil {
+		return PendingItem{}, false, err
+	}
+	for _, item := range items {
+		if item.sheetRow == sheetRow {
+			return item, true, nil
+		}
+	}
+	return PendingItem{}, false, nil
+}
+
+func (b *Bot) removePendingItem(ctx context.Context, item PendingItem) error {
+	if err := storeWithContext(ctx, b.store).Clear(sheetRange(pendingSheet, fmt.Sprintf("A%d:E%d", item.sheetRow, item.sheetRow))); err != nil {
+		return fmt.Errorf("failed to remove pending item: %w", err)
+	}
+	return nil
+}
+
+// pendingList lists the pending items of chatID with a button to confirm and
+// one to delete each of the first pendingListSize. The keyboard is nil when
+// nothing is pending.
+func (b *Bot) pendingList(ctx context.Context, chatID int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	items, err := b.getPendingItems(ctx, chatID)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(items) == 0 {
+		return "✅ Tidak ada entri yang menunggu konfirmasi", nil, nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("⏳ %d entri menunggu konfirmasi:\n\n", len(items)))
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, item := range items[:min(len(items), pendingListSize)] {
+		result.WriteString(fmt.Sprintf("%d. 📅%s - 💰%s | 🎯%s | 📚%s\n",
+			i+1, item.Date.Format("02-01-2006"), formatRupiah(item.Nominal), item.Category, item.Description))
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ Catat %d", i+1), fmt.Sprintf("pending_confirm:%d", item.sheetRow)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🗑 Hapus %d", i+1), fmt.Sprintf("pending_delete:%d", item.sheetRow)),
+		))
+	}
+	if len(items) > pendingListSize {
+		result.WriteString(fmt.Sprintf("\n…dan %d lainnya, muncul setelah entri di atas dikonfirmasi atau dihapus.", len(items)-pendingListSize))
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return strings.TrimRight(result.String(), "\n"), &markup, nil
+}
+
+// sendPendingList sends the pending items of chatID, or replaces the list in
+// messageID when it is not 0.
+func (b *Bot) sendPendingList(ctx context.Context, chatID int64, messageID int, notice string) {
+	text, keyboard, err := b.pendingList(ctx, chatID)
+	if err != nil {
+		text, keyboard = "❌ Gagal mengambil entri yang menunggu konfirmasi", nil
+	}
+	if notice != "" {
+		text = notice + "\n\n" + text
+	}
+
+	if messageID == 0 {
+		msg := tgbotapi.NewMessage(chatID, text)
+		if keyboard != nil {
+			msg.ReplyMarkup = keyboard
+		}
+		b.sendMessage(msg)
+		return
+	}
+	if keyboard != nil {
+		b.sendMessage(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, *keyboard))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, text))
+}
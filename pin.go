@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const pinnedSheet = "Pinned"
+
+var pinnedHeader = []interface{}{"ChatID", "RowNumber", "PinnedAt", "Label"}
+
+// Pin is an entry a user bookmarked with /pin.
+type Pin struct {
+	Row      int
+	PinnedAt string
+	Label    string
+	sheetRow int // row of the pin in the Pinned tab
+}
+
+// getPins returns the pins of chatID in the order they were made.
+func (b *Bot) getPins(ctx context.Context, chatID int64) ([]Pin, error) {
+	if err := b.ensureTab(ctx, pinnedSheet, pinnedHeader); err != nil {
+		return nil, err
+	}
+
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(pinnedSheet, "A:D"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pins: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	var pins []Pin
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 || fmt.Sprintf("%v", row[0]) != id { // Skip header
+			continue
+		}
+
+		rowNumber, err := strconv.Atoi(fmt.Sprintf("%v", row[1]))
+		if err != nil {
+			continue
+		}
+		pin := Pin{Row: rowNumber, PinnedAt: fmt.Sprintf("%v", row[2]), sheetRow: i + 1}
+		if len(row) > 3 {
+			pin.Label = fmt.Sprintf("%v", row[3])
+		}
+		pins = append(pins, pin)
+	}
+	return pins, nil
+}
+
+// pinnedRows returns the entry rows chatID has pinned.
+func (b *Bot) pinnedRows(ctx context.Context, chatID int64) (map[int]bool, error) {
+	pins, err := b.getPins(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[int]bool, len(pins))
+	for _, pin := range pins {
+		rows[pin.Row] = true
+	}
+	return rows, nil
+}
+
+// pinEntry pins an entry row for chatID, replacing the label of an
+// existing pin of the same row.
+func (b *Bot) pinEntry(ctx context.Context, chatID int64, row int, label string) error {
+	store := storeWithContext(ctx, b.store)
+	pins, err := b.getPins(ctx, chatID)
+	if err != nil {
+		return err
+	}
+
+	values := [][]interface{}{{strconv.FormatInt(chatID, 10), row, time.Now().Format("02-01-2006"), label}}
+	for _, pin := range pins {
+		if pin.Row == row {
+			return store.Update(sheetRange(pinnedSheet, fmt.Sprintf("A%d", pin.sheetRow)), values)
+		}
+	}
+	return store.Append(sheetRange(pinnedSheet, "A1"), values)
+}
+
+// unpinEntry removes the pin of an entry row and reports whether there was one.
+func (b *Bot) unpinEntry(ctx context.Context, chatID int64, row int) (bool, error) {
+	pins, err := b.getPins(ctx, chatID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, pin := range pins {
+		if pin.Row == row {
+			if err := storeWithContext(ctx, b.store).Clear(sheetRange(pinnedSheet, fmt.Sprintf("A%d:D%d", pin.sheetRow, pin.sheetRow))); err != nil {
+				return false, fmt.Errorf("failed to remove pin: %w", err)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// formatPins lists the pins of chatID with the current content of each entry.
+func (b *Bot) formatPins(ctx context.Context, chatID int64) (string, error) {
+	pins, err := b.getPins(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+
+	if len(pins) == 0 {
+		return "📌 Belum ada entri yang di-pin. Gunakan /pin <nomor> [label]", nil
+	}
+
+	var result strings.Builder
+	result.WriteString("📌 Entri yang di-pin:\n\n")
+	for _, pin := range pins {
+		entry, err := b.getEntryByNumber(ctx, pin.Row)
+		if err != nil {
+			entry = "(entri sudah tidak ada)"
+		}
+
+		label := ""
+		if pin.Label != "" {
+			label = " " + pin.Label
+		}
+		result.WriteString(fmt.Sprintf("#%d%s\n%s\n\n", pin.Row, label, entry))
+	}
+	return strings.TrimRight(result.String(), "\n"), nil
+}
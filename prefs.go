@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+	_ "time/tzdata" // user timezones must load on hosts without zoneinfo
+)
+
+const preferencesSheet = "Preferences"
+
+var preferencesHeader = []interface{}{"ChatID", "Nama", "Timezone", "Reminder", "Limit", "Language", "Digest", "WeeklyReport", "WeeklyLimit", "PausedUntil", "ReportEmail", "Account", "JointSheet"}
+
+// defaultTimezone is used for users who have not picked a timezone.
+const defaultTimezone = "Asia/Jakarta"
+
+// timezoneOptions are the timezones offered during onboarding.
+var timezoneOptions = []struct {
+	label, name string
+}{
+	{"WIB (Jakarta)", "Asia/Jakarta"},
+	{"WITA (Makassar)", "Asia/Makassar"},
+	{"WIT (Jayapura)", "Asia/Jayapura"},
+}
+
+func timezoneLabel(name string) string {
+	for _, tz := range timezoneOptions {
+		if tz.name == name {
+			return tz.label
+		}
+	}
+	return name
+}
+
+// location returns the user's timezone, falling back to defaultTimezone.
+func (pref UserPreference) location() *time.Location {
+	name := pref.Timezone
+	if name == "" {
+		name = defaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+func preferenceRow(pref UserPreference) []interface{} {
+	pausedUntil := ""
+	if pref.PausedUntil != nil {
+		pausedUntil = pref.PausedUntil.Format(time.RFC3339)
+	}
+	return []interface{}{strconv.FormatInt(pref.ChatID, 10), pref.Name, pref.Timezone, string(pref.ReminderType), pref.MonthlyLimit, pref.Language, pref.DigestTime, pref.WeeklyReportEnabled, pref.WeeklyLimit, pausedUntil, pref.ReportEmail, pref.Account, pref.JointSheet}
+}
+
+func parsePreferenceRow(row []interface{}) (UserPreference, bool) {
+	cell := func(i int) string {
+		if i < len(row) && row[i] != nil {
+			return fmt.Sprintf("%v", row[i])
+		}
+		return ""
+	}
+
+	chatID, err := strconv.ParseInt(cell(0), 10, 64)
+	if err != nil {
+		return UserPreference{}, false
+	}
+	monthlyLimit, _ := strconv.Atoi(cell(4))
+	weeklyReport, _ := strconv.ParseBool(cell(7))
+	weeklyLimit, _ := strconv.Atoi(cell(8))
+	var pausedUntil *time.Time
+	if until, err := time.Parse(time.RFC3339, cell(9)); err == nil {
+		pausedUntil = &until
+	}
+	return UserPreference{
+		ChatID:       chatID,
+		Name:         cell(1),
+		Timezone:     cell(2),
+		ReminderType: ReminderType(cell(3)),
+		MonthlyLimit: monthlyLimit,
+		WeeklyLimit:  weeklyLimit,
+		Language:     cell(5),
+		DigestTime:   cell(6),
+
+		WeeklyReportEnabled: weeklyReport,
+		PausedUntil:         pausedUntil,
+		ReportEmail:         cell(10),
+		Account:             cell(11),
+		JointSheet:          cell(12),
+	}, true
+}
+
+// loadUserPreferences reads every saved preference from the Preferences tab,
+// replacing the ones in memory, and returns how many were loaded. Any
+// preference that differs from the one in memory is logged, so that a sync
+// after the tab was edited by hand shows what it changed.
+func (b *Bot) loadUserPreferences(ctx context.Context) (int, error) {
+	if err := b.ensureTab(ctx, preferencesSheet, preferencesHeader); err != nil {
+		return 0, err
+	}
+
+	rows, err := storeWithContext(ctx, b.store).Get(b.tabRange(preferencesSheet, "A:Z"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	prefs := make(map[int64]UserPreference, len(rows))
+	for i, row := range rows {
+		if i == 0 { // Skip header
+			continue
+		}
+		if pref, ok := parsePreferenceRow(row); ok {
+			prefs[pref.ChatID] = pref
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for chatID, old := range b.prefs {
+		pref, ok := prefs[chatID]
+		if !ok {
+			log.Printf("preferences of %d removed from the sheet", chatID)
+			continue
+		}
+		if before, after := preferenceChanges(old, pref); before != "" {
+			log.Printf("preferences of %d changed in the sheet: %s -> %s", chatID, before, after)
+		}
+	}
+	b.prefs = prefs
+	return len(prefs), nil
+}
+
+func (b *Bot) getPreference(chatID int64) (UserPreference, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	pref, ok := b.prefs[chatID]
+	return pref, ok
+}
+
+// saveUserPreference stores pref, overwriting the user's existing row in the
+// Preferences tab or appending a new one.
+func (b *Bot) saveUserPreference(ctx context.Context, pref UserPreference) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, preferencesSheet, preferencesHeader); err != nil {
+		return err
+	}
+
+	rows, err := store.Get(b.tabRange(preferencesSheet, "A:A"))
+	if err != nil {
+		return fmt.Errorf("failed to get preferences: %w", err)
+	}
+
+	id := strconv.FormatInt(pref.ChatID, 10)
+	old, _ := b.getPreference(pref.ChatID)
+	old.ChatID = pref.ChatID
+	values := [][]interface{}{preferenceRow(pref)}
+	saved := false
+	for i, row := range rows {
+		if i > 0 && len(row) > 0 && fmt.Sprintf("%v", row[0]) == id {
+			if err := store.Update(b.tabRange(preferencesSheet, fmt.Sprintf("A%d", i+1)), values); err != nil {
+				return fmt.Errorf("failed to update preference: %w", err)
+			}
+			saved = true
+			break
+		}
+	}
+	if !saved {
+		if err := store.Append(b.tabRange(preferencesSheet, "A1"), values); err != nil {
+			return fmt.Errorf("failed to append preference: %w", err)
+		}
+	}
+
+	b.mu.Lock()
+	b.prefs[pref.ChatID] = pref
+	b.mu.Unlock()
+
+	if oldValue, newValue := preferenceChanges(old, pref); newValue != "" {
+		b.logAudit(ctx, id, auditPreferenceChange, 0, oldValue, newValue)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// messageQueueSize is how many updates can wait for the workers in all.
+// Updates arriving while that many are waiting are dropped.
+const messageQueueSize = 100
+
+// queuedUpdate is an update waiting for a worker, with the context it is
+// handled in. done is closed once it was handled.
+type queuedUpdate struct {
+	ctx    context.Context
+	update tgbotapi.Update
+	done   chan struct{}
+}
+
+// MessageQueue hands updates to a pool of workers, so a slow Sheets call
+// only holds up the chats of its worker. The updates of a chat always go to
+// the same worker, which handles them in the order they arrived.
+type MessageQueue struct {
+	shards  []chan queuedUpdate // queue of every worker
+	waiting chan struct{}       // holds a token for every queued update
+	workers sync.WaitGroup
+}
+
+// NewMessageQueue starts workers goroutines handling the queued updates
+// with bot.
+func NewMessageQueue(bot *Bot, workers int) *MessageQueue {
+	q := &MessageQueue{
+		shards:  make([]chan queuedUpdate, workers),
+		waiting: make(chan struct{}, messageQueueSize),
+	}
+	for i := range q.shards {
+		// Any worker may have to hold every waiting update, when they all
+		// come from its chats
+		updates := make(chan queuedUpdate, messageQueueSize)
+		q.shards[i] = updates
+		q.workers.Add(1)
+		go func() {
+			defer q.workers.Done()
+			for queued := range updates {
+				<-q.waiting
+				bot.handleUpdate(queued.ctx, queued.update)
+				close(queued.done)
+			}
+		}()
+	}
+	return q
+}
+
+// Stop waits for the workers to finish the updates already queued. Push
+// must not be called after Stop.
+func (q *MessageQueue) Stop() {
+	for _, updates := range q.shards {
+		close(updates)
+	}
+	q.workers.Wait()
+}
+
+// updateChatID returns the chat update belongs to, or the user for inline
+// queries, which have no chat. It is 0 for other updates.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.EditedMessage != nil:
+		return update.EditedMessage.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.InlineQuery != nil && update.InlineQuery.From != nil:
+		return update.InlineQuery.From.ID
+	}
+	return 0
+}
+
+// shard returns the queue of the worker handling the updates of chatID.
+func (q *MessageQueue) shard(chatID int64) chan queuedUpdate {
+	// Group chats have negative IDs
+	n := chatID % int64(len(q.shards))
+	if n < 0 {
+		n = -n
+	}
+	return q.shards[n]
+}
+
+// Push queues update to be handled in ctx without blocking, dropping it
+// when messageQueueSize updates are already waiting. The returned channel
+// is closed once the update was handled or dropped.
+func (q *MessageQueue) Push(ctx context.Context, update tgbotapi.Update) <-chan struct{} {
+	done := make(chan struct{})
+	select {
+	case q.waiting <- struct{}{}:
+		// The token keeps every shard below its capacity, so this does
+		// not block
+		q.shard(updateChatID(update)) <- queuedUpdate{ctx: ctx, update: update, done: done}
+	default:
+		log.Printf("⚠️ message queue is full, dropping update %d", update.UpdateID)
+		close(done)
+	}
+	return done
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestMessageQueueKeepsChatOrder(t *testing.T) {
+	b, _ := newTestBot(t)
+	q := NewMessageQueue(b, 4)
+	for i := range 10 {
+		for chatID := int64(1); chatID <= 5; chatID++ {
+			q.Push(context.Background(), textUpdate(chatID, fmt.Sprintf("%drb, Makanan, %d", i+1, i)))
+		}
+	}
+	q.Stop()
+
+	rows, err := b.getRows(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 50 {
+		t.Fatalf("got %d entries, want 50", len(rows))
+	}
+
+	raw, err := b.store.Get(b.expenseRange("A:A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := make(map[string]int)
+	for _, row := range rows {
+		if got := raw[row.RowNum-1][0]; got != row.RowNum {
+			t.Errorf("row %d is numbered %v", row.RowNum, got)
+		}
+		chat := refChatID(row.Ref)
+		if want := strconv.Itoa(next[chat]); row.Description != want {
+			t.Errorf("chat %s: got entry %q, want %q", chat, row.Description, want)
+		}
+		next[chat]++
+	}
+}
+
+func TestMessageQueueLimitIsShared(t *testing.T) {
+	// No workers take the updates, so they all stay waiting
+	q := &MessageQueue{waiting: make(chan struct{}, messageQueueSize)}
+	for range 4 {
+		q.shards = append(q.shards, make(chan queuedUpdate, messageQueueSize))
+	}
+
+	isClosed := func(done <-chan struct{}) bool {
+		select {
+		case <-done:
+			return true
+		default:
+			return false
+		}
+	}
+	for i := range messageQueueSize {
+		if isClosed(q.Push(context.Background(), textUpdate(1, "1rb, Makanan"))) {
+			t.Fatalf("update %d of one chat was dropped while the queue had room", i+1)
+		}
+	}
+	if !isClosed(q.Push(context.Background(), textUpdate(2, "1rb, Makanan"))) {
+		t.Errorf("update was queued past messageQueueSize")
+	}
+}
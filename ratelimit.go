@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sendWithRateLimit sends msg and, when Telegram answers 429 Too Many
+// Requests, waits for as long as it asks and retries once.
+func sendWithRateLimit(bot *tgbotapi.BotAPI, msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+	sent, err := bot.Send(msg)
+
+	var apiErr *tgbotapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusTooManyRequests {
+		return sent, err
+	}
+
+	wait := time.Duration(apiErr.RetryAfter+1) * time.Second
+	log.Printf("rate limited by Telegram, retrying in %v", wait)
+	time.Sleep(wait)
+	return bot.Send(msg)
+}
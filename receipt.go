@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// ReceiptStore keeps the receipt photos attached to entries.
+type ReceiptStore interface {
+	// SaveReceipt stores a photo and returns the URL it can be viewed at.
+	SaveReceipt(name string, photo io.Reader) (string, error)
+}
+
+// DriveReceiptStore uploads receipts into a Google Drive folder shared with
+// the service account.
+type DriveReceiptStore struct {
+	service  func() (*drive.Service, error)
+	folderID string
+}
+
+func NewDriveReceiptStore(service func() (*drive.Service, error), folderID string) *DriveReceiptStore {
+	return &DriveReceiptStore{service: service, folderID: folderID}
+}
+
+func (s *DriveReceiptStore) SaveReceipt(name string, photo io.Reader) (string, error) {
+	srv, err := s.service()
+	if err != nil {
+		return "", err
+	}
+	file, err := srv.Files.Create(&drive.File{Name: name, Parents: []string{s.folderID}}).
+		Media(photo).
+		Fields("webViewLink").
+		Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to upload receipt: %w", err)
+	}
+	return file.WebViewLink, nil
+}
+
+var receiptClient = &http.Client{Timeout: 30 * time.Second}
+
+// attachReceipt downloads the Telegram photo fileID, saves it to the receipt
+// store and links it from column G of the entry in row of the tab sheet, see
+// accountSheet. It returns the receipt URL.
+func (b *Bot) attachReceipt(ctx context.Context, sheet string, row int, fileID string) (string, error) {
+	if b.receipts == nil {
+		return "", fmt.Errorf("receipts are not enabled")
+	}
+
+	photoURL, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get receipt photo: %w", err)
+	}
+	resp, err := receiptClient.Get(photoURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download receipt photo: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download receipt photo: %s", resp.Status)
+	}
+
+	url, err := b.receipts.SaveReceipt(fmt.Sprintf("struk-%d-%s.jpg", row, time.Now().Format("20060102-150405")), resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if err := storeWithContext(ctx, b.store).Update(b.tabRange(sheet, fmt.Sprintf("%s%d", columnLetter(receiptColumn), row)), [][]interface{}{{url}}); err != nil {
+		return "", fmt.Errorf("failed to save receipt URL: %w", err)
+	}
+	return url, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ReminderType is how often a user wants to be reminded of their spending.
+type ReminderType string
+
+const (
+	ReminderNone    ReminderType = "none"
+	ReminderDaily   ReminderType = "daily"
+	ReminderWeekly  ReminderType = "weekly"
+	ReminderMonthly ReminderType = "monthly"
+)
+
+// reminderHour is the hour, in the user's timezone, reminders are sent at.
+const reminderHour = 20
+
+var reminderTypes = []ReminderType{ReminderDaily, ReminderWeekly, ReminderMonthly, ReminderNone}
+
+func (t ReminderType) label() string {
+	switch t {
+	case ReminderDaily:
+		return fmt.Sprintf("Harian (setiap hari, %02d:00)", reminderHour)
+	case ReminderWeekly:
+		return fmt.Sprintf("Mingguan (setiap Minggu, %02d:00)", reminderHour)
+	case ReminderMonthly:
+		return fmt.Sprintf("Bulanan (akhir bulan, %02d:00)", reminderHour)
+	default:
+		return "Tidak ada"
+	}
+}
+
+// reminderKeyboard offers every reminder type, with callback data made of
+// prefix and the type.
+func reminderKeyboard(prefix string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, t := range reminderTypes {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(t.label(), prefix+string(t)),
+		))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// reminderDue reports whether a reminder of type t should be sent at now,
+// given in the user's timezone.
+func reminderDue(t ReminderType, now time.Time) bool {
+	if now.Hour() != reminderHour || now.Minute() != 0 {
+		return false
+	}
+	switch t {
+	case ReminderDaily:
+		return true
+	case ReminderWeekly:
+		return now.Weekday() == time.Sunday
+	case ReminderMonthly:
+		return now.AddDate(0, 0, 1).Day() == 1
+	default:
+		return false
+	}
+}
+
+func (b *Bot) sendDueReminders(ctx context.Context, now time.Time) {
+	b.mu.RLock()
+	prefs := make([]UserPreference, 0, len(b.prefs))
+	for _, pref := range b.prefs {
+		prefs = append(prefs, pref)
+	}
+	b.mu.RUnlock()
+
+	for _, pref := range prefs {
+		if pref.remindersPaused(now) || !reminderDue(pref.ReminderType, now.In(pref.location())) {
+			continue
+		}
+		if err := b.sendReminder(ctx, pref.ChatID, pref.ReminderType); err != nil {
+			log.Printf("failed to send reminder to %d: %v", pref.ChatID, err)
+			continue
+		}
+		b.mu.Lock()
+		b.reminderSent[pref.ChatID] = now
+		b.mu.Unlock()
+	}
+}
+
+// maxReminderPauseDays is the longest /reminder pause.
+const maxReminderPauseDays = 365
+
+// remindersPaused reports whether /reminder pause holds back reminders at now.
+func (pref UserPreference) remindersPaused(now time.Time) bool {
+	return pref.PausedUntil != nil && now.Before(*pref.PausedUntil)
+}
+
+// NextReminderTime returns when the next reminder of pref is due after now,
+// and after its pause if any, in the user's timezone, or the zero time when
+// they have no reminder.
+func NextReminderTime(pref UserPreference, now time.Time) time.Time {
+	if pref.remindersPaused(now) {
+		now = *pref.PausedUntil
+	}
+	now = now.In(pref.location())
+	at := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), reminderHour, 0, 0, 0, day.Location())
+	}
+
+	switch pref.ReminderType {
+	case ReminderDaily:
+		next := at(now)
+		if !next.After(now) {
+			next = at(now.AddDate(0, 0, 1))
+		}
+		return next
+	case ReminderWeekly:
+		next := at(now.AddDate(0, 0, (7-int(now.Weekday()))%7))
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	case ReminderMonthly:
+		firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		next := at(firstOfMonth.AddDate(0, 1, -1))
+		if !next.After(now) {
+			next = at(firstOfMonth.AddDate(0, 2, -1))
+		}
+		return next
+	default:
+		return time.Time{}
+	}
+}
+
+// reminderHelp is the /help reminder page together with chatID's own
+// reminder settings and schedule.
+func (b *Bot) reminderHelp(chatID int64) string {
+	pref, ok := b.getPreference(chatID)
+	if !ok {
+		return commandHelp["reminder"]
+	}
+
+	b.mu.RLock()
+	sent, wasSent := b.reminderSent[chatID]
+	b.mu.RUnlock()
+
+	loc := pref.location()
+	lastSent := "belum pernah (sejak bot terakhir dijalankan)"
+	if wasSent {
+		lastSent = sent.In(loc).Format("02-01-2006 15:04")
+	}
+	next := "-"
+	if at := NextReminderTime(pref, time.Now()); !at.IsZero() {
+		next = at.Format("02-01-2006 15:04")
+	}
+	if pref.remindersPaused(time.Now()) {
+		next += fmt.Sprintf(" (dijeda sampai %s)", pref.PausedUntil.In(loc).Format("02-01-2006 15:04"))
+	}
+
+	return fmt.Sprintf("%s\n\n📋 Pengingat kamu:\n"+
+		"• Jenis: %s\n"+
+		"• Jam: %02d:00\n"+
+		"• Zona waktu: %s\n"+
+		"• Terakhir dikirim: %s\n"+
+		"• Berikutnya: %s",
+		commandHelp["reminder"], pref.ReminderType.label(), reminderHour, timezoneLabel(loc.String()), lastSent, next)
+}
+
+// sendReminder sends chatID the summary that goes with reminderType.
+func (b *Bot) sendReminder(ctx context.Context, chatID int64, reminderType ReminderType) error {
+	text, err := b.reminderText(ctx, chatID, reminderType)
+	if err != nil || text == "" {
+		return err
+	}
+	_, err = b.sendMessage(tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+// reminderText is the reminder of reminderType for chatID, or an empty
+// string for ReminderNone.
+func (b *Bot) reminderText(ctx context.Context, chatID int64, reminderType ReminderType) (string, error) {
+	pref, _ := b.getPreference(chatID)
+	now := time.Now().In(pref.location())
+
+	var text string
+	switch reminderType {
+	case ReminderDaily:
+		daily, err := b.getDailySummary(ctx, now)
+		if err != nil {
+			return "", err
+		}
+		text = "🔔 Pengingat harian: jangan lupa catat pengeluaranmu!\n\n" + formatDailySummary(daily)
+
+	case ReminderWeekly:
+		weekly, err := b.getWeeklySummary(ctx)
+		if err != nil {
+			return "", err
+		}
+		text = "🔔 Pengingat mingguan\n\n" + weekly
+
+	case ReminderMonthly:
+		monthly, err := b.getMonthlySummary(ctx)
+		if err != nil {
+			return "", err
+		}
+		text = "🔔 Pengingat bulanan\n\n" + monthly
+
+		savings, err := b.getMonthlySavings(ctx, chatID, now)
+		if err != nil {
+			return "", err
+		}
+		if savings > 0 {
+			expenses, err := b.getMonthlyTotal(ctx, now)
+			if err != nil {
+				return "", err
+			}
+			text += fmt.Sprintf("\n🐷 Tabungan bulan ini: Rp %s (tingkat tabungan %s)",
+				formatRupiah(savings), formatSavingsRate(savings, expenses))
+		}
+
+	default:
+		return "", nil
+	}
+	return text, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextReminderTime(t *testing.T) {
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, jakarta)
+	}
+	pausedUntil := at(time.October, 20, 12)
+
+	tests := []struct {
+		name string
+		pref UserPreference
+		now  time.Time
+		want time.Time
+	}{
+		{"daily before 20:00", UserPreference{ReminderType: ReminderDaily}, at(time.October, 16, 10), at(time.October, 16, 20)},
+		{"daily after 20:00", UserPreference{ReminderType: ReminderDaily}, at(time.October, 16, 21), at(time.October, 17, 20)},
+		{"weekly on a Sunday morning", UserPreference{ReminderType: ReminderWeekly}, at(time.October, 18, 9), at(time.October, 18, 20)},
+		{"weekly on a Sunday night", UserPreference{ReminderType: ReminderWeekly}, at(time.October, 18, 21), at(time.October, 25, 20)},
+		{"monthly on the last day", UserPreference{ReminderType: ReminderMonthly}, at(time.October, 31, 9), at(time.October, 31, 20)},
+		{"monthly after the last reminder", UserPreference{ReminderType: ReminderMonthly}, at(time.October, 31, 21), at(time.November, 30, 20)},
+		{"paused", UserPreference{ReminderType: ReminderDaily, PausedUntil: &pausedUntil}, at(time.October, 16, 10), at(time.October, 20, 20)},
+		{"none", UserPreference{ReminderType: ReminderNone}, at(time.October, 16, 10), time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := NextReminderTime(tt.pref, tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: NextReminderTime = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportTopEntries is how many of the biggest entries /report monthly lists.
+const reportTopEntries = 3
+
+// MonthlyReport is what /report monthly shows of a month.
+type MonthlyReport struct {
+	Month         time.Time
+	Total         int
+	Entries       int
+	Categories    []CategoryTotal // biggest first
+	TopEntries    []Row           // biggest first, at most reportTopEntries
+	DailyAverage  int             // over the days of the month so far
+	PreviousTotal int             // spending of the month before
+}
+
+// buildMonthlyReport reports the month of month from rows, which must cover
+// it and the month before.
+func buildMonthlyReport(rows []Row, month time.Time) MonthlyReport {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+	previous := start.AddDate(0, -1, 0)
+
+	report := MonthlyReport{Month: start}
+	totals := make(map[string]int)
+	var entries []Row
+	for _, row := range rows {
+		switch {
+		case !row.Date.Before(start) && row.Date.Before(end):
+			report.Total += row.Nominal
+			totals[row.Category] += row.Nominal
+			entries = append(entries, row)
+		case !row.Date.Before(previous) && row.Date.Before(start):
+			report.PreviousTotal += row.Nominal
+		}
+	}
+	report.Entries = len(entries)
+
+	for category, total := range totals {
+		report.Categories = append(report.Categories, CategoryTotal{Category: category, Total: total})
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		if report.Categories[i].Total != report.Categories[j].Total {
+			return report.Categories[i].Total > report.Categories[j].Total
+		}
+		return report.Categories[i].Category < report.Categories[j].Category
+	})
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Nominal > entries[j].Nominal })
+	report.TopEntries = entries[:min(len(entries), reportTopEntries)]
+
+	// The current month is averaged over the days gone by, not all of it
+	days := end.AddDate(0, 0, -1).Day()
+	if now := time.Now(); now.Before(end) && !now.Before(start) {
+		days = now.Day()
+	}
+	report.DailyAverage = report.Total / days
+	return report
+}
+
+func (r MonthlyReport) format() string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📑 Laporan %s %d\n\n", monthNames[r.Month.Month()-1], r.Month.Year()))
+	if r.Entries == 0 {
+		result.WriteString("Belum ada pengeluaran di bulan ini")
+		return result.String()
+	}
+
+	result.WriteString(fmt.Sprintf("💰 Total: Rp %s\n", formatRupiah(r.Total)))
+	result.WriteString(fmt.Sprintf("🧾 Jumlah transaksi: %d\n", r.Entries))
+	result.WriteString(fmt.Sprintf("📅 Rata-rata harian: Rp %s\n", formatRupiah(r.DailyAverage)))
+
+	delta := r.Total - r.PreviousTotal
+	switch {
+	case r.PreviousTotal == 0:
+		result.WriteString("📊 Bulan lalu: belum ada pengeluaran\n")
+	case delta > 0:
+		result.WriteString(fmt.Sprintf("📊 Bulan lalu: Rp %s (▲ Rp %s, %d%%)\n", formatRupiah(r.PreviousTotal), formatRupiah(delta), delta*100/r.PreviousTotal))
+	case delta < 0:
+		result.WriteString(fmt.Sprintf("📊 Bulan lalu: Rp %s (▼ Rp %s, %d%%)\n", formatRupiah(r.PreviousTotal), formatRupiah(-delta), -delta*100/r.PreviousTotal))
+	default:
+		result.WriteString(fmt.Sprintf("📊 Bulan lalu: Rp %s (=)\n", formatRupiah(r.PreviousTotal)))
+	}
+
+	result.WriteString("\n🎯 Per kategori:\n")
+	for _, category := range r.Categories {
+		result.WriteString(fmt.Sprintf("%s: Rp %s (%d%%)\n", category.Category, formatRupiah(category.Total), category.Total*100/r.Total))
+	}
+
+	result.WriteString("\n🏆 Pengeluaran terbesar:\n")
+	for i, row := range r.TopEntries {
+		result.WriteString(fmt.Sprintf("%d. Rp %s - %s - %s (%s)\n", i+1, formatRupiah(row.Nominal), row.Category, row.Description, row.Date.Format("02-01")))
+	}
+	return result.String()
+}
+
+// getMonthlyReport reports the month of month.
+func (b *Bot) getMonthlyReport(ctx context.Context, month time.Time) (MonthlyReport, error) {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	rows, err := b.getEntriesBetween(ctx, start.AddDate(0, -1, 0), start.AddDate(0, 1, -1))
+	if err != nil {
+		return MonthlyReport{}, fmt.Errorf("failed to get monthly report: %w", err)
+	}
+	return buildMonthlyReport(rows, start), nil
+}
+
+// summarySheet keeps a running history of monthly reports, one column per
+// month from column B, for reading and sharing the spreadsheet itself.
+// Column A labels the rows.
+const summarySheet = "Summary"
+
+var summaryLabels = []interface{}{"Bulan", "Total", "Transaksi", "Rata-rata harian", "Bulan lalu", "Per kategori"}
+
+// summaryColumn returns the column of month in the Summary tab, whose first
+// row is read in header: the column already titled with month, or else the
+// first free one.
+func summaryColumn(header []interface{}, month string) int {
+	for i := 1; i < len(header); i++ {
+		if fmt.Sprintf("%v", header[i]) == month {
+			return i
+		}
+	}
+	return max(1, len(header))
+}
+
+// writeMonthlySummaryToSheet writes report into its month's column of the
+// Summary tab, replacing what an earlier run wrote there, and returns the
+// letter of the column.
+func (b *Bot) writeMonthlySummaryToSheet(ctx context.Context, report MonthlyReport) (string, error) {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, summarySheet, summaryLabels[:1]); err != nil {
+		return "", err
+	}
+	rows, err := store.Get(sheetRange(summarySheet, "A1:ZZ1"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read summary: %w", err)
+	}
+	var header []interface{}
+	if len(rows) > 0 {
+		header = rows[0]
+	}
+
+	month := fmt.Sprintf("%s %d", monthNames[report.Month.Month()-1], report.Month.Year())
+	col := columnLetter(summaryColumn(header, month))
+
+	values := [][]interface{}{{month}, {report.Total}, {report.Entries}, {report.DailyAverage}, {report.PreviousTotal}, {""}}
+	for _, category := range report.Categories {
+		values = append(values, []interface{}{fmt.Sprintf("%s: Rp %s (%d%%)", category.Category, formatRupiah(category.Total), category.Total*100/max(1, report.Total))})
+	}
+
+	labels := make([][]interface{}, len(summaryLabels))
+	for i, label := range summaryLabels {
+		labels[i] = []interface{}{label}
+	}
+	if err := store.Clear(sheetRange(summarySheet, col+":"+col)); err != nil {
+		return "", fmt.Errorf("failed to clear summary column %s: %w", col, err)
+	}
+	err = store.BatchUpdate([]RangeValues{
+		{Range: sheetRange(summarySheet, "A1"), Values: labels},
+		{Range: sheetRange(summarySheet, col+"1"), Values: values},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to write summary: %w", err)
+	}
+	return col, nil
+}
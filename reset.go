@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resetCodeTTL is how long a /reset confirmation code stays valid.
+const resetCodeTTL = 2 * time.Minute
+
+// pendingReset is a /reset waiting for the user to send back its code.
+type pendingReset struct {
+	code    string
+	expires time.Time
+}
+
+// startReset returns the code chatID must send back to confirm /reset. It
+// is drawn from crypto/rand, since it guards deleting all of their data.
+func (b *Bot) startReset(chatID int64) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate reset code: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n)
+	b.mu.Lock()
+	b.pendingResets[chatID] = pendingReset{code: code, expires: time.Now().Add(resetCodeTTL)}
+	b.mu.Unlock()
+	return code, nil
+}
+
+// takePendingReset returns and forgets the pending /reset of chatID, if it
+// has not expired.
+func (b *Bot) takePendingReset(chatID int64) (pendingReset, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	reset, ok := b.pendingResets[chatID]
+	delete(b.pendingResets, chatID)
+	return reset, ok && time.Now().Before(reset.expires)
+}
+
+// clearRows clears the rows of a tab for which match returns true and
+// returns how many were cleared. Row 1 is the header and is kept.
+func (b *Bot) clearRows(ctx context.Context, title, lastCol string, match func(row []interface{}) bool) (int, error) {
+	store := storeWithContext(ctx, b.store)
+	rows, err := store.Get(b.tabRange(title, "A:"+lastCol))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sheet %q: %w", title, err)
+	}
+
+	var updates []RangeValues
+	for i, row := range rows {
+		if i == 0 || !match(row) {
+			continue
+		}
+		cells := b.tabRange(title, fmt.Sprintf("A%d", i+1))
+
+		// Empty strings clear the cells, so all rows go in one call instead
+		// of a Clear per row.
+		blank := make([]interface{}, len(row))
+		for j := range blank {
+			blank[j] = ""
+		}
+		updates = append(updates, RangeValues{Range: cells, Values: [][]interface{}{blank}})
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	if err := store.BatchUpdate(updates); err != nil {
+		return 0, fmt.Errorf("failed to clear rows of sheet %q: %w", title, err)
+	}
+	return len(updates), nil
+}
+
+// resetUser deletes everything stored for chatID: the entries recorded from
+// their messages, the entries of their accounts, their entries in joint
+// sheets, their savings, pins, links, aliases, templates, pending copies,
+// installments, tax rates, goals, preferences and audit log, and all
+// in-memory state. It returns how many entries were deleted.
+func (b *Bot) resetUser(ctx context.Context, chatID int64) (int, error) {
+	id := strconv.FormatInt(chatID, 10)
+	ownedByChat := func(row []interface{}) bool {
+		return len(row) > 0 && fmt.Sprintf("%v", row[0]) == id
+	}
+
+	entries, err := b.clearRows(ctx, "", b.sheetConfig.lastColumn(), entryOwnedBy(chatID))
+	if err != nil {
+		return 0, err
+	}
+
+	// Account tabs belong to the chat as a whole
+	accounts, err := b.getAccounts(ctx, chatID)
+	if err != nil {
+		return entries, err
+	}
+	titles, err := storeWithContext(ctx, b.store).SheetTitles()
+	if err != nil {
+		return entries, fmt.Errorf("failed to get sheet titles: %w", err)
+	}
+	for _, account := range accounts[1:] {
+		title := accountSheet(chatID, account)
+		if !slices.Contains(titles, title) {
+			continue
+		}
+		cleared, err := b.clearRows(ctx, title, b.sheetConfig.lastColumn(), func(row []interface{}) bool {
+			return len(row) > 0
+		})
+		if err != nil {
+			return entries, err
+		}
+		entries += cleared
+	}
+
+	// Joint sheets hold the entries of every chat sharing them, so only
+	// the chat's own entries are cleared, in the current and earlier ones
+	for _, title := range titles {
+		if !strings.HasPrefix(title, jointSheetPrefix) {
+			continue
+		}
+		cleared, err := b.clearRows(ctx, title, b.sheetConfig.lastColumn(), entryOwnedBy(chatID))
+		if err != nil {
+			return entries, err
+		}
+		entries += cleared
+	}
+
+	tabs := []struct {
+		title, lastCol string
+		header         []interface{}
+	}{
+		{savingsSheet, "D", savingsHeader},
+		{pinnedSheet, "D", pinnedHeader},
+		{linksSheet, "F", linksHeader},
+		{aliasesSheet, "C", aliasesHeader},
+		{templatesSheet, "E", templatesHeader},
+		{pendingSheet, "E", pendingHeader},
+		{recurringSheet, "G", recurringHeader},
+		{taxConfigSheet, "C", taxConfigHeader},
+		{goalsSheet, "C", goalsHeader},
+		{preferencesSheet, "Z", preferencesHeader},
+	}
+	for _, tab := range tabs {
+		if err := b.ensureTab(ctx, tab.title, tab.header); err != nil {
+			return entries, err
+		}
+		if _, err := b.clearRows(ctx, tab.title, tab.lastCol, ownedByChat); err != nil {
+			return entries, err
+		}
+	}
+
+	// The audit log has the chat ID in its second column
+	if err := b.ensureTab(ctx, auditSheet, auditHeader); err != nil {
+		return entries, err
+	}
+	if _, err := b.clearRows(ctx, auditSheet, "F", func(row []interface{}) bool {
+		return len(row) > 1 && fmt.Sprintf("%v", row[1]) == id
+	}); err != nil {
+		return entries, err
+	}
+
+	b.mu.Lock()
+	delete(b.prefs, chatID)
+	delete(b.editingState, chatID)
+	delete(b.editStats, chatID)
+	delete(b.pendingMerge, chatID)
+	delete(b.pendingClear, chatID)
+	delete(b.pendingDeleteRange, chatID)
+	delete(b.conversationStates, chatID)
+	delete(b.pendingExpense, chatID)
+	delete(b.pendingConversions, chatID)
+	delete(b.pendingMigrations, chatID)
+	delete(b.pendingVoice, chatID)
+	delete(b.pendingCheckin, chatID)
+	delete(b.pendingSubscribe, chatID)
+	for target, request := range b.pendingSubscribe {
+		if request.from == chatID {
+			delete(b.pendingSubscribe, target)
+		}
+	}
+	delete(b.lastRecorded, chatID)
+	delete(b.limitWarnings, chatID)
+	delete(b.goalReportMonth, chatID)
+	delete(b.weeklyReportDay, chatID)
+	delete(b.reportEmailMonth, chatID)
+	delete(b.reminderSent, chatID)
+	delete(b.messageRowMap, chatID)
+	if b.aliases != nil {
+		delete(b.aliases, chatID)
+	}
+	b.mu.Unlock()
+
+	b.historyMu.Lock()
+	delete(b.operationHistory, chatID)
+	b.historyMu.Unlock()
+
+	return entries, nil
+}
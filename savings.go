@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const savingsSheet = "Savings"
+
+var savingsHeader = []interface{}{"ChatID", "Tanggal", "Nominal", "Catatan"}
+
+func (b *Bot) appendSaving(ctx context.Context, chatID int64, nominal int, note string) error {
+	if err := b.ensureTab(ctx, savingsSheet, savingsHeader); err != nil {
+		return err
+	}
+
+	currentDate := time.Now().Format("02-01-2006")
+	values := [][]interface{}{{strconv.FormatInt(chatID, 10), currentDate, nominal, note}}
+	return storeWithContext(ctx, b.store).Append(sheetRange(savingsSheet, "A1"), values)
+}
+
+// getMonthlySavings sums the savings recorded by chatID in the month of date.
+func (b *Bot) getMonthlySavings(ctx context.Context, chatID int64, date time.Time) (int, error) {
+	if err := b.ensureTab(ctx, savingsSheet, savingsHeader); err != nil {
+		return 0, err
+	}
+
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(savingsSheet, "A:D"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get savings: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	total := 0
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 { // Skip header
+			continue
+		}
+		if fmt.Sprintf("%v", row[0]) != id {
+			continue
+		}
+
+		rowDate, err := time.Parse("02-01-2006", normalizeDateString(fmt.Sprintf("%v", row[1])))
+		if err != nil || rowDate.Year() != date.Year() || rowDate.Month() != date.Month() {
+			continue
+		}
+
+		nominal, _ := strconv.Atoi(fmt.Sprintf("%v", row[2]))
+		total += nominal
+	}
+	return total, nil
+}
+
+// getSavingsStatus reports this month's savings, expenses and savings rate.
+func (b *Bot) getSavingsStatus(ctx context.Context, chatID int64) (string, error) {
+	now := time.Now()
+	savings, err := b.getMonthlySavings(ctx, chatID, now)
+	if err != nil {
+		return "", err
+	}
+	expenses, err := b.getMonthlyTotal(ctx, now)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("🐷 Tabungan Bulan Ini:\n\n💰 Total tabungan: Rp %s\n💸 Total pengeluaran: Rp %s\n📈 Tingkat tabungan: %s",
+		formatRupiah(savings), formatRupiah(expenses), formatSavingsRate(savings, expenses)), nil
+}
+
+func formatSavingsRate(savings, expenses int) string {
+	if savings+expenses == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(savings)/float64(savings+expenses)*100)
+}
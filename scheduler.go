@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// untilNextMinute returns how long it is from now to the start of the next
+// minute.
+func untilNextMinute(now time.Time) time.Duration {
+	return now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+}
+
+// startScheduler runs the bot's periodic jobs at the start of every minute
+// until ctx is done. The timer is set again after each run, from the clock
+// rather than from a fixed interval, so the jobs stay within a second of the
+// minute however long they take.
+func (b *Bot) startScheduler(ctx context.Context) {
+	timer := time.NewTimer(untilNextMinute(time.Now()))
+	go func() {
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-timer.C:
+				b.runScheduledJobs(ctx, now.Truncate(time.Minute))
+				timer.Reset(untilNextMinute(time.Now()))
+			}
+		}
+	}()
+}
+
+func (b *Bot) runScheduledJobs(ctx context.Context, now time.Time) {
+	if now.Hour() == 0 && now.Minute() == 0 && b.backupStore != nil {
+		rows, err := backupToSheet(storeWithContext(ctx, b.store), storeWithContext(ctx, b.backupStore))
+		if err != nil {
+			log.Printf("scheduled backup failed: %v", err)
+		} else {
+			log.Printf("scheduled backup done: %d rows", rows)
+		}
+	}
+
+	if now.Hour() == 0 && now.Minute() == 0 {
+		b.runInstallmentJob(ctx, now)
+	}
+
+	b.sendDueReminders(ctx, now)
+	b.sendDueDigests(ctx, now)
+	b.sendGoalReports(ctx, now)
+	b.sendWeeklyReports(ctx, now)
+	b.sendReportEmails(ctx, now)
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metaSheet records which schema migrations have been applied to the store.
+const metaSheet = "_Meta"
+
+var metaHeader = []interface{}{"ID", "Migrasi", "Diterapkan"}
+
+// Migration brings an existing spreadsheet up to date with a change in its
+// layout, such as a column added by a new feature.
+type Migration struct {
+	ID          int
+	Description string
+	Apply       func(store SheetStore) error
+}
+
+// migrations are applied in order. New ones go at the end with the next ID
+// and must not change once released.
+var migrations = []Migration{
+	{
+		ID:          1,
+		Description: "Tambah kolom Pesan, Struk, Pengirim dan Lokasi di tab utama",
+		Apply: func(store SheetStore) error {
+			return extendHeader(store, "", expenseHeader)
+		},
+	},
+	{
+		ID:          2,
+		Description: "Tambah kolom Digest, WeeklyReport, WeeklyLimit, PausedUntil, ReportEmail, Account dan JointSheet di tab Preferences",
+		Apply: func(store SheetStore) error {
+			return extendHeader(store, preferencesSheet, preferencesHeader)
+		},
+	},
+	{
+		ID:          3,
+		Description: "Tambah named range EXPENSE_DATA dan PREFERENCE_DATA",
+		Apply:       addNamedRanges,
+	},
+}
+
+// extendHeader adds the columns of header missing at the end of the header
+// row of the tab title, the main tab when empty. Existing column names are
+// kept, since they may have been renamed by hand. Tabs that do not exist yet
+// get the full header when they are first used.
+func extendHeader(store SheetStore, title string, header []interface{}) error {
+	cells := func(cells string) string {
+		if title == "" {
+			return cells
+		}
+		return sheetRange(title, cells)
+	}
+	if title != "" {
+		titles, err := store.SheetTitles()
+		if err != nil {
+			return fmt.Errorf("failed to get sheet titles: %w", err)
+		}
+		if !slices.Contains(titles, title) {
+			return nil
+		}
+	}
+
+	rows, err := store.Get(cells("A1:Z1"))
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	if len(rows) == 0 || len(rows[0]) >= len(header) {
+		return nil
+	}
+	missing := header[len(rows[0]):]
+	if err := store.Update(cells(columnLetter(len(rows[0]))+"1"), [][]interface{}{missing}); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	return nil
+}
+
+// lastMigrationID returns the highest migration ID recorded in the _Meta
+// tab, creating the tab when it is missing.
+func lastMigrationID(store SheetStore) (int, error) {
+	if err := store.EnsureSheet(metaSheet); err != nil {
+		return 0, fmt.Errorf("failed to create sheet %q: %w", metaSheet, err)
+	}
+	rows, err := store.Get(sheetRange(metaSheet, "A:A"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	if len(rows) == 0 {
+		if err := store.Update(sheetRange(metaSheet, "A1"), [][]interface{}{metaHeader}); err != nil {
+			return 0, fmt.Errorf("failed to write header of sheet %q: %w", metaSheet, err)
+		}
+	}
+
+	last := 0
+	for i, row := range rows {
+		if i == 0 || len(row) == 0 {
+			continue
+		}
+		if id, err := strconv.Atoi(fmt.Sprintf("%v", row[0])); err == nil {
+			last = max(last, id)
+		}
+	}
+	return last, nil
+}
+
+// runMigrations applies the migrations newer than the last recorded one and
+// records each in the _Meta tab. It stops at the first that fails, so that
+// it is tried again on the next run, and returns how many were applied.
+func runMigrations(store SheetStore) (int, error) {
+	last, err := lastMigrationID(store)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, migration := range migrations {
+		if migration.ID <= last {
+			continue
+		}
+		if err := migration.Apply(store); err != nil {
+			return applied, fmt.Errorf("failed to apply migration %d (%s): %w", migration.ID, migration.Description, err)
+		}
+		record := [][]interface{}{{migration.ID, migration.Description, time.Now().Format("02-01-2006 15:04")}}
+		if err := store.Append(sheetRange(metaSheet, "A1"), record); err != nil {
+			return applied, fmt.Errorf("failed to record migration %d: %w", migration.ID, err)
+		}
+		log.Printf("Applied migration %d: %s", migration.ID, migration.Description)
+		applied++
+	}
+	return applied, nil
+}
+
+// schemaTabs are the tabs validateSheetSchema checks besides the expense tab,
+// with the header each starts with.
+var schemaTabs = []struct {
+	title  string
+	header []interface{}
+}{
+	{preferencesSheet, preferencesHeader},
+	{sheetConfigSheet, sheetConfigHeader},
+	{savingsSheet, savingsHeader},
+	{pinnedSheet, pinnedHeader},
+	{linksSheet, linksHeader},
+	{aliasesSheet, aliasesHeader},
+	{templatesSheet, templatesHeader},
+	{pendingSheet, pendingHeader},
+	{recurringSheet, recurringHeader},
+	{taxConfigSheet, taxConfigHeader},
+	{goalsSheet, goalsHeader},
+	{auditSheet, auditHeader},
+}
+
+// errHeaderMissing and errHeaderMismatch tell apart the layout problems
+// found by validateSheetSchema. A missing header means the tab was not laid
+// out by the bot, while a mismatched one may have been renamed on purpose.
+var (
+	errHeaderMissing  = errors.New("spreadsheet header is missing")
+	errHeaderMismatch = errors.New("spreadsheet header does not match")
+)
+
+// validateSheetSchema checks the header row of the expense tab against the
+// column layout in sheetConfig, and of every other tab that exists against
+// the header the bot writes, so that a spreadsheet laid out differently is
+// reported before entries are written to the wrong columns. The error lists
+// every missing or mismatched header and wraps errHeaderMissing when any
+// header is missing, errHeaderMismatch otherwise. Tabs the bot has not
+// created yet are skipped.
+func (b *Bot) validateSheetSchema(ctx context.Context) error {
+	titles, err := storeWithContext(ctx, b.store).SheetTitles()
+	if err != nil {
+		return fmt.Errorf("failed to get sheet titles: %w", err)
+	}
+
+	expected := maps.Clone(fixedColumns)
+	config := b.sheetConfig
+	for _, field := range config.fields() {
+		expected[columnIndex(*field.col)] = field.name
+	}
+	missing, mismatched, err := b.headerProblems(ctx, "", "expense tab", expected)
+	if err != nil {
+		return err
+	}
+
+	for _, tab := range schemaTabs {
+		_, named := namedRangeTabs[tab.title]
+		if !named && !slices.Contains(titles, tab.title) {
+			continue
+		}
+		expected := make(map[int]string, len(tab.header))
+		for i, name := range tab.header {
+			expected[i] = fmt.Sprintf("%v", name)
+		}
+		tabMissing, tabMismatched, err := b.headerProblems(ctx, tab.title, fmt.Sprintf("tab %q", tab.title), expected)
+		if err != nil {
+			return err
+		}
+		missing = append(missing, tabMissing...)
+		mismatched = append(mismatched, tabMismatched...)
+	}
+
+	problems := append(missing, mismatched...)
+	switch {
+	case len(missing) > 0:
+		return fmt.Errorf("%w:\n%s", errHeaderMissing, strings.Join(problems, "\n"))
+	case len(mismatched) > 0:
+		return fmt.Errorf("%w:\n%s", errHeaderMismatch, strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// headerProblems compares the header row of the tab title with the column
+// names expected by column index, ignoring case. It returns the missing
+// headers and the mismatched ones apart.
+func (b *Bot) headerProblems(ctx context.Context, title, label string, expected map[int]string) (missing, mismatched []string, err error) {
+	rows, err := storeWithContext(ctx, b.store).Get(b.tabRange(title, "A1:Z1"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header of %s: %w", label, err)
+	}
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return []string{fmt.Sprintf("%s: header row is missing", label)}, nil, nil
+	}
+
+	header := rows[0]
+	for _, col := range slices.Sorted(maps.Keys(expected)) {
+		want := expected[col]
+		got := ""
+		if col < len(header) {
+			got = strings.TrimSpace(fmt.Sprintf("%v", header[col]))
+		}
+		switch {
+		case got == "":
+			missing = append(missing, fmt.Sprintf("%s: column %s is missing, expected %q", label, columnLetter(col), want))
+		case !strings.EqualFold(got, want):
+			mismatched = append(mismatched, fmt.Sprintf("%s: column %s is %q, expected %q", label, columnLetter(col), got, want))
+		}
+	}
+	return missing, mismatched, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateSheetSchema(t *testing.T) {
+	b, _ := newTestBot(t)
+	if _, err := runMigrations(b.store); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.validateSheetSchema(context.Background()); err != nil {
+		t.Fatalf("validateSheetSchema() = %v, want nil", err)
+	}
+
+	// A renamed column is only a mismatch
+	renamed := append([]interface{}{}, expenseHeader...)
+	renamed[1] = "Tgl"
+	if err := b.store.Update("A1", [][]interface{}{renamed}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.validateSheetSchema(context.Background()); !errors.Is(err, errHeaderMismatch) {
+		t.Errorf("validateSheetSchema() = %v, want %v", err, errHeaderMismatch)
+	}
+
+	// A tab without a header is missing it
+	if err := b.store.Clear("A1:Z1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.validateSheetSchema(context.Background()); !errors.Is(err, errHeaderMissing) {
+		t.Errorf("validateSheetSchema() = %v, want %v", err, errHeaderMissing)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	sendAttempts       = 3
+	sendRetryDelay     = 2 * time.Second
+	deadLetterCapacity = 100 // failed messages kept, the oldest are dropped beyond it
+	deadLetterInterval = 30 * time.Second
+)
+
+// FailedMessage is a message that could not be sent after every retry,
+// waiting in the dead-letter queue to be sent again.
+type FailedMessage struct {
+	Msg      tgbotapi.Chattable
+	Err      error
+	FailedAt time.Time
+}
+
+// retryableSendError reports whether sending again may succeed. Telegram
+// rejecting the message itself, e.g. a blocked bot or an unchanged edit,
+// fails the same way every time.
+func retryableSendError(err error) bool {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// sendMessage sends msg, retrying up to sendAttempts times when Telegram
+// cannot be reached. A message that still fails goes to the dead-letter
+// queue so it is not lost.
+func (b *Bot) sendMessage(msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var sent tgbotapi.Message
+	var err error
+	for attempt := 1; attempt <= sendAttempts; attempt++ {
+		sent, err = sendWithRateLimit(b.api, msg)
+		if err == nil || !retryableSendError(err) {
+			return sent, err
+		}
+		if attempt < sendAttempts {
+			time.Sleep(sendRetryDelay)
+		}
+	}
+
+	log.Printf("failed to send message after %d attempts, queued for later: %v", sendAttempts, err)
+	b.pushFailedMessage(FailedMessage{Msg: msg, Err: err, FailedAt: time.Now()})
+	return sent, err
+}
+
+func (b *Bot) pushFailedMessage(failed FailedMessage) {
+	b.deadLetterMu.Lock()
+	defer b.deadLetterMu.Unlock()
+	if len(b.failedMessages) >= deadLetterCapacity {
+		log.Printf("dead-letter queue full, dropping message that failed at %v", b.failedMessages[0].FailedAt)
+		b.failedMessages = b.failedMessages[1:]
+	}
+	b.failedMessages = append(b.failedMessages, failed)
+}
+
+// startDeadLetterQueue tries to send the failed messages again every
+// deadLetterInterval, once each. Messages that fail again stay queued.
+func (b *Bot) startDeadLetterQueue() {
+	go func() {
+		for range time.Tick(deadLetterInterval) {
+			b.deadLetterMu.Lock()
+			queued := b.failedMessages
+			b.failedMessages = nil
+			b.deadLetterMu.Unlock()
+
+			for _, failed := range queued {
+				_, err := sendWithRateLimit(b.api, failed.Msg)
+				switch {
+				case err == nil:
+				case retryableSendError(err):
+					failed.Err = err
+					b.pushFailedMessage(failed)
+				default:
+					log.Printf("dropping queued message that failed at %v: %v", failed.FailedAt, err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sort"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// shareCardSize is the width and height of the /share card, the square
+// format social media crops least.
+const shareCardSize = 1080
+
+// shareTopCategories is how many categories the card lists.
+const shareTopCategories = 5
+
+var monthNames = []string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// CategoryTotal is the spending of one category.
+type CategoryTotal struct {
+	Category string
+	Total    int
+}
+
+// MonthlySummary is what the /share card shows of a month. It holds no
+// user details on purpose.
+type MonthlySummary struct {
+	Month         time.Time
+	Total         int
+	TopCategories []CategoryTotal // biggest first, at most shareTopCategories
+}
+
+func (b *Bot) getMonthlyShareSummary(ctx context.Context, date time.Time) (MonthlySummary, error) {
+	totals, err := b.getCategoryTotals(ctx, date)
+	if err != nil {
+		return MonthlySummary{}, err
+	}
+
+	summary := MonthlySummary{Month: date}
+	for category, total := range totals {
+		if total <= 0 {
+			continue
+		}
+		summary.Total += total
+		summary.TopCategories = append(summary.TopCategories, CategoryTotal{Category: category, Total: total})
+	}
+	sort.Slice(summary.TopCategories, func(i, j int) bool {
+		return summary.TopCategories[i].Total > summary.TopCategories[j].Total
+	})
+	if len(summary.TopCategories) > shareTopCategories {
+		summary.TopCategories = summary.TopCategories[:shareTopCategories]
+	}
+	return summary, nil
+}
+
+var (
+	shareBackground = color.RGBA{0x12, 0x3c, 0x4a, 0xff}
+	shareAccent     = color.RGBA{0xf2, 0xb8, 0x3b, 0xff}
+	shareBar        = color.RGBA{0x2a, 0x6f, 0x82, 0xff}
+	shareText       = color.White
+	shareMuted      = color.RGBA{0xb8, 0xd0, 0xd6, 0xff}
+)
+
+func shareFace(ttf []byte, size float64) (font.Face, error) {
+	parsed, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font: %w", err)
+	}
+	return face, nil
+}
+
+// renderSummaryCard draws summary as a square PNG card with the bot's
+// branding, the month, its total and its top categories.
+func renderSummaryCard(summary MonthlySummary) ([]byte, error) {
+	title, err := shareFace(gobold.TTF, 96)
+	if err != nil {
+		return nil, err
+	}
+	heading, err := shareFace(gobold.TTF, 48)
+	if err != nil {
+		return nil, err
+	}
+	body, err := shareFace(goregular.TTF, 40)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, shareCardSize, shareCardSize))
+	draw.Draw(img, img.Bounds(), &image.Uniform{shareBackground}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, shareCardSize, 16), &image.Uniform{shareAccent}, image.Point{}, draw.Src)
+
+	const margin = 80
+	text := func(face font.Face, c color.Color, x, y int, s string) {
+		d := &font.Drawer{Dst: img, Src: &image.Uniform{c}, Face: face, Dot: fixed.P(x, y)}
+		d.DrawString(s)
+	}
+	rightAligned := func(face font.Face, c color.Color, y int, s string) {
+		width := font.MeasureString(face, s).Ceil()
+		text(face, c, shareCardSize-margin-width, y, s)
+	}
+
+	text(heading, shareAccent, margin, 130, "ChatKeu")
+	text(body, shareMuted, margin, 220, fmt.Sprintf("Pengeluaran %s %d", monthNames[summary.Month.Month()-1], summary.Month.Year()))
+	text(title, shareText, margin, 340, "Rp "+formatRupiah(summary.Total))
+
+	text(heading, shareText, margin, 480, "Kategori Terbesar")
+	y := 560
+	for _, category := range summary.TopCategories {
+		share := 0
+		if summary.Total > 0 {
+			share = category.Total * 100 / summary.Total
+		}
+		barWidth := (shareCardSize - 2*margin) * share / 100
+		draw.Draw(img, image.Rect(margin, y+16, margin+barWidth, y+28), &image.Uniform{shareBar}, image.Point{}, draw.Src)
+
+		text(body, shareText, margin, y, category.Category)
+		rightAligned(body, shareMuted, y, fmt.Sprintf("Rp %s (%d%%)", formatRupiah(category.Total), share))
+		y += 90
+	}
+
+	text(body, shareMuted, margin, shareCardSize-50, "Dicatat dengan bot pencatat keuangan ChatKeu")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode summary card: %w", err)
+	}
+	return buf.Bytes(), nil
+}
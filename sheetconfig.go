@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// sheetConfigSheet holds the column layout of the expense tab, so that
+// spreadsheets kept by hand before the bot can be used as they are.
+const sheetConfigSheet = "Config"
+
+var sheetConfigHeader = []interface{}{"Kolom", "Huruf"}
+
+// Columns of the expense tab that always keep their place, see expenseHeader.
+const (
+	refColumn      = 5 // F, Pesan
+	receiptColumn  = 6 // G, Struk
+	senderColumn   = 7 // H, Pengirim
+	locationColumn = 8 // I, Lokasi
+)
+
+// fixedColumns names the columns that always keep their place, as they are
+// titled in expenseHeader.
+var fixedColumns = map[int]string{
+	refColumn:      "Pesan",
+	receiptColumn:  "Struk",
+	senderColumn:   "Pengirim",
+	locationColumn: "Lokasi",
+}
+
+// SheetConfig is the column, as a letter, of each field of an expense entry.
+type SheetConfig struct {
+	RowNumCol      string
+	DateCol        string
+	NominalCol     string
+	CategoryCol    string
+	DescriptionCol string
+}
+
+// defaultSheetConfig is the layout of expenseHeader, used by sheets the bot
+// created itself.
+var defaultSheetConfig = SheetConfig{
+	RowNumCol:      "A",
+	DateCol:        "B",
+	NominalCol:     "C",
+	CategoryCol:    "D",
+	DescriptionCol: "E",
+}
+
+// fields pairs each column of the config with its name in the Config tab.
+func (c *SheetConfig) fields() []struct {
+	name string
+	col  *string
+} {
+	return []struct {
+		name string
+		col  *string
+	}{
+		{"No", &c.RowNumCol},
+		{"Tanggal", &c.DateCol},
+		{"Nominal", &c.NominalCol},
+		{"Kategori", &c.CategoryCol},
+		{"Keterangan", &c.DescriptionCol},
+	}
+}
+
+// validate checks that every column is a letter and that no two fields, the
+// fixed columns included, share a column.
+func (c SheetConfig) validate() error {
+	used := maps.Clone(fixedColumns)
+	for _, field := range c.fields() {
+		col, row, err := parseA1Cell(*field.col)
+		if err != nil || row != -1 {
+			return fmt.Errorf("invalid column %q for %s", *field.col, field.name)
+		}
+		if other, ok := used[col]; ok {
+			return fmt.Errorf("%s and %s both use column %s", other, field.name, *field.col)
+		}
+		used[col] = field.name
+	}
+	return nil
+}
+
+// columnIndex returns the zero-based index of the column letter col, which
+// validate has checked.
+func columnIndex(col string) int {
+	index, _, _ := parseA1Cell(col)
+	return index
+}
+
+// columnLetter is the inverse of columnIndex.
+func columnLetter(index int) string {
+	letters := ""
+	for index >= 0 {
+		letters = string(rune('A'+index%26)) + letters
+		index = index/26 - 1
+	}
+	return letters
+}
+
+// width is the number of columns an expense row spans.
+func (c SheetConfig) width() int {
+	width := locationColumn + 1
+	for _, field := range c.fields() {
+		width = max(width, columnIndex(*field.col)+1)
+	}
+	return width
+}
+
+// lastColumn is the letter of the last column an expense row spans.
+func (c SheetConfig) lastColumn() string {
+	return columnLetter(c.width() - 1)
+}
+
+// rowRange is the range of the whole expense row rowNumber.
+func (c SheetConfig) rowRange(rowNumber int) string {
+	return fmt.Sprintf("A%d:%s%d", rowNumber, c.lastColumn(), rowNumber)
+}
+
+// cell is the range of column col of the expense row rowNumber.
+func (c SheetConfig) cell(col string, rowNumber int) string {
+	return fmt.Sprintf("%s%d", col, rowNumber)
+}
+
+// newRow lays out an entry as a row of the expense tab starting at column A.
+func (c SheetConfig) newRow(rowNumber int, date string, nominal int, category, description, ref, sender string) []interface{} {
+	row := make([]interface{}, c.width())
+	for i := range row {
+		row[i] = ""
+	}
+	row[columnIndex(c.RowNumCol)] = rowNumber
+	row[columnIndex(c.DateCol)] = date
+	row[columnIndex(c.NominalCol)] = nominal
+	row[columnIndex(c.CategoryCol)] = category
+	row[columnIndex(c.DescriptionCol)] = description
+	row[refColumn] = ref
+	row[senderColumn] = sender
+	return trimEmptyCells(row)
+}
+
+// loadSheetConfig reads the column layout from the Config tab, writing the
+// default layout there when the tab is new so that it can be edited. An
+// invalid layout is reported and the default is used instead.
+func (b *Bot) loadSheetConfig(ctx context.Context) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, sheetConfigSheet, sheetConfigHeader); err != nil {
+		return err
+	}
+	rows, err := store.Get(sheetRange(sheetConfigSheet, "A:B"))
+	if err != nil {
+		return fmt.Errorf("failed to get sheet config: %w", err)
+	}
+
+	config := defaultSheetConfig
+	if len(rows) < 2 {
+		var values [][]interface{}
+		for _, field := range config.fields() {
+			values = append(values, []interface{}{field.name, *field.col})
+		}
+		if err := store.Append(sheetRange(sheetConfigSheet, "A1"), values); err != nil {
+			return fmt.Errorf("failed to write sheet config: %w", err)
+		}
+		return nil
+	}
+
+	for i, row := range rows {
+		if i == 0 || len(row) < 2 { // Skip header
+			continue
+		}
+		name := strings.TrimSpace(fmt.Sprintf("%v", row[0]))
+		for _, field := range config.fields() {
+			if strings.EqualFold(name, field.name) {
+				*field.col = strings.ToUpper(strings.TrimSpace(fmt.Sprintf("%v", row[1])))
+			}
+		}
+	}
+	if err := config.validate(); err != nil {
+		return fmt.Errorf("invalid sheet config, using the default columns: %w", err)
+	}
+	b.sheetConfig = config
+	return nil
+}
@@ -0,0 +1,598 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore keeps the sheet tabs in a local SQLite database, for
+// self-hosters who would rather not set up a Google service account. Every
+// tab is stored in its own table with a column per sheet column, named after
+// its header, and a row per sheet row keyed by idx, the zero-based row
+// number. The main data tab lives in "expenses" and the preferences in
+// "preferences", and the "categories" view totals the expenses by category.
+// All tabs are kept in memory as well, and every write saves the rows it
+// changed.
+type SQLiteStore struct {
+	db  *sql.DB
+	mem MemorySheetStore
+
+	// mu is held by every write from changing the rows in memory until they
+	// are committed, so the database gets the writes in the same order and
+	// never goes back to older rows.
+	mu     sync.Mutex
+	tables map[string]string // table of every tab, by title
+}
+
+var nonWordPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// reservedTables are the tables of the store itself, which tabs other than
+// the main one cannot take.
+var reservedTables = []string{"expenses", "categories", "sheets", "named_ranges"}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+
+	s := &SQLiteStore{db: db, tables: make(map[string]string)}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS sheets (title TEXT PRIMARY KEY, table_name TEXT NOT NULL UNIQUE)`); err != nil {
+		return nil, fmt.Errorf("failed to create sheets table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS named_ranges (name TEXT PRIMARY KEY, a1 TEXT NOT NULL)`); err != nil {
+		return nil, fmt.Errorf("failed to create named ranges table: %w", err)
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.EnsureSheet(""); err != nil {
+		return nil, err
+	}
+
+	// A new database gets the same header row a prepared spreadsheet has.
+	rows, err := s.Get("A1:E1")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		if err := s.Update("A1", [][]interface{}{expenseHeader}); err != nil {
+			return nil, fmt.Errorf("failed to write expenses header: %w", err)
+		}
+	}
+	if err := s.createCategoriesView(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newTableName picks the table of a new tab: "expenses" for the main tab,
+// otherwise the title in lower case with the other characters turned into
+// "_", numbered when another tab already has that table.
+func (s *SQLiteStore) newTableName(title string) string {
+	if title == "" {
+		return "expenses"
+	}
+	base := strings.Trim(nonWordPattern.ReplaceAllString(strings.ToLower(title), "_"), "_")
+	if base == "" || strings.HasPrefix(base, "sqlite_") {
+		base = "sheet_" + base
+	}
+
+	taken := func(name string) bool {
+		if slices.Contains(reservedTables, name) {
+			return true
+		}
+		for _, table := range s.tables {
+			if table == name {
+				return true
+			}
+		}
+		return false
+	}
+	name := strings.TrimSuffix(base, "_")
+	for n := 2; taken(name); n++ {
+		name = fmt.Sprintf("%s_%d", strings.TrimSuffix(base, "_"), n)
+	}
+	return name
+}
+
+func (s *SQLiteStore) load() error {
+	registry, err := s.db.Query(`SELECT title, table_name FROM sheets`)
+	if err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+	for registry.Next() {
+		var title, table string
+		if err := registry.Scan(&title, &table); err != nil {
+			registry.Close()
+			return err
+		}
+		s.tables[title] = table
+	}
+	registry.Close()
+	if err := registry.Err(); err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	for title, table := range s.tables {
+		grid, err := s.loadTable(table)
+		if err != nil {
+			return err
+		}
+		s.mem.write(title, 0, 0, grid)
+	}
+
+	named, err := s.db.Query(`SELECT name, a1 FROM named_ranges`)
+	if err != nil {
+		return fmt.Errorf("failed to list named ranges: %w", err)
+	}
+	defer named.Close()
+	for named.Next() {
+		var name, a1 string
+		if err := named.Scan(&name, &a1); err != nil {
+			return err
+		}
+		if err := s.mem.AddNamedRange(name, a1); err != nil {
+			return fmt.Errorf("failed to load named range %s: %w", name, err)
+		}
+	}
+	return named.Err()
+}
+
+func (s *SQLiteStore) loadTable(table string) ([][]interface{}, error) {
+	rows, err := s.db.Query(fmt.Sprintf(`SELECT * FROM %q ORDER BY idx`, table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table %q: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var grid [][]interface{}
+	for rows.Next() {
+		var idx int
+		row := make([]interface{}, len(columns)-1)
+		dest := []interface{}{&idx}
+		for i := range row {
+			dest = append(dest, &row[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to read table %q: %w", table, err)
+		}
+
+		// Keep whole numbers as ints, the same as they were written.
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case int64:
+				row[i] = int(v)
+			case []byte:
+				row[i] = string(v)
+			}
+		}
+
+		for len(grid) < idx {
+			grid = append(grid, nil)
+		}
+		grid = append(grid, trimEmptyCells(row))
+	}
+	return grid, rows.Err()
+}
+
+// createCategoriesView (re)creates the categories view over the expenses
+// table, with the columns the header of the main tab names.
+func (s *SQLiteStore) createCategoriesView() error {
+	header, err := s.mem.Get("A1:Z1")
+	if err != nil || len(header) == 0 {
+		return err
+	}
+	columns, err := tableColumns(s.db, "expenses")
+	if err != nil {
+		return err
+	}
+	column := func(name string) string {
+		for i, cell := range header[0] {
+			if fmt.Sprintf("%v", cell) == name && i < len(columns) {
+				return columns[i]
+			}
+		}
+		return ""
+	}
+	category, nominal := column("Kategori"), column("Nominal")
+
+	if _, err := s.db.Exec(`DROP VIEW IF EXISTS categories`); err != nil {
+		return fmt.Errorf("failed to drop categories view: %w", err)
+	}
+	if category == "" || nominal == "" {
+		return nil
+	}
+	view := fmt.Sprintf(`CREATE VIEW categories AS SELECT %[1]q AS name, COUNT(*) AS entries, SUM(%[2]q) AS total FROM expenses WHERE idx > 0 AND %[1]q IS NOT NULL AND %[1]q != '' GROUP BY %[1]q`, category, nominal)
+	if _, err := s.db.Exec(view); err != nil {
+		return fmt.Errorf("failed to create categories view: %w", err)
+	}
+	return nil
+}
+
+// querier is what tableColumns needs of a database or a transaction.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// tableColumns returns the columns of table after idx, in order.
+func tableColumns(q querier, table string) ([]string, error) {
+	rows, err := q.Query(fmt.Sprintf(`SELECT name FROM pragma_table_info(%s) ORDER BY cid`, quoteString(table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of table %q: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if name != "idx" {
+			columns = append(columns, name)
+		}
+	}
+	return columns, rows.Err()
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// columnName names the column for the cells of sheet column i after its
+// header, or after its letter when the header is empty or already taken.
+func columnName(header []interface{}, i int, taken []string) string {
+	name := ""
+	if i < len(header) && header[i] != nil {
+		name = strings.Trim(nonWordPattern.ReplaceAllString(strings.ToLower(fmt.Sprintf("%v", header[i])), "_"), "_")
+	}
+	if name == "" || name == "idx" || slices.Contains(taken, name) {
+		name = "col_" + strings.ToLower(columnLetter(i))
+	}
+	for n := 2; slices.Contains(taken, name); n++ {
+		name = fmt.Sprintf("col_%s_%d", strings.ToLower(columnLetter(i)), n)
+	}
+	return name
+}
+
+// sqliteValue is the value a cell is stored as. Values the driver does not
+// take are stored as their text.
+func sqliteValue(cell interface{}) interface{} {
+	switch cell.(type) {
+	case nil, string, int, int64, float64, bool:
+		return cell
+	}
+	return fmt.Sprintf("%v", cell)
+}
+
+// commit runs write in a transaction and commits it.
+func (s *SQLiteStore) commit(write func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := write(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveRows writes the rows from to to, excluded, of the tab title as they
+// are in memory: each one is upserted, or deleted when it is empty. Columns
+// are added to the table for cells beyond its last one.
+func (s *SQLiteStore) saveRows(tx *sql.Tx, title string, from, to int) error {
+	table, ok := s.tables[title]
+	if !ok {
+		return fmt.Errorf("no table for sheet %q", title)
+	}
+
+	s.mem.mu.Lock()
+	grid := s.mem.sheets[title]
+	var header []interface{}
+	if len(grid) > 0 {
+		header = slices.Clone(grid[0])
+	}
+	rows := make([][]interface{}, 0, max(to-from, 0))
+	for idx := from; idx < to; idx++ {
+		var row []interface{}
+		if idx < len(grid) {
+			row = slices.Clone(trimEmptyCells(grid[idx]))
+		}
+		rows = append(rows, row)
+	}
+	s.mem.mu.Unlock()
+
+	columns, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	for i, row := range rows {
+		idx := from + i
+		if len(row) == 0 {
+			if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %q WHERE idx = ?`, table), idx); err != nil {
+				return fmt.Errorf("failed to delete row %d of table %q: %w", idx, table, err)
+			}
+			continue
+		}
+
+		for len(columns) < len(row) {
+			name := columnName(header, len(columns), columns)
+			if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %q ADD COLUMN %q`, table, name)); err != nil {
+				return fmt.Errorf("failed to add column %q to table %q: %w", name, table, err)
+			}
+			columns = append(columns, name)
+		}
+
+		names := []string{"idx"}
+		placeholders := []string{"?"}
+		var updates []string
+		args := []interface{}{idx}
+		for j, column := range columns {
+			var cell interface{}
+			if j < len(row) {
+				cell = row[j]
+			}
+			names = append(names, fmt.Sprintf("%q", column))
+			placeholders = append(placeholders, "?")
+			updates = append(updates, fmt.Sprintf("%q = excluded.%q", column, column))
+			args = append(args, sqliteValue(cell))
+		}
+		upsert := fmt.Sprintf(`INSERT INTO %q (%s) VALUES (%s) ON CONFLICT (idx) DO UPDATE SET %s`,
+			table, strings.Join(names, ", "), strings.Join(placeholders, ", "), strings.Join(updates, ", "))
+		if _, err := tx.Exec(upsert, args...); err != nil {
+			return fmt.Errorf("failed to write row %d of table %q: %w", idx, table, err)
+		}
+	}
+	return nil
+}
+
+// saveRange writes the rows of the A1 range r, as saveRows. Open-ended
+// ranges go down to height.
+func (s *SQLiteStore) saveRange(tx *sql.Tx, r a1Range, height int) error {
+	to := r.endRow + 1
+	if r.endRow < 0 {
+		to = height
+	}
+	return s.saveRows(tx, r.sheet, r.startRow, to)
+}
+
+// height returns how many rows the tab title has in memory.
+func (s *SQLiteStore) height(title string) int {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	return len(s.mem.sheets[title])
+}
+
+// ensureTable creates the table of the tab title unless it has one.
+func (s *SQLiteStore) ensureTable(title string) error {
+	if _, ok := s.tables[title]; ok {
+		return nil
+	}
+
+	table := s.newTableName(title)
+	err := s.commit(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %q (idx INTEGER PRIMARY KEY)`, table)); err != nil {
+			return fmt.Errorf("failed to create table %q: %w", table, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO sheets (title, table_name) VALUES (?, ?)`, title, table); err != nil {
+			return fmt.Errorf("failed to register table %q: %w", table, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.tables[title] = table
+	return nil
+}
+
+func (s *SQLiteStore) Get(readRange string) ([][]interface{}, error) {
+	return s.mem.Get(readRange)
+}
+
+func (s *SQLiteStore) Append(writeRange string, values [][]interface{}) error {
+	r, err := parseA1Range(writeRange)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ensureTable(r.sheet); err != nil {
+		return err
+	}
+	s.mem.mu.Lock()
+	next := s.mem.nextRow(r.sheet)
+	s.mem.mu.Unlock()
+
+	if err := s.mem.Append(writeRange, values); err != nil {
+		return err
+	}
+	return s.commit(func(tx *sql.Tx) error {
+		return s.saveRows(tx, r.sheet, next, next+len(values))
+	})
+}
+
+func (s *SQLiteStore) Update(writeRange string, values [][]interface{}) error {
+	r, err := parseA1Range(writeRange)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ensureTable(r.sheet); err != nil {
+		return err
+	}
+	if err := s.mem.Update(writeRange, values); err != nil {
+		return err
+	}
+	return s.commit(func(tx *sql.Tx) error {
+		return s.saveRows(tx, r.sheet, r.startRow, r.startRow+len(values))
+	})
+}
+
+func (s *SQLiteStore) Clear(clearRange string) error {
+	r, err := parseA1Range(clearRange)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ensureTable(r.sheet); err != nil {
+		return err
+	}
+	if err := s.mem.Clear(clearRange); err != nil {
+		return err
+	}
+	return s.commit(func(tx *sql.Tx) error {
+		return s.saveRange(tx, r, s.height(r.sheet))
+	})
+}
+
+func (s *SQLiteStore) BatchUpdate(data []RangeValues) error {
+	ranges := make([]a1Range, len(data))
+	for i, d := range data {
+		r, err := parseA1Range(d.Range)
+		if err != nil {
+			return err
+		}
+		ranges[i] = r
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range ranges {
+		if err := s.ensureTable(r.sheet); err != nil {
+			return err
+		}
+	}
+	if err := s.mem.BatchUpdate(data); err != nil {
+		return err
+	}
+	return s.commit(func(tx *sql.Tx) error {
+		for i, r := range ranges {
+			if err := s.saveRows(tx, r.sheet, r.startRow, r.startRow+len(data[i].Values)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRows rewrites the rows from the first deleted one down, which all
+// move up.
+func (s *SQLiteStore) DeleteRows(title string, rows []int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.ensureTable(title); err != nil {
+		return err
+	}
+	height := s.height(title)
+	if err := s.mem.DeleteRows(title, rows); err != nil {
+		return err
+	}
+	return s.commit(func(tx *sql.Tx) error {
+		return s.saveRows(tx, title, max(slices.Min(rows)-1, 0), height)
+	})
+}
+
+func (s *SQLiteStore) SheetTitles() ([]string, error) {
+	return s.mem.SheetTitles()
+}
+
+func (s *SQLiteStore) Ping() error {
+	if err := s.db.Ping(); err != nil {
+		return fmt.Errorf("failed to reach SQLite database: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) NamedRanges() (map[string]string, error) {
+	return s.mem.NamedRanges()
+}
+
+func (s *SQLiteStore) AddNamedRange(name, a1 string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.mem.AddNamedRange(name, a1); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`INSERT INTO named_ranges (name, a1) VALUES (?, ?) ON CONFLICT (name) DO UPDATE SET a1 = excluded.a1`, name, a1); err != nil {
+		return fmt.Errorf("failed to save named range %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) EnsureSheet(title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.ensureTable(title); err != nil {
+		return err
+	}
+	return s.mem.EnsureSheet(title)
+}
+
+// exportToSQLite copies every tab of src into the SQLite database at path and
+// returns the number of rows copied. The first tab of src is the one the bot
+// reads expenses from, so it becomes the "expenses" table.
+func exportToSQLite(src SheetStore, path string) (int, error) {
+	dst, err := NewSQLiteStore(path)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.db.Close()
+
+	titles, err := src.SheetTitles()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list source sheets: %w", err)
+	}
+
+	total := 0
+	for i, title := range titles {
+		rows, err := src.Get(sheetRange(title, "A:Z"))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read sheet %q: %w", title, err)
+		}
+
+		dstTitle := title
+		if i == 0 {
+			dstTitle = ""
+		}
+		if err := dst.EnsureSheet(dstTitle); err != nil {
+			return 0, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if err := dst.Update(sheetRange(dstTitle, "A1"), rows); err != nil {
+			return 0, fmt.Errorf("failed to write table for sheet %q: %w", title, err)
+		}
+		total += len(rows)
+	}
+	if err := dst.createCategoriesView(); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
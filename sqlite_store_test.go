@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestSQLiteStoreKeepsRowsAcrossOpens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chatkeu.db")
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tabs whose titles map to the same name must not share a table
+	for _, title := range []string{"Foo Bar", "foo_bar", "Sheets"} {
+		if err := s.EnsureSheet(title); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Update(sheetRange(title, "A1"), [][]interface{}{{title}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Append("A1", [][]interface{}{{i + 2, "16-10-2026", 1000 * i, "Makanan", fmt.Sprintf("entry %d", i)}}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := s.DeleteRows("", []int{3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Clear("A5:I5"); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := s.Get("A:I")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.db.Close()
+
+	reopened, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.db.Close()
+
+	got, err := reopened.Get("A:I")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A cleared row reads back as nil rather than empty
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got rows %v after reopening, want %v", got, want)
+	}
+	for _, title := range []string{"Foo Bar", "foo_bar", "Sheets"} {
+		rows, err := reopened.Get(sheetRange(title, "A1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 1 || rows[0][0] != title {
+			t.Errorf("sheet %q holds %v, want its own title", title, rows)
+		}
+	}
+
+	var total int
+	if err := reopened.db.QueryRow(`SELECT SUM(nominal) FROM expenses WHERE idx > 0`).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	var categories int
+	if err := reopened.db.QueryRow(`SELECT total FROM categories WHERE name = 'Makanan'`).Scan(&categories); err != nil {
+		t.Fatal(err)
+	}
+	if total == 0 || categories != total {
+		t.Errorf("got expenses total %d and categories total %d, want the same non-zero total", total, categories)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// weekBounds returns the first day of the Sunday to Saturday week offset
+// weeks away from the current one and the first day after it.
+func weekBounds(offset int) (time.Time, time.Time) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart := today.AddDate(0, 0, -int(today.Weekday())+offset*7)
+	return weekStart, weekStart.AddDate(0, 0, 7)
+}
+
+// getWeeklySummaryByOffset sums the spending per category of the Sunday to
+// Saturday week offset weeks away from the current one, 0 being this week
+// and -1 last week.
+func (b *Bot) getWeeklySummaryByOffset(ctx context.Context, offset int) (map[string]int, error) {
+	weekStart, weekEnd := weekBounds(offset)
+	rows, err := b.getEntriesBetween(ctx, weekStart, weekEnd.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly summary: %w", err)
+	}
+
+	totals := make(map[string]int)
+	for _, row := range rows {
+		totals[normalizeCategory(row.Category)] += row.Nominal
+	}
+	return totals, nil
+}
+
+// compareWeeks lists how the spending of each category changed from last
+// week to this week, biggest change first.
+func (b *Bot) compareWeeks(ctx context.Context) (string, error) {
+	thisWeek, err := b.getWeeklySummaryByOffset(ctx, 0)
+	if err != nil {
+		return "", err
+	}
+	lastWeek, err := b.getWeeklySummaryByOffset(ctx, -1)
+	if err != nil {
+		return "", err
+	}
+
+	var categories []string
+	for category := range lastWeek {
+		categories = append(categories, category)
+	}
+	for category := range thisWeek {
+		if _, ok := lastWeek[category]; !ok {
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) == 0 {
+		return "Tidak ada pengeluaran minggu ini maupun minggu lalu", nil
+	}
+
+	delta := func(category string) int {
+		d := thisWeek[category] - lastWeek[category]
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if delta(categories[i]) != delta(categories[j]) {
+			return delta(categories[i]) > delta(categories[j])
+		}
+		return categories[i] < categories[j]
+	})
+
+	amount := func(totals map[string]int, category string) string {
+		if total, ok := totals[category]; ok {
+			return "Rp " + formatRupiah(total)
+		}
+		return "—"
+	}
+
+	var result strings.Builder
+	result.WriteString("📊 Minggu Lalu → Minggu Ini:\n\n")
+	for _, category := range categories {
+		result.WriteString(fmt.Sprintf("%s: %s → %s", category, amount(lastWeek, category), amount(thisWeek, category)))
+
+		before, inLastWeek := lastWeek[category]
+		after, inThisWeek := thisWeek[category]
+		switch {
+		case !inLastWeek || !inThisWeek || before == 0:
+		case after > before:
+			result.WriteString(fmt.Sprintf(" (▲%d%%)", (after-before)*100/before))
+		case after < before:
+			result.WriteString(fmt.Sprintf(" (▼%d%%)", (before-after)*100/before))
+		default:
+			result.WriteString(" (=)")
+		}
+		result.WriteString("\n")
+	}
+	return result.String(), nil
+}
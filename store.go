@@ -0,0 +1,708 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
+)
+
+// SheetStore is the storage the bot reads and writes rows through. Ranges
+// use A1 notation, e.g. "A:E", "A5:E5" or "Preferences!A2:D2".
+type SheetStore interface {
+	Get(readRange string) ([][]interface{}, error)
+	Append(writeRange string, values [][]interface{}) error
+	Update(writeRange string, values [][]interface{}) error
+	Clear(clearRange string) error
+	BatchUpdate(data []RangeValues) error
+	SheetTitles() ([]string, error)
+	EnsureSheet(title string) error
+	// Ping checks that the store can be reached.
+	Ping() error
+	// NamedRanges returns the A1 range of every named range by its name.
+	NamedRanges() (map[string]string, error)
+	// AddNamedRange gives the A1 range a1 a name. A range without a sheet
+	// title is on the first tab.
+	AddNamedRange(name, a1 string) error
+	// DeleteRows removes the 1-based rows of the tab title, the first tab
+	// when empty, moving the rows below them up. They are deleted from the
+	// bottom up in one call, so the numbers stay valid while deleting.
+	DeleteRows(title string, rows []int) error
+}
+
+// ContextStore is a SheetStore whose requests can be tied to a context, such
+// as the one of the update they are made for.
+type ContextStore interface {
+	SheetStore
+	// WithContext returns the store with its requests cancelled when ctx
+	// is. The store's own timeout still applies to each request.
+	WithContext(ctx context.Context) SheetStore
+}
+
+// storeWithContext returns store with its requests tied to ctx, or store
+// itself when it does not make requests that can be cancelled.
+func storeWithContext(ctx context.Context, store SheetStore) SheetStore {
+	if s, ok := store.(ContextStore); ok {
+		return s.WithContext(ctx)
+	}
+	return store
+}
+
+// RangeValues is a block of values written at an A1 range.
+type RangeValues struct {
+	Range  string
+	Values [][]interface{}
+}
+
+// GoogleSheetStore stores rows in a Google Spreadsheet. The Sheets client is
+// fetched from service on every call so that it can be created lazily.
+// Each request is given up after timeout so that a hanging Sheets API
+// cannot block the bot, and when ctx is cancelled so that requests still
+// running at shutdown stop with it.
+type GoogleSheetStore struct {
+	ctx           context.Context
+	service       func() (*sheets.Service, error)
+	spreadsheetID string
+	timeout       time.Duration
+}
+
+func NewGoogleSheetStore(ctx context.Context, service func() (*sheets.Service, error), spreadsheetID string, timeout time.Duration) *GoogleSheetStore {
+	return &GoogleSheetStore{ctx: ctx, service: service, spreadsheetID: spreadsheetID, timeout: timeout}
+}
+
+func (s *GoogleSheetStore) WithContext(ctx context.Context) SheetStore {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
+}
+
+// call runs the request op with a context that expires after the store's
+// timeout or when the store's context is cancelled.
+func (s *GoogleSheetStore) call(op string, request func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+	err := request(ctx)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		log.Printf("sheets %s of spreadsheet %s timed out after %v", op, s.spreadsheetID, s.timeout)
+	}
+	return err
+}
+
+func (s *GoogleSheetStore) Get(readRange string) ([][]interface{}, error) {
+	srv, err := s.service()
+	if err != nil {
+		return nil, err
+	}
+	var resp *sheets.ValueRange
+	err = s.call("get", func(ctx context.Context) (err error) {
+		resp, err = srv.Spreadsheets.Values.Get(s.spreadsheetID, readRange).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, nil
+	}
+	return resp.Values, nil
+}
+
+func (s *GoogleSheetStore) Append(writeRange string, values [][]interface{}) error {
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	valueRange := &sheets.ValueRange{Values: values}
+	return s.call("append", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.Values.Append(s.spreadsheetID, writeRange, valueRange).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+		return err
+	})
+}
+
+func (s *GoogleSheetStore) Update(writeRange string, values [][]interface{}) error {
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	valueRange := &sheets.ValueRange{Values: values}
+	return s.call("update", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.Values.Update(s.spreadsheetID, writeRange, valueRange).ValueInputOption("USER_ENTERED").Context(ctx).Do()
+		return err
+	})
+}
+
+func (s *GoogleSheetStore) Clear(clearRange string) error {
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	return s.call("clear", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.Values.Clear(s.spreadsheetID, clearRange, &sheets.ClearValuesRequest{}).Context(ctx).Do()
+		return err
+	})
+}
+
+func (s *GoogleSheetStore) BatchUpdate(data []RangeValues) error {
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	req := &sheets.BatchUpdateValuesRequest{ValueInputOption: "USER_ENTERED"}
+	for _, d := range data {
+		req.Data = append(req.Data, &sheets.ValueRange{Range: d.Range, Values: d.Values})
+	}
+	return s.call("batch update", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.Values.BatchUpdate(s.spreadsheetID, req).Context(ctx).Do()
+		return err
+	})
+}
+
+func (s *GoogleSheetStore) SheetTitles() ([]string, error) {
+	srv, err := s.service()
+	if err != nil {
+		return nil, err
+	}
+	var spreadsheet *sheets.Spreadsheet
+	err = s.call("get sheet titles", func(ctx context.Context) (err error) {
+		spreadsheet, err = srv.Spreadsheets.Get(s.spreadsheetID).Fields("sheets.properties.title").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make([]string, 0, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		titles = append(titles, sheet.Properties.Title)
+	}
+	return titles, nil
+}
+
+func (s *GoogleSheetStore) EnsureSheet(title string) error {
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	titles, err := s.SheetTitles()
+	if err != nil {
+		return err
+	}
+	for _, t := range titles {
+		if t == title {
+			return nil
+		}
+	}
+
+	req := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: title}},
+		}},
+	}
+	return s.call("add sheet", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.BatchUpdate(s.spreadsheetID, req).Context(ctx).Do()
+		return err
+	})
+}
+
+func (s *GoogleSheetStore) NamedRanges() (map[string]string, error) {
+	srv, err := s.service()
+	if err != nil {
+		return nil, err
+	}
+	var spreadsheet *sheets.Spreadsheet
+	err = s.call("get named ranges", func(ctx context.Context) (err error) {
+		spreadsheet, err = srv.Spreadsheets.Get(s.spreadsheetID).Fields("namedRanges", "sheets.properties(sheetId,title)").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[int64]string, len(spreadsheet.Sheets))
+	for _, sheet := range spreadsheet.Sheets {
+		titles[sheet.Properties.SheetId] = sheet.Properties.Title
+	}
+	ranges := make(map[string]string, len(spreadsheet.NamedRanges))
+	for _, named := range spreadsheet.NamedRanges {
+		if a1, ok := gridRangeA1(titles, named.Range); ok {
+			ranges[named.Name] = a1
+		}
+	}
+	return ranges, nil
+}
+
+func (s *GoogleSheetStore) AddNamedRange(name, a1 string) error {
+	r, err := parseA1Range(a1)
+	if err != nil {
+		return err
+	}
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	var spreadsheet *sheets.Spreadsheet
+	err = s.call("get sheet IDs", func(ctx context.Context) (err error) {
+		spreadsheet, err = srv.Spreadsheets.Get(s.spreadsheetID).Fields("sheets.properties(sheetId,title)").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	sheetID := int64(-1)
+	for i, sheet := range spreadsheet.Sheets {
+		if (r.sheet == "" && i == 0) || (r.sheet != "" && sheet.Properties.Title == r.sheet) {
+			sheetID = sheet.Properties.SheetId
+			break
+		}
+	}
+	if sheetID < 0 {
+		return fmt.Errorf("sheet %q not found", r.sheet)
+	}
+
+	// The first sheet usually has ID 0, which is only sent when forced.
+	grid := &sheets.GridRange{
+		SheetId:          sheetID,
+		StartRowIndex:    int64(r.startRow),
+		StartColumnIndex: int64(r.startCol),
+		EndColumnIndex:   int64(r.endCol + 1),
+		ForceSendFields:  []string{"SheetId"},
+	}
+	if r.endRow >= 0 {
+		grid.EndRowIndex = int64(r.endRow + 1)
+	}
+	req := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			AddNamedRange: &sheets.AddNamedRangeRequest{NamedRange: &sheets.NamedRange{Name: name, Range: grid}},
+		}},
+	}
+	return s.call("add named range", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.BatchUpdate(s.spreadsheetID, req).Context(ctx).Do()
+		return err
+	})
+}
+
+func (s *GoogleSheetStore) DeleteRows(title string, rows []int) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	var spreadsheet *sheets.Spreadsheet
+	err = s.call("get sheet IDs", func(ctx context.Context) (err error) {
+		spreadsheet, err = srv.Spreadsheets.Get(s.spreadsheetID).Fields("sheets.properties(sheetId,title)").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	sheetID := int64(-1)
+	for i, sheet := range spreadsheet.Sheets {
+		if (title == "" && i == 0) || (title != "" && sheet.Properties.Title == title) {
+			sheetID = sheet.Properties.SheetId
+			break
+		}
+	}
+	if sheetID < 0 {
+		return fmt.Errorf("sheet %q not found", title)
+	}
+
+	// Consecutive rows are deleted with one DeleteDimensionRequest
+	req := &sheets.BatchUpdateSpreadsheetRequest{}
+	for _, span := range rowSpansDescending(rows) {
+		req.Requests = append(req.Requests, &sheets.Request{
+			DeleteDimension: &sheets.DeleteDimensionRequest{Range: &sheets.DimensionRange{
+				SheetId:         sheetID,
+				Dimension:       "ROWS",
+				StartIndex:      int64(span.start - 1),
+				EndIndex:        int64(span.end),
+				ForceSendFields: []string{"SheetId", "StartIndex"},
+			}},
+		})
+	}
+	return s.call("delete rows", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.BatchUpdate(s.spreadsheetID, req).Context(ctx).Do()
+		return err
+	})
+}
+
+// rowSpansDescending groups rows into spans of consecutive rows, the
+// bottom span first. Duplicate rows are ignored.
+func rowSpansDescending(rows []int) []rowSpan {
+	sorted := slices.Clone(rows)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var spans []rowSpan
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if n := len(spans); n > 0 && spans[n-1].start == sorted[i]+1 {
+			spans[n-1].start = sorted[i]
+			continue
+		}
+		spans = append(spans, rowSpan{start: sorted[i], end: sorted[i]})
+	}
+	return spans
+}
+
+// gridRangeA1 writes a range of the Sheets API in A1 notation. Ranges
+// spanning every column are not used by the bot and are skipped.
+func gridRangeA1(titles map[int64]string, g *sheets.GridRange) (string, bool) {
+	if g == nil || g.EndColumnIndex == 0 {
+		return "", false
+	}
+	title, ok := titles[g.SheetId]
+	if !ok {
+		return "", false
+	}
+
+	start, end := columnLetter(int(g.StartColumnIndex)), columnLetter(int(g.EndColumnIndex-1))
+	if g.StartRowIndex > 0 || g.EndRowIndex > 0 {
+		start += strconv.FormatInt(g.StartRowIndex+1, 10)
+	}
+	if g.EndRowIndex > 0 {
+		end += strconv.FormatInt(g.EndRowIndex, 10)
+	}
+	return sheetRange(title, start+":"+end), true
+}
+
+// Ping opens the spreadsheet, which fails when its ID is wrong or it is not
+// shared with the service account.
+func (s *GoogleSheetStore) Ping() error {
+	srv, err := s.service()
+	if err != nil {
+		return err
+	}
+	err = s.call("ping", func(ctx context.Context) error {
+		_, err := srv.Spreadsheets.Get(s.spreadsheetID).Fields("spreadsheetId").Context(ctx).Do()
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusNotFound:
+			return fmt.Errorf("spreadsheet %s not found, check the spreadsheet ID: %w", s.spreadsheetID, err)
+		case http.StatusForbidden:
+			return fmt.Errorf("no access to spreadsheet %s, share it with the service account as an editor: %w", s.spreadsheetID, err)
+		}
+	}
+	return fmt.Errorf("failed to open spreadsheet %s: %w", s.spreadsheetID, err)
+}
+
+// MemorySheetStore keeps rows in memory. It mimics the parts of the Sheets
+// values API the bot relies on, which makes it handy for local runs and tests.
+// The zero value is ready to use.
+type MemorySheetStore struct {
+	mu          sync.Mutex
+	sheets      map[string][][]interface{}
+	namedRanges map[string]string
+}
+
+func (s *MemorySheetStore) Get(readRange string) ([][]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := parseA1Range(readRange)
+	if err != nil {
+		return nil, err
+	}
+
+	grid := s.sheets[r.sheet]
+	var values [][]interface{}
+	for i := r.startRow; i < len(grid) && (r.endRow < 0 || i <= r.endRow); i++ {
+		var row []interface{}
+		for j := r.startCol; j < len(grid[i]) && j <= r.endCol; j++ {
+			row = append(row, grid[i][j])
+		}
+		values = append(values, trimEmptyCells(row))
+	}
+
+	// Like the Sheets API, trailing empty rows are not returned.
+	for len(values) > 0 && len(values[len(values)-1]) == 0 {
+		values = values[:len(values)-1]
+	}
+	return values, nil
+}
+
+func (s *MemorySheetStore) Append(writeRange string, values [][]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := parseA1Range(writeRange)
+	if err != nil {
+		return err
+	}
+
+	s.write(r.sheet, s.nextRow(r.sheet), r.startCol, values)
+	return nil
+}
+
+// nextRow returns the zero-based row Append writes to, the one after the
+// last row with a value. The caller holds mu.
+func (s *MemorySheetStore) nextRow(sheet string) int {
+	next := 0
+	for i, row := range s.sheets[sheet] {
+		if len(trimEmptyCells(row)) > 0 {
+			next = i + 1
+		}
+	}
+	return next
+}
+
+func (s *MemorySheetStore) Update(writeRange string, values [][]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := parseA1Range(writeRange)
+	if err != nil {
+		return err
+	}
+	s.write(r.sheet, r.startRow, r.startCol, values)
+	return nil
+}
+
+func (s *MemorySheetStore) Clear(clearRange string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, err := parseA1Range(clearRange)
+	if err != nil {
+		return err
+	}
+
+	grid := s.sheets[r.sheet]
+	for i := r.startRow; i < len(grid) && (r.endRow < 0 || i <= r.endRow); i++ {
+		for j := r.startCol; j < len(grid[i]) && j <= r.endCol; j++ {
+			grid[i][j] = nil
+		}
+	}
+	return nil
+}
+
+func (s *MemorySheetStore) BatchUpdate(data []RangeValues) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range data {
+		r, err := parseA1Range(d.Range)
+		if err != nil {
+			return err
+		}
+		s.write(r.sheet, r.startRow, r.startCol, d.Values)
+	}
+	return nil
+}
+
+func (s *MemorySheetStore) SheetTitles() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	titles := make([]string, 0, len(s.sheets))
+	for title := range s.sheets {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	return titles, nil
+}
+
+func (s *MemorySheetStore) EnsureSheet(title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sheets == nil {
+		s.sheets = make(map[string][][]interface{})
+	}
+	if _, ok := s.sheets[title]; !ok {
+		s.sheets[title] = nil
+	}
+	return nil
+}
+
+// Ping always succeeds, the rows are in memory.
+func (s *MemorySheetStore) Ping() error {
+	return nil
+}
+
+func (s *MemorySheetStore) NamedRanges() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.namedRanges), nil
+}
+
+func (s *MemorySheetStore) AddNamedRange(name, a1 string) error {
+	if _, err := parseA1Range(a1); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.namedRanges == nil {
+		s.namedRanges = make(map[string]string)
+	}
+	s.namedRanges[name] = a1
+	return nil
+}
+
+func (s *MemorySheetStore) DeleteRows(title string, rows []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grid := s.sheets[title]
+	for _, span := range rowSpansDescending(rows) {
+		if span.start < 1 || span.start > len(grid) {
+			continue
+		}
+		grid = slices.Delete(grid, span.start-1, min(span.end, len(grid)))
+	}
+	if s.sheets != nil {
+		s.sheets[title] = grid
+	}
+	return nil
+}
+
+func (s *MemorySheetStore) write(sheet string, startRow, startCol int, values [][]interface{}) {
+	if s.sheets == nil {
+		s.sheets = make(map[string][][]interface{})
+	}
+
+	grid := s.sheets[sheet]
+	for i, row := range values {
+		for len(grid) <= startRow+i {
+			grid = append(grid, nil)
+		}
+		for len(grid[startRow+i]) < startCol+len(row) {
+			grid[startRow+i] = append(grid[startRow+i], nil)
+		}
+		copy(grid[startRow+i][startCol:], row)
+	}
+	s.sheets[sheet] = grid
+}
+
+// dryRunStore reads from the store it wraps but only logs writes, for trying
+// the bot out without touching real data.
+type dryRunStore struct {
+	SheetStore
+}
+
+func (s dryRunStore) Append(writeRange string, values [][]interface{}) error {
+	log.Printf("[dry-run] append %s: %v", writeRange, values)
+	return nil
+}
+
+func (s dryRunStore) Update(writeRange string, values [][]interface{}) error {
+	log.Printf("[dry-run] update %s: %v", writeRange, values)
+	return nil
+}
+
+func (s dryRunStore) Clear(clearRange string) error {
+	log.Printf("[dry-run] clear %s", clearRange)
+	return nil
+}
+
+func (s dryRunStore) BatchUpdate(data []RangeValues) error {
+	for _, d := range data {
+		log.Printf("[dry-run] update %s: %v", d.Range, d.Values)
+	}
+	return nil
+}
+
+func (s dryRunStore) EnsureSheet(title string) error {
+	log.Printf("[dry-run] ensure sheet %q", title)
+	return nil
+}
+
+func (s dryRunStore) DeleteRows(title string, rows []int) error {
+	log.Printf("[dry-run] delete rows of sheet %q: %v", title, rows)
+	return nil
+}
+
+func (s dryRunStore) WithContext(ctx context.Context) SheetStore {
+	return dryRunStore{storeWithContext(ctx, s.SheetStore)}
+}
+
+func (s dryRunStore) AddNamedRange(name, a1 string) error {
+	log.Printf("[dry-run] add named range %s: %s", name, a1)
+	return nil
+}
+
+// sheetRange prefixes an A1 range with a quoted sheet title.
+func sheetRange(title, cells string) string {
+	return fmt.Sprintf("'%s'!%s", strings.ReplaceAll(title, "'", "''"), cells)
+}
+
+func trimEmptyCells(row []interface{}) []interface{} {
+	for len(row) > 0 && (row[len(row)-1] == nil || row[len(row)-1] == "") {
+		row = row[:len(row)-1]
+	}
+	return row
+}
+
+// a1Range is a parsed A1 range with zero-based, inclusive bounds. An endRow
+// of -1 means the range is open-ended, as in "A:E".
+type a1Range struct {
+	sheet            string
+	startRow, endRow int
+	startCol, endCol int
+}
+
+func parseA1Range(s string) (a1Range, error) {
+	var r a1Range
+	if i := strings.LastIndex(s, "!"); i >= 0 {
+		r.sheet = strings.ReplaceAll(strings.Trim(s[:i], "'"), "''", "'")
+		s = s[i+1:]
+	}
+
+	start, end, hasEnd := strings.Cut(s, ":")
+	startCol, startRow, err := parseA1Cell(start)
+	if err != nil {
+		return r, err
+	}
+	endCol, endRow := startCol, startRow
+	if hasEnd {
+		if endCol, endRow, err = parseA1Cell(end); err != nil {
+			return r, err
+		}
+	}
+
+	r.startCol, r.endCol = startCol, endCol
+	r.startRow, r.endRow = max(startRow, 0), endRow
+	return r, nil
+}
+
+// parseA1Cell parses a cell reference such as "C7" or "C" into zero-based
+// column and row indexes. The row is -1 when the reference has no row.
+func parseA1Cell(cell string) (col, row int, err error) {
+	i := 0
+	for i < len(cell) && cell[i] >= 'A' && cell[i] <= 'Z' {
+		col = col*26 + int(cell[i]-'A'+1)
+		i++
+	}
+	if i == 0 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", cell)
+	}
+	if i == len(cell) {
+		return col - 1, -1, nil
+	}
+	n, err := strconv.Atoi(cell[i:])
+	if err != nil || n < 1 {
+		return 0, 0, fmt.Errorf("invalid cell reference %q", cell)
+	}
+	return col - 1, n - 1, nil
+}
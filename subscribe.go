@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// subscribeTimeout is how long a /subscribe request can be accepted.
+const subscribeTimeout = 24 * time.Hour
+
+// subscribeRequest is a /subscribe waiting for the invited chat to accept it.
+type subscribeRequest struct {
+	from    int64
+	expires time.Time
+}
+
+// jointSheetPrefix starts the title of every joint sheet. The dash keeps
+// them apart from the account tabs, see accountSheet.
+const jointSheetPrefix = "Shared-"
+
+// jointSheet returns the nth tab shared after a /subscribe by initiator,
+// e.g. "Shared-123456-1".
+func jointSheet(initiator int64, n int) string {
+	return fmt.Sprintf("%s%d-%d", jointSheetPrefix, initiator, n)
+}
+
+// errNotSubscribed is returned by unsubscribe when the chat does not share
+// a joint sheet.
+var errNotSubscribed = errors.New("chat does not share a joint sheet")
+
+// jointPartners returns the chats other than chatID that record in sheet.
+func (b *Bot) jointPartners(chatID int64, sheet string) []int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var partners []int64
+	for id, pref := range b.prefs {
+		if id != chatID && pref.JointSheet == sheet {
+			partners = append(partners, id)
+		}
+	}
+	return partners
+}
+
+// requestSubscription sends target a request to share chatID's joint sheet,
+// with buttons to accept or decline it.
+func (b *Bot) requestSubscription(chatID, target int64) string {
+	pref, ok := b.getPreference(chatID)
+	if !ok {
+		return b.msg(chatID).NeedStart
+	}
+	if target == chatID {
+		return "❌ Tidak bisa berbagi dengan diri sendiri"
+	}
+	if pref.JointSheet != "" {
+		return "❌ Kamu sudah berbagi pengeluaran. Gunakan /unsubscribe dulu"
+	}
+	partner, ok := b.getPreference(target)
+	if !ok {
+		return fmt.Sprintf("❌ Chat %d belum memakai bot ini. Minta dia kirim /start dulu", target)
+	}
+	if partner.JointSheet != "" {
+		return fmt.Sprintf("❌ Chat %d sudah berbagi pengeluaran dengan orang lain", target)
+	}
+
+	b.mu.Lock()
+	b.pendingSubscribe[target] = subscribeRequest{from: chatID, expires: time.Now().Add(subscribeTimeout)}
+	b.mu.Unlock()
+
+	name := pref.Name
+	if name == "" {
+		name = strconv.FormatInt(chatID, 10)
+	}
+	msg := tgbotapi.NewMessage(target, fmt.Sprintf("🤝 %s mengajak kamu mencatat pengeluaran bersama. Pengeluaranmu berikutnya akan dicatat di tab miliknya.\n\nAjakan ini berlaku 24 jam.", name))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Terima", fmt.Sprintf("subscribe_accept:%d", chatID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Tolak", fmt.Sprintf("subscribe_decline:%d", chatID)),
+		),
+	)
+	b.sendMessage(msg)
+	return fmt.Sprintf("📨 Ajakan terkirim ke chat %d. Ajakan berlaku 24 jam.", target)
+}
+
+// handleSubscribeCallback answers a /subscribe request with
+// "accept:<chat ID>" or "decline:<chat ID>", the chat ID of who sent it.
+func (b *Bot) handleSubscribeCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	action, fromStr, _ := strings.Cut(data, ":")
+	from, err := strconv.ParseInt(fromStr, 10, 64)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	request, ok := b.pendingSubscribe[chatID]
+	if ok && request.from == from {
+		delete(b.pendingSubscribe, chatID)
+	}
+	b.mu.Unlock()
+
+	if !ok || request.from != from {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Tidak ada ajakan yang menunggu jawaban"))
+		return
+	}
+	if time.Now().After(request.expires) {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "⌛ Ajakan ini sudah kedaluwarsa. Minta ajakan baru dengan /subscribe"))
+		return
+	}
+	if action != "accept" {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🚫 Ajakan ditolak"))
+		b.sendMessage(tgbotapi.NewMessage(from, fmt.Sprintf("🚫 Chat %d menolak ajakanmu", chatID)))
+		return
+	}
+
+	if err := b.startJointSheet(ctx, from, chatID); err != nil {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Gagal memulai pencatatan bersama"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "✅ Ajakan diterima. Pengeluaranmu berikutnya dicatat bersama. Berhenti dengan /unsubscribe"))
+	b.sendMessage(tgbotapi.NewMessage(from, fmt.Sprintf("✅ Chat %d menerima ajakanmu. Pengeluaran kalian berikutnya dicatat bersama.", chatID)))
+}
+
+// startJointSheet makes initiator and partner record their entries in a
+// new joint sheet of initiator. Every subscription gets a tab of its own, so
+// the entries of earlier partners are not mixed into it.
+func (b *Bot) startJointSheet(ctx context.Context, initiator, partner int64) error {
+	titles, err := storeWithContext(ctx, b.store).SheetTitles()
+	if err != nil {
+		return fmt.Errorf("failed to get sheet titles: %w", err)
+	}
+	title := jointSheet(initiator, 1)
+	for n := 2; slices.Contains(titles, title); n++ {
+		title = jointSheet(initiator, n)
+	}
+	if err := b.ensureTab(ctx, title, b.accountHeader()); err != nil {
+		return err
+	}
+
+	for _, chatID := range []int64{initiator, partner} {
+		pref, ok := b.getPreference(chatID)
+		if !ok {
+			return fmt.Errorf("no preferences for chat %d", chatID)
+		}
+		if pref.JointSheet != "" && pref.JointSheet != title {
+			return fmt.Errorf("chat %d already shares %s", chatID, pref.JointSheet)
+		}
+		pref.JointSheet = title
+		if err := b.saveUserPreference(ctx, pref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unsubscribe stops chatID from recording in its joint sheet and copies
+// every entry of the sheet to the tab chatID records in now, as entries of
+// chatID. It returns how many entries were copied.
+func (b *Bot) unsubscribe(ctx context.Context, chatID int64) (int, error) {
+	pref, ok := b.getPreference(chatID)
+	if !ok || pref.JointSheet == "" {
+		return 0, errNotSubscribed
+	}
+	title := pref.JointSheet
+
+	rows, err := b.getRowsIn(ctx, title)
+	if err != nil {
+		return 0, err
+	}
+	pref.JointSheet = ""
+	if err := b.saveUserPreference(ctx, pref); err != nil {
+		return 0, err
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	for i, row := range rows {
+		// The copy keeps when the message was sent but belongs to chatID
+		_, sent, _ := strings.Cut(row.Ref, ":")
+		if _, err := b.appendData(ctx, row.Nominal, row.Category, row.Description, row.Date, id+":"+sent, row.Sender); err != nil {
+			return i, err
+		}
+	}
+	return len(rows), nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// newSubscribedBot returns a test bot where chat 7 accepted the /subscribe
+// of chat 42.
+func newSubscribedBot(t *testing.T) *Bot {
+	t.Helper()
+	b, _ := newTestBot(t)
+	for _, chatID := range []int64{42, 7} {
+		if err := b.saveUserPreference(context.Background(), UserPreference{ChatID: chatID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.handleUpdate(context.Background(), textUpdate(42, "/subscribe 7"))
+	b.handleSubscribeCallback(context.Background(), 7, 1, "accept:42")
+	return b
+}
+
+func TestSubscribeSharesInitiatorSheet(t *testing.T) {
+	b := newSubscribedBot(t)
+	for _, chatID := range []int64{42, 7} {
+		if pref, _ := b.getPreference(chatID); pref.JointSheet != jointSheet(42, 1) {
+			t.Fatalf("chat %d shares %q, want %q", chatID, pref.JointSheet, jointSheet(42, 1))
+		}
+	}
+
+	b.handleUpdate(context.Background(), textUpdate(42, "10rb, Makanan, Sarapan"))
+	b.handleUpdate(context.Background(), textUpdate(7, "20rb, Belanja, Sabun"))
+
+	shared, err := b.getRowsIn(context.Background(), jointSheet(42, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shared) != 2 {
+		t.Fatalf("got %d shared entries, want 2", len(shared))
+	}
+	if main, _ := b.getRows(context.Background()); len(main) != 0 {
+		t.Errorf("got %d entries in the main tab, want 0", len(main))
+	}
+}
+
+func TestUnsubscribeCopiesSharedEntries(t *testing.T) {
+	b := newSubscribedBot(t)
+	b.handleUpdate(context.Background(), textUpdate(42, "10rb, Makanan, Sarapan"))
+	b.handleUpdate(context.Background(), textUpdate(7, "20rb, Belanja, Sabun"))
+
+	b.handleUpdate(context.Background(), textUpdate(7, "/unsubscribe"))
+
+	if pref, _ := b.getPreference(7); pref.JointSheet != "" {
+		t.Errorf("chat 7 still shares %q", pref.JointSheet)
+	}
+	rows, err := b.getRows(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d copied entries, want 2", len(rows))
+	}
+	for _, row := range rows {
+		if refChatID(row.Ref) != "7" {
+			t.Errorf("copied entry %q belongs to %s, want 7", row.Description, refChatID(row.Ref))
+		}
+	}
+}
+
+func TestSubscribeRequestExpires(t *testing.T) {
+	b, _ := newTestBot(t)
+	for _, chatID := range []int64{42, 7} {
+		if err := b.saveUserPreference(context.Background(), UserPreference{ChatID: chatID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.handleUpdate(context.Background(), textUpdate(42, "/subscribe 7"))
+	b.pendingSubscribe[7] = subscribeRequest{from: 42, expires: time.Now().Add(-time.Minute)}
+
+	b.handleSubscribeCallback(context.Background(), 7, 1, "accept:42")
+
+	if pref, _ := b.getPreference(7); pref.JointSheet != "" {
+		t.Errorf("expired request was accepted, chat 7 shares %q", pref.JointSheet)
+	}
+}
+
+func TestSubscribeAgainStartsNewSheet(t *testing.T) {
+	b := newSubscribedBot(t)
+	b.handleUpdate(context.Background(), textUpdate(7, "20rb, Belanja, Sabun"))
+	b.handleUpdate(context.Background(), textUpdate(42, "/unsubscribe"))
+	b.handleUpdate(context.Background(), textUpdate(7, "/unsubscribe"))
+
+	if err := b.saveUserPreference(context.Background(), UserPreference{ChatID: 9}); err != nil {
+		t.Fatal(err)
+	}
+	b.handleUpdate(context.Background(), textUpdate(42, "/subscribe 9"))
+	b.handleSubscribeCallback(context.Background(), 9, 1, "accept:42")
+
+	pref, _ := b.getPreference(42)
+	if pref.JointSheet != jointSheet(42, 2) {
+		t.Fatalf("chat 42 shares %q, want %q", pref.JointSheet, jointSheet(42, 2))
+	}
+	shared, err := b.getRowsIn(context.Background(), pref.JointSheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shared) != 0 {
+		t.Errorf("got %d entries of earlier partners in the new sheet, want 0", len(shared))
+	}
+}
+
+func TestResetClearsJointEntries(t *testing.T) {
+	b := newSubscribedBot(t)
+	b.handleUpdate(context.Background(), textUpdate(42, "10rb, Makanan, Sarapan"))
+	b.handleUpdate(context.Background(), textUpdate(7, "20rb, Belanja, Sabun"))
+	b.pendingSubscribe[9] = subscribeRequest{from: 7, expires: time.Now().Add(time.Hour)}
+
+	if _, err := b.resetUser(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+
+	shared, err := b.getRowsIn(context.Background(), jointSheet(42, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shared) != 1 || refChatID(shared[0].Ref) != "42" {
+		t.Errorf("got %d shared entries after reset, want only the entry of chat 42", len(shared))
+	}
+	if _, ok := b.pendingSubscribe[9]; ok {
+		t.Error("the /subscribe request of chat 7 was kept")
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+)
+
+// categoryIndexTTL is how long the category index is used before it is
+// rebuilt from the sheet, to pick up edits made outside the bot.
+const categoryIndexTTL = 10 * time.Minute
+
+// minSuggestionScore is how many earlier entries must back a suggestion.
+const minSuggestionScore = 2
+
+// categoryIndex counts, for every word used in a description, the
+// categories of the entries it was used in.
+type categoryIndex struct {
+	words    map[string]map[string]int
+	loadedAt time.Time
+}
+
+// pendingExpense is an expense waiting for the user to accept or reject a
+// suggested category.
+type pendingExpense struct {
+	expense    newExpense
+	suggestion string
+}
+
+// descriptionWords returns the words of a description worth matching on.
+func descriptionWords(description string) []string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(description)) {
+		word = strings.Trim(word, ".,!?()\"'")
+		if len(word) >= 3 {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func (idx *categoryIndex) add(description, category string) {
+	if idx.words == nil {
+		idx.words = make(map[string]map[string]int)
+	}
+	for _, word := range descriptionWords(description) {
+		if idx.words[word] == nil {
+			idx.words[word] = make(map[string]int)
+		}
+		idx.words[word][category]++
+	}
+}
+
+func (b *Bot) loadCategoryIndex(ctx context.Context) error {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return err
+	}
+
+	idx := categoryIndex{loadedAt: time.Now()}
+	for _, row := range rows {
+		idx.add(row.Description, normalizeCategory(row.Category))
+	}
+
+	b.mu.Lock()
+	b.categoryIndex = idx
+	b.mu.Unlock()
+	return nil
+}
+
+// suggestCategory returns the category most often used with descriptions
+// sharing words with description, or "" when there is no clear favourite.
+func (b *Bot) suggestCategory(ctx context.Context, description string) string {
+	b.mu.RLock()
+	stale := time.Since(b.categoryIndex.loadedAt) > categoryIndexTTL
+	b.mu.RUnlock()
+	if stale {
+		if err := b.loadCategoryIndex(ctx); err != nil {
+			log.Printf("failed to load category index: %v", err)
+			return ""
+		}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	scores := make(map[string]int)
+	for _, word := range descriptionWords(description) {
+		for category, count := range b.categoryIndex.words[word] {
+			scores[category] += count
+		}
+	}
+
+	best, bestScore := "", 0
+	for category, score := range scores {
+		if score > bestScore || (score == bestScore && category < best) {
+			best, bestScore = category, score
+		}
+	}
+	if bestScore < minSuggestionScore {
+		return ""
+	}
+	return best
+}
+
+// learnCategory adds a newly recorded entry to the category index.
+func (b *Bot) learnCategory(description, category string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.categoryIndex.loadedAt.IsZero() {
+		b.categoryIndex.add(description, category)
+	}
+}
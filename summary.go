@@ -0,0 +1,527 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dateFormats are the date formats found in the sheet. Entries are written
+// as DD-MM-YYYY but rows typed in by hand may use the others.
+var dateFormats = []string{"02-01-2006", "2-1-2006", "2006-01-02"}
+
+// normalizeDateString rewrites a date of the sheet in any of dateFormats as
+// DD-MM-YYYY, so it can be compared with dates formatted by the bot. Other
+// strings are returned unchanged.
+func normalizeDateString(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateFormats {
+		if date, err := time.Parse(layout, s); err == nil {
+			return date.Format("02-01-2006")
+		}
+	}
+	return s
+}
+
+// Row is an expense entry of the sheet.
+type Row struct {
+	RowNum      int // row number in the sheet, as used by /edit and /pin
+	Date        time.Time
+	Nominal     int
+	Category    string
+	Description string // decrypted, except as returned by parseRow
+	Ref         string // "chatID:unixDate" of the message it was recorded from
+	Receipt     string // URL of the receipt photo, empty when there is none
+	Sender      string // who sent the message, empty for entries recorded before it was kept
+	Location    string // "lat,lon" of a /checkin, empty when there is none
+}
+
+// parseRow parses an expense row read from column A, laid out as c. The row
+// number is not part of the row, so RowNum is left for the caller to set,
+// and the description is kept as stored.
+func (c SheetConfig) parseRow(raw []interface{}) (Row, error) {
+	cell := func(col int) string {
+		if col < len(raw) && raw[col] != nil {
+			return fmt.Sprintf("%v", raw[col])
+		}
+		return ""
+	}
+
+	dateCell, nominalCell := cell(columnIndex(c.DateCol)), cell(columnIndex(c.NominalCol))
+	date, err := time.ParseInLocation("02-01-2006", normalizeDateString(dateCell), time.Local)
+	if err != nil {
+		return Row{}, fmt.Errorf("invalid date %q: %w", dateCell, err)
+	}
+	nominal, err := strconv.Atoi(nominalCell)
+	if err != nil {
+		return Row{}, fmt.Errorf("invalid nominal %q: %w", nominalCell, err)
+	}
+	return Row{
+		Date:        date,
+		Nominal:     nominal,
+		Category:    cell(columnIndex(c.CategoryCol)),
+		Description: cell(columnIndex(c.DescriptionCol)),
+		Ref:         cell(refColumn),
+		Receipt:     cell(receiptColumn),
+		Sender:      cell(senderColumn),
+		Location:    cell(locationColumn),
+	}, nil
+}
+
+// getRows returns the expense entries in sheet order with their descriptions
+// decrypted. Rows that do not parse, like the header and deleted entries,
+// are skipped.
+func (b *Bot) getRows(ctx context.Context) ([]Row, error) {
+	return b.getRowsIn(ctx, "")
+}
+
+// getRowsIn is getRows for the tab title, the main tab when empty or an
+// account tab, see accountSheet.
+func (b *Bot) getRowsIn(ctx context.Context, title string) ([]Row, error) {
+	raw, err := storeWithContext(ctx, b.store).Get(b.tabRange(title, "A:"+b.sheetConfig.lastColumn()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+
+	rows := make([]Row, 0, len(raw))
+	for i, cells := range raw {
+		row, err := b.sheetConfig.parseRow(cells)
+		if err != nil {
+			continue
+		}
+		row.RowNum = i + 1
+		row.Description = b.openDescription(refChatID(row.Ref), row.Description)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// getEntriesBetween returns the entries dated from the day of start to the
+// day of end, both included, in sheet order.
+func (b *Bot) getEntriesBetween(ctx context.Context, start, end time.Time) ([]Row, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.Local)
+
+	var entries []Row
+	for _, row := range rows {
+		if !row.Date.Before(first) && !row.Date.After(last) {
+			entries = append(entries, row)
+		}
+	}
+	return entries, nil
+}
+
+// format renders the entry as a line of the weekly and monthly summaries.
+func (r Row) format() string {
+	return fmt.Sprintf("📅%s - 💰%d | 🎯%s | 📚%s", r.Date.Format("02-01-2006"), r.Nominal, r.Category, r.Description)
+}
+
+// getSummary sums the spending recorded on or after since. A zero since
+// sums every entry.
+func (b *Bot) getSummary(ctx context.Context, since time.Time) int {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		log.Printf("failed to get summary: %v", err)
+		return 0
+	}
+	total := 0
+	for _, row := range rows {
+		if !since.IsZero() && row.Date.Before(since) {
+			continue
+		}
+		total += row.Nominal
+	}
+	return total
+}
+
+// getSummaryByCategory sums the spending of category recorded on or after
+// since, matching the category case-insensitively.
+func (b *Bot) getSummaryByCategory(ctx context.Context, category string, since time.Time) (int, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get category summary: %w", err)
+	}
+
+	total := 0
+	for _, row := range rows {
+		if strings.EqualFold(row.Category, category) && !row.Date.Before(since) {
+			total += row.Nominal
+		}
+	}
+	return total, nil
+}
+
+// monthStart returns the first moment of the current month.
+func monthStart() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+}
+
+func (b *Bot) getLastEntry(ctx context.Context) (string, error) {
+	raw, err := storeWithContext(ctx, b.store).Get(b.expenseRange("A:" + b.sheetConfig.lastColumn()))
+	if err != nil {
+		return "", fmt.Errorf("failed to get last entry: %w", err)
+	}
+
+	if len(raw) < 2 {
+		return "Belum ada data yang dimasukkan", nil
+	}
+
+	row, err := b.sheetConfig.parseRow(raw[len(raw)-1])
+	if err != nil {
+		return "Format data tidak valid", nil
+	}
+	row.RowNum = len(raw)
+	row.Description = b.openDescription(refChatID(row.Ref), row.Description)
+
+	return fmt.Sprintf("🕘 Data terakhir: #%d - %s", row.RowNum, row.format()), nil
+}
+
+func (b *Bot) getWeeklySummary(ctx context.Context) (string, error) {
+	now := time.Now()
+	weekStart := now.AddDate(0, 0, -int(now.Weekday()))
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	rows, err := b.getEntriesBetween(ctx, weekStart, weekEnd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get weekly summary: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return "Tidak ada pengeluaran minggu ini", nil
+	}
+
+	total := 0
+	result := ""
+	for _, row := range rows {
+		total += row.Nominal
+		result += row.format() + "\n"
+	}
+	return fmt.Sprintf("📊 Pengeluaran %s – %s (Rp %s):\n\n",
+		weekStart.Format("02 Jan"), weekEnd.Format("02 Jan 2006"), formatRupiah(total)) + result, nil
+}
+
+// isoWeekStart returns the Monday of an ISO week.
+func isoWeekStart(year, week int) time.Time {
+	// January 4th is always in week 1.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	return jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+(week-1)*7)
+}
+
+// getWeekSummaryByISOWeek lists the spending of a Monday-to-Sunday ISO week.
+func (b *Bot) getWeekSummaryByISOWeek(ctx context.Context, year, week int) (string, error) {
+	weekStart := isoWeekStart(year, week)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	rows, err := b.getEntriesBetween(ctx, weekStart, weekEnd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get week summary: %w", err)
+	}
+
+	period := fmt.Sprintf("%d-%02d (%s - %s)", year, week, weekStart.Format("02-01-2006"), weekEnd.Format("02-01-2006"))
+	if len(rows) == 0 {
+		return "Tidak ada pengeluaran pada minggu " + period, nil
+	}
+
+	total := 0
+	result := ""
+	for _, row := range rows {
+		total += row.Nominal
+		result += row.format() + "\n"
+	}
+	return fmt.Sprintf("📊 Pengeluaran Minggu %s (Rp. %d):\n\n", period, total) + result, nil
+}
+
+// monthlyPageSize is how many entries one page of /monthly lists, keeping
+// the message under Telegram's 4096 character limit.
+const monthlyPageSize = 20
+
+// paginateEntries splits entries into pages of at most pageSize lines.
+func paginateEntries(entries []string, pageSize int) []string {
+	var pages []string
+	for start := 0; start < len(entries); start += pageSize {
+		end := min(start+pageSize, len(entries))
+		pages = append(pages, strings.Join(entries[start:end], "\n")+"\n")
+	}
+	return pages
+}
+
+// getMonthlySummary returns the first page of this month's spending.
+func (b *Bot) getMonthlySummary(ctx context.Context) (string, error) {
+	summary, _, err := b.getMonthlySummaryPage(ctx, 0)
+	return summary, err
+}
+
+// getMonthlySummaryPage returns the page-th page, counted from 0, of this
+// month's spending together with the number of pages.
+func (b *Bot) getMonthlySummaryPage(ctx context.Context, page int) (string, int, error) {
+	start := monthStart()
+	rows, err := b.getEntriesBetween(ctx, start, start.AddDate(0, 1, -1))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get monthly summary: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return "Tidak ada pengeluaran bulan ini", 0, nil
+	}
+
+	total := 0
+	entries := make([]string, 0, len(rows))
+	for _, row := range rows {
+		total += row.Nominal
+		entries = append(entries, row.format())
+	}
+
+	pages := paginateEntries(entries, monthlyPageSize)
+	page = max(0, min(page, len(pages)-1))
+	result := fmt.Sprintf("📊 Pengeluaran Bulan Ini (Rp. %d):\nHalaman %d dari %d (total %d entri)\n\n",
+		total, page+1, len(pages), len(entries))
+	return result + pages[page], len(pages), nil
+}
+
+// monthlyPageKeyboard links to the pages around page of /monthly.
+func monthlyPageKeyboard(page, pages int) *tgbotapi.InlineKeyboardMarkup {
+	var buttons []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("« Sebelumnya", fmt.Sprintf("monthly_page:%d", page-1)))
+	}
+	if page < pages-1 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("Lihat lebih banyak »", fmt.Sprintf("monthly_page:%d", page+1)))
+	}
+	if len(buttons) == 0 {
+		return nil
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(buttons)
+	return &markup
+}
+
+// groupByWeekOfMonth assigns each row to week 1 (days 1-7), 2 (8-14),
+// 3 (15-21) or 4 (22 to the end of the month) by its day of the month.
+func groupByWeekOfMonth(rows []Row) map[int][]Row {
+	weeks := make(map[int][]Row)
+	for _, row := range rows {
+		week := min((row.Date.Day()-1)/7+1, 4)
+		weeks[week] = append(weeks[week], row)
+	}
+	return weeks
+}
+
+// getMonthlyBreakdown lists this month's spending grouped by week of the
+// month, with a sub-total for each week.
+func (b *Bot) getMonthlyBreakdown(ctx context.Context) (string, error) {
+	start := monthStart()
+	end := start.AddDate(0, 1, -1)
+	rows, err := b.getEntriesBetween(ctx, start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to get monthly breakdown: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return "Tidak ada pengeluaran bulan ini", nil
+	}
+
+	weeks := groupByWeekOfMonth(rows)
+	total := 0
+	var result strings.Builder
+	for week := 1; week <= 4; week++ {
+		firstDay, lastDay := (week-1)*7+1, week*7
+		label := fmt.Sprintf("Minggu %d", week)
+		if week == 4 {
+			lastDay = end.Day()
+			label = "Minggu 4+"
+		}
+
+		subtotal := 0
+		for _, row := range weeks[week] {
+			subtotal += row.Nominal
+		}
+		total += subtotal
+
+		result.WriteString(fmt.Sprintf("🗓 %s (tgl %d-%d): Rp %s\n", label, firstDay, lastDay, formatRupiah(subtotal)))
+		if len(weeks[week]) == 0 {
+			result.WriteString("Tidak ada pengeluaran\n")
+		}
+		for _, row := range weeks[week] {
+			result.WriteString(row.format() + "\n")
+		}
+		result.WriteString("\n")
+	}
+	return fmt.Sprintf("📊 Pengeluaran Bulan Ini per Minggu (Rp %s):\n\n", formatRupiah(total)) + result.String(), nil
+}
+
+// getMonthlyTotal sums the spending recorded in the month of date.
+func (b *Bot) getMonthlyTotal(ctx context.Context, date time.Time) (int, error) {
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.Local)
+	rows, err := b.getEntriesBetween(ctx, start, start.AddDate(0, 1, -1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get monthly total: %w", err)
+	}
+
+	total := 0
+	for _, row := range rows {
+		total += row.Nominal
+	}
+	return total, nil
+}
+
+// getWeekendSummary compares Saturday and Sunday spending of the current
+// Monday-to-Sunday week with the weekday spending of the same week.
+func (b *Bot) getWeekendSummary(ctx context.Context) (string, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	weekStart := today.AddDate(0, 0, -((int(now.Weekday()) + 6) % 7))
+
+	rows, err := b.getEntriesBetween(ctx, weekStart, weekStart.AddDate(0, 0, 6))
+	if err != nil {
+		return "", fmt.Errorf("failed to get weekend summary: %w", err)
+	}
+
+	saturday, sunday, weekday := 0, 0, 0
+	for _, row := range rows {
+		switch row.Date.Weekday() {
+		case time.Saturday:
+			saturday += row.Nominal
+		case time.Sunday:
+			sunday += row.Nominal
+		default:
+			weekday += row.Nominal
+		}
+	}
+
+	weekend := saturday + sunday
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📊 Pengeluaran Akhir Pekan (%s - %s):\n\n",
+		weekStart.AddDate(0, 0, 5).Format("02-01-2006"), weekStart.AddDate(0, 0, 6).Format("02-01-2006")))
+	result.WriteString(fmt.Sprintf("🗓 Sabtu: Rp %s\n", formatRupiah(saturday)))
+	result.WriteString(fmt.Sprintf("🗓 Minggu: Rp %s\n", formatRupiah(sunday)))
+	result.WriteString(fmt.Sprintf("💰 Total akhir pekan: Rp %s\n\n", formatRupiah(weekend)))
+	result.WriteString(fmt.Sprintf("💼 Total hari kerja (Senin-Jumat): Rp %s\n", formatRupiah(weekday)))
+
+	// Compare per-day averages, since a week has five weekdays but only two weekend days.
+	weekendAvg, weekdayAvg := weekend/2, weekday/5
+	switch {
+	case weekendAvg > weekdayAvg:
+		result.WriteString(fmt.Sprintf("📈 Rata-rata harian akhir pekan (Rp %s) lebih tinggi dari hari kerja (Rp %s)", formatRupiah(weekendAvg), formatRupiah(weekdayAvg)))
+	case weekendAvg < weekdayAvg:
+		result.WriteString(fmt.Sprintf("📉 Rata-rata harian akhir pekan (Rp %s) lebih rendah dari hari kerja (Rp %s)", formatRupiah(weekendAvg), formatRupiah(weekdayAvg)))
+	default:
+		result.WriteString(fmt.Sprintf("⚖️ Rata-rata harian akhir pekan sama dengan hari kerja (Rp %s)", formatRupiah(weekendAvg)))
+	}
+
+	return result.String(), nil
+}
+
+// DailySummary holds the spending recorded on a single day.
+type DailySummary struct {
+	Date       time.Time
+	Total      int
+	Count      int
+	Categories map[string]int
+}
+
+func (b *Bot) getDailySummary(ctx context.Context, date time.Time) (DailySummary, error) {
+	summary := DailySummary{Date: date, Categories: make(map[string]int)}
+
+	rows, err := b.getEntriesBetween(ctx, date, date)
+	if err != nil {
+		return summary, fmt.Errorf("failed to get daily summary: %w", err)
+	}
+
+	for _, row := range rows {
+		summary.Total += row.Nominal
+		summary.Count++
+		summary.Categories[row.Category] += row.Nominal
+	}
+	return summary, nil
+}
+
+// formatDailySummary renders a DailySummary for the /today command, with a
+// per-category breakdown sorted from the biggest spending, then by name.
+func formatDailySummary(summary DailySummary) string {
+	if summary.Count == 0 {
+		return fmt.Sprintf("📅 Belum ada pengeluaran hari ini (%s)", summary.Date.Format("02-01-2006"))
+	}
+
+	categories := make([]string, 0, len(summary.Categories))
+	for category := range summary.Categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if summary.Categories[categories[i]] != summary.Categories[categories[j]] {
+			return summary.Categories[categories[i]] > summary.Categories[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📅 Pengeluaran Hari Ini (%s)\n\n", summary.Date.Format("02-01-2006")))
+	result.WriteString(fmt.Sprintf("💰 Total: Rp %s\n", formatRupiah(summary.Total)))
+	result.WriteString(fmt.Sprintf("🧾 Jumlah transaksi: %d\n\n", summary.Count))
+	result.WriteString("🎯 Per kategori:\n")
+	for _, category := range categories {
+		result.WriteString(fmt.Sprintf("• %s: Rp %s\n", category, formatRupiah(summary.Categories[category])))
+	}
+
+	return result.String()
+}
+
+// maxLastEntries is the most entries /last N lists.
+const maxLastEntries = 50
+
+// lastEntriesPageSize is how many entries one message of /last N lists,
+// keeping it under Telegram's 4096 character limit.
+const lastEntriesPageSize = 10
+
+// getLastNEntries returns the last n entries in sheet order, fewer when
+// there are not that many.
+func (b *Bot) getLastNEntries(ctx context.Context, n int) ([]Row, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+	return rows[max(0, len(rows)-n):], nil
+}
+
+// getLastEntries lists the last n entries, marking the rows in pinned, as
+// one message per lastEntriesPageSize entries.
+func (b *Bot) getLastEntries(ctx context.Context, n int, pinned map[int]bool) ([]string, error) {
+	entries, err := b.getLastNEntries(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return []string{"Belum ada data yang dimasukkan"}, nil
+	}
+
+	lines := make([]string, len(entries))
+	for i, row := range entries {
+		marker := ""
+		if pinned[row.RowNum] {
+			marker = "📌 "
+		}
+		lines[i] = fmt.Sprintf("%d. %sRp %s - %s - %s", i+1, marker, formatRupiah(row.Nominal), row.Category, row.Description)
+	}
+	pages := paginateEntries(lines, lastEntriesPageSize)
+	pages[0] = fmt.Sprintf("🧾 %d Transaksi Terakhir:\n\n", len(entries)) + pages[0]
+	return pages, nil
+}
+
+// getLastFiveEntries lists the last five entries, marking the rows in
+// pinned with 📌.
+func (b *Bot) getLastFiveEntries(ctx context.Context, pinned map[int]bool) (string, error) {
+	pages, err := b.getLastEntries(ctx, 5, pinned)
+	if err != nil {
+		return "", err
+	}
+	return pages[0], nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const taxConfigSheet = "TaxConfig"
+
+var taxConfigHeader = []interface{}{"ChatID", "Kategori", "Tarif"}
+
+// TaxCategory is a category whose spending is taxed at Rate percent.
+type TaxCategory struct {
+	Category string
+	Rate     float64
+}
+
+// parseTaxRate parses a percentage such as "11%", "11" or "2,5%".
+func parseTaxRate(s string) (float64, bool) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "%")
+	rate, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+	if err != nil || rate < 0 || rate > 100 {
+		return 0, false
+	}
+	return rate, true
+}
+
+func formatTaxRate(rate float64) string {
+	return formatDecimal(rate, 2) + "%"
+}
+
+// getTaxCategories returns the taxed categories of chatID.
+func (b *Bot) getTaxCategories(ctx context.Context, chatID int64) ([]TaxCategory, error) {
+	if err := b.ensureTab(ctx, taxConfigSheet, taxConfigHeader); err != nil {
+		return nil, err
+	}
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(taxConfigSheet, "A:C"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tax config: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	var categories []TaxCategory
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 || fmt.Sprintf("%v", row[0]) != id { // Skip header
+			continue
+		}
+		rate, ok := parseTaxRate(fmt.Sprintf("%v", row[2]))
+		if !ok {
+			continue
+		}
+		categories = append(categories, TaxCategory{Category: fmt.Sprintf("%v", row[1]), Rate: rate})
+	}
+	return categories, nil
+}
+
+// setTaxRate taxes category of chatID at rate percent. A rate of 0 stops
+// taxing the category.
+func (b *Bot) setTaxRate(ctx context.Context, chatID int64, category string, rate float64) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, taxConfigSheet, taxConfigHeader); err != nil {
+		return err
+	}
+	rows, err := store.Get(sheetRange(taxConfigSheet, "A:B"))
+	if err != nil {
+		return fmt.Errorf("failed to get tax config: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	row := 0
+	for i, r := range rows {
+		if i > 0 && len(r) > 1 && fmt.Sprintf("%v", r[0]) == id && strings.EqualFold(fmt.Sprintf("%v", r[1]), category) {
+			row = i + 1
+			break
+		}
+	}
+
+	switch {
+	case rate == 0 && row == 0:
+		return nil
+	case rate == 0:
+		err = store.Clear(sheetRange(taxConfigSheet, fmt.Sprintf("A%d:C%d", row, row)))
+	case row > 0:
+		err = store.Update(sheetRange(taxConfigSheet, fmt.Sprintf("A%d", row)), [][]interface{}{{id, category, rate}})
+	default:
+		err = store.Append(sheetRange(taxConfigSheet, "A1"), [][]interface{}{{id, category, rate}})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save tax rate: %w", err)
+	}
+	return nil
+}
+
+// getTaxEstimate applies the tax rates of chatID to this month's spending
+// per category.
+func (b *Bot) getTaxEstimate(ctx context.Context, chatID int64, date time.Time) (string, error) {
+	categories, err := b.getTaxCategories(ctx, chatID)
+	if err != nil {
+		return "", err
+	}
+	if len(categories) == 0 {
+		return "🧾 Belum ada kategori kena pajak. Gunakan /tax config <kategori>=<tarif>\nContoh: /tax config Jasa=11%", nil
+	}
+
+	totals, err := b.getCategoryTotals(ctx, date)
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Category < categories[j].Category
+	})
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("🧾 Estimasi Pajak %s:\n\n", date.Format("01-2006")))
+	totalTax := 0
+	for _, category := range categories {
+		spent := totals[category.Category]
+		tax := int(math.Round(float64(spent) * category.Rate / 100))
+		totalTax += tax
+		result.WriteString(fmt.Sprintf("%s: Rp %s × %s = Rp %s\n",
+			category.Category, formatRupiah(spent), formatTaxRate(category.Rate), formatRupiah(tax)))
+	}
+	result.WriteString(fmt.Sprintf("\n💰 Total estimasi pajak: Rp %s", formatRupiah(totalTax)))
+	return result.String(), nil
+}
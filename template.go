@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const templatesSheet = "Templates"
+
+var templatesHeader = []interface{}{"ChatID", "Nama", "Nominal", "Kategori", "Keterangan"}
+
+// Template is a named expense that /template use records in one go.
+type Template struct {
+	Name        string
+	Nominal     int
+	Category    string
+	Description string
+}
+
+// getTemplates returns the templates of chatID in the order they were
+// added.
+func (b *Bot) getTemplates(ctx context.Context, chatID int64) ([]Template, error) {
+	if err := b.ensureTab(ctx, templatesSheet, templatesHeader); err != nil {
+		return nil, err
+	}
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(templatesSheet, "A:E"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	var templates []Template
+	for i, row := range rows {
+		if i == 0 || len(row) < 4 || fmt.Sprintf("%v", row[0]) != id { // Skip header
+			continue
+		}
+		nominal, err := strconv.Atoi(fmt.Sprintf("%v", row[2]))
+		if err != nil {
+			continue
+		}
+		template := Template{Name: fmt.Sprintf("%v", row[1]), Nominal: nominal, Category: fmt.Sprintf("%v", row[3])}
+		if len(row) > 4 {
+			template.Description = b.openDescription(id, row[4])
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// getTemplate returns the template of chatID called name, ignoring case.
+func (b *Bot) getTemplate(ctx context.Context, chatID int64, name string) (Template, bool, error) {
+	templates, err := b.getTemplates(ctx, chatID)
+	if err != nil {
+		return Template{}, false, err
+	}
+	for _, template := range templates {
+		if strings.EqualFold(template.Name, name) {
+			return template, true, nil
+		}
+	}
+	return Template{}, false, nil
+}
+
+// templateRow returns the row of the template of chatID called name in the
+// Templates tab, or 0.
+func (b *Bot) templateRow(ctx context.Context, chatID int64, name string) (int, error) {
+	rows, err := storeWithContext(ctx, b.store).Get(sheetRange(templatesSheet, "A:B"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get templates: %w", err)
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	for i, row := range rows {
+		if i > 0 && len(row) > 1 && fmt.Sprintf("%v", row[0]) == id && strings.EqualFold(fmt.Sprintf("%v", row[1]), name) {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// setTemplate saves template for chatID, replacing the one with the same
+// name.
+func (b *Bot) setTemplate(ctx context.Context, chatID int64, template Template) error {
+	store := storeWithContext(ctx, b.store)
+	if err := b.ensureTab(ctx, templatesSheet, templatesHeader); err != nil {
+		return err
+	}
+
+	id := strconv.FormatInt(chatID, 10)
+	description, err := b.sealDescription(id, template.Description)
+	if err != nil {
+		return err
+	}
+	row, err := b.templateRow(ctx, chatID, template.Name)
+	if err != nil {
+		return err
+	}
+
+	values := [][]interface{}{{id, strings.ToLower(template.Name), template.Nominal, normalizeCategory(template.Category), description}}
+	if row > 0 {
+		err = store.Update(sheetRange(templatesSheet, fmt.Sprintf("A%d", row)), values)
+	} else {
+		err = store.Append(sheetRange(templatesSheet, "A1"), values)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save template: %w", err)
+	}
+	return nil
+}
+
+// deleteTemplate removes the template of chatID called name and reports
+// whether it existed.
+func (b *Bot) deleteTemplate(ctx context.Context, chatID int64, name string) (bool, error) {
+	if err := b.ensureTab(ctx, templatesSheet, templatesHeader); err != nil {
+		return false, err
+	}
+
+	row, err := b.templateRow(ctx, chatID, name)
+	if err != nil || row == 0 {
+		return false, err
+	}
+	if err := storeWithContext(ctx, b.store).Clear(sheetRange(templatesSheet, fmt.Sprintf("A%d:E%d", row, row))); err != nil {
+		return false, fmt.Errorf("failed to delete template: %w", err)
+	}
+	return true, nil
+}
+
+func formatTemplates(templates []Template) string {
+	if len(templates) == 0 {
+		return "📋 Belum ada template. Gunakan /template add <nama> <nominal>, <kategori>, <keterangan>"
+	}
+
+	var result strings.Builder
+	result.WriteString("📋 Template pengeluaran:\n\n")
+	for _, template := range templates {
+		result.WriteString(fmt.Sprintf("• %s: Rp %s | 🎯%s | 📚%s\n", template.Name, formatRupiah(template.Nominal), template.Category, template.Description))
+	}
+	result.WriteString("\nGunakan dengan /template use <nama>")
+	return result.String()
+}
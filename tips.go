@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// generalTips is the key of tips.json for tips not tied to a category.
+const generalTips = "Umum"
+
+//go:embed tips.json
+var tipsFile []byte
+
+// loadTips reads the financial tips of /random, keyed by category.
+func loadTips() (map[string][]string, error) {
+	var tips map[string][]string
+	if err := json.Unmarshal(tipsFile, &tips); err != nil {
+		return nil, fmt.Errorf("failed to parse tips.json: %w", err)
+	}
+	if len(tips[generalTips]) == 0 {
+		return nil, fmt.Errorf("tips.json has no %q tips", generalTips)
+	}
+	return tips, nil
+}
+
+// getRandomTip picks a tip for the category with the most spending this week
+// that has tips, or a general tip when there is none.
+func (b *Bot) getRandomTip(ctx context.Context) (string, error) {
+	totals, err := b.getWeeklySummaryByOffset(ctx, 0)
+	if err != nil {
+		return "", err
+	}
+
+	categories := make([]string, 0, len(totals))
+	for category := range totals {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return totals[categories[i]] > totals[categories[j]]
+	})
+
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	for _, category := range categories {
+		if tips := b.tips[category]; totals[category] > 0 && len(tips) > 0 {
+			return fmt.Sprintf("💡 Tips %s\n(pengeluaran %s minggu ini: Rp %s)\n\n%s",
+				category, category, formatRupiah(totals[category]), tips[r.Intn(len(tips))]), nil
+		}
+	}
+
+	tips := b.tips[generalTips]
+	return "💡 Tips Keuangan\n\n" + tips[r.Intn(len(tips))], nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// trendDays is how many days /trend shows.
+const trendDays = 30
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// getRollingAverage maps each of the last trendDays days, as DD-MM-YYYY, to
+// the average daily spending over the windowDays days ending on it.
+func getRollingAverage(rows []Row, windowDays int) map[string]float64 {
+	daily := make(map[string]int)
+	for _, row := range rows {
+		daily[row.Date.Format("02-01-2006")] += row.Nominal
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	averages := make(map[string]float64, trendDays)
+	for i := 0; i < trendDays; i++ {
+		day := today.AddDate(0, 0, -i)
+		sum := 0
+		for j := 0; j < windowDays; j++ {
+			sum += daily[day.AddDate(0, 0, -j).Format("02-01-2006")]
+		}
+		averages[day.Format("02-01-2006")] = float64(sum) / float64(windowDays)
+	}
+	return averages
+}
+
+// sparkline renders values as a line of block characters scaled between
+// the smallest and the largest value.
+func sparkline(values []float64) string {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+
+	var line strings.Builder
+	for _, v := range values {
+		level := 0
+		if high > low {
+			level = int((v - low) / (high - low) * float64(len(sparkBlocks)-1))
+		}
+		line.WriteRune(sparkBlocks[level])
+	}
+	return line.String()
+}
+
+// timelineBarWidth is the length of the bar of the day with the most
+// spending in renderDailyTimeline.
+const timelineBarWidth = 15
+
+// renderDailyTimeline draws the spending of each of the last days days, up to
+// trendDays so the message stays within Telegram's length limit, as a bar
+// scaled to the day with the most spending.
+func renderDailyTimeline(rows []Row, days int) string {
+	days = max(1, min(days, trendDays))
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	first := today.AddDate(0, 0, -(days - 1))
+
+	daily := make(map[string]int)
+	for _, row := range rows {
+		if !row.Date.Before(first) && !row.Date.After(today) {
+			daily[row.Date.Format("02-01-2006")] += row.Nominal
+		}
+	}
+	highest := 0
+	for _, total := range daily {
+		highest = max(highest, total)
+	}
+
+	var chart strings.Builder
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		total := daily[day.Format("02-01-2006")]
+		if total <= 0 {
+			chart.WriteString(fmt.Sprintf("%s | - (tidak ada)\n", day.Format("02-01")))
+			continue
+		}
+		width := max(1, total*timelineBarWidth/highest)
+		chart.WriteString(fmt.Sprintf("%s | %s Rp %s\n", day.Format("02-01"), strings.Repeat("█", width), formatRupiah(total)))
+	}
+	return chart.String()
+}
+
+func (b *Bot) getTrend(ctx context.Context) (string, error) {
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get trend: %w", err)
+	}
+
+	averages := getRollingAverage(rows, 7)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	first := today.AddDate(0, 0, -(trendDays - 1))
+
+	values := make([]float64, 0, trendDays)
+	peak, trough := first, first
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		v := averages[day.Format("02-01-2006")]
+		values = append(values, v)
+		if v > averages[peak.Format("02-01-2006")] {
+			peak = day
+		}
+		if v < averages[trough.Format("02-01-2006")] {
+			trough = day
+		}
+	}
+
+	if averages[peak.Format("02-01-2006")] == 0 {
+		return fmt.Sprintf("Tidak ada pengeluaran dalam %d hari terakhir", trendDays), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📈 Tren Pengeluaran %d Hari Terakhir\n(rata-rata bergerak 7 hari)\n\n", trendDays))
+	result.WriteString(sparkline(values) + "\n")
+	result.WriteString(fmt.Sprintf("%s → %s\n\n", first.Format("02-01"), today.Format("02-01")))
+	result.WriteString(fmt.Sprintf("🔺 Tertinggi: %s (Rp %s/hari)\n", peak.Format("02-01-2006"),
+		formatRupiah(int(math.Round(averages[peak.Format("02-01-2006")])))))
+	result.WriteString(fmt.Sprintf("🔻 Terendah: %s (Rp %s/hari)\n", trough.Format("02-01-2006"),
+		formatRupiah(int(math.Round(averages[trough.Format("02-01-2006")])))))
+	result.WriteString(fmt.Sprintf("📍 Hari ini: Rp %s/hari", formatRupiah(int(math.Round(values[len(values)-1])))))
+	return result.String(), nil
+}
+
+// costPerDayDays is the period /cost_per_day averages over by default.
+const costPerDayDays = 30
+
+// getDailyCostByCategory returns the average daily spending on category over
+// the last days days, today included, and on how many of those days it was
+// spent on at all.
+func (b *Bot) getDailyCostByCategory(ctx context.Context, category string, days int) (float64, int, error) {
+	today := time.Now()
+	rows, err := b.getEntriesBetween(ctx, today.AddDate(0, 0, -(days-1)), today)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get daily cost: %w", err)
+	}
+
+	total := 0
+	activeDays := make(map[string]bool)
+	for _, row := range rows {
+		if normalizeCategory(row.Category) != normalizeCategory(category) {
+			continue
+		}
+		total += row.Nominal
+		activeDays[row.Date.Format("02-01-2006")] = true
+	}
+	return float64(total) / float64(days), len(activeDays), nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Transcriber turns voice messages into text.
+type Transcriber interface {
+	Transcribe(name string, audio io.Reader) (string, error)
+}
+
+const whisperURL = "https://api.openai.com/v1/audio/transcriptions"
+
+var voiceClient = &http.Client{Timeout: 60 * time.Second}
+
+// WhisperTranscriber transcribes with the OpenAI Whisper API.
+type WhisperTranscriber struct {
+	apiKey string
+}
+
+func NewWhisperTranscriber(apiKey string) *WhisperTranscriber {
+	return &WhisperTranscriber{apiKey: apiKey}
+}
+
+func (t *WhisperTranscriber) Transcribe(name string, audio io.Reader) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	file, err := form.CreateFormFile("file", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	if _, err := io.Copy(file, audio); err != nil {
+		return "", fmt.Errorf("failed to read voice message: %w", err)
+	}
+	form.WriteField("model", "whisper-1")
+	form.WriteField("language", "id")
+	if err := form.Close(); err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, whisperURL, &body)
+	if err != nil {
+		return "", fmt.Errorf("failed to build transcription request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := voiceClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to transcribe voice message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Text  string `json:"text"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to read transcription (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to transcribe voice message: %s: %s", resp.Status, result.Error.Message)
+	}
+	return result.Text, nil
+}
+
+// spokenAmount matches an amount at the start of a transcript, as written
+// from speech, e.g. "10 ribu" or "1,5 juta".
+var spokenAmount = regexp.MustCompile(`(?i)^(\d[\d.]*(?:,\d+)?)\s*(ribu|juta)\b`)
+
+// normalizeSpokenExpense rewrites a transcript into the format typed
+// expenses use: the closing period is dropped and a spoken amount such as
+// "10 ribu" becomes "10rb".
+func normalizeSpokenExpense(transcript string) string {
+	transcript = strings.TrimRight(strings.TrimSpace(transcript), ".")
+	m := spokenAmount.FindStringSubmatchIndex(transcript)
+	if m == nil {
+		return transcript
+	}
+	unit := "rb"
+	if strings.EqualFold(transcript[m[4]:m[5]], "juta") {
+		unit = "jt"
+	}
+	return transcript[m[2]:m[3]] + unit + transcript[m[1]:]
+}
+
+// handleVoice transcribes a voice message and, when it reads as an expense,
+// asks the user to confirm the parsed values before recording it.
+func (b *Bot) handleVoice(ctx context.Context, message *tgbotapi.Message) {
+	chatId := message.Chat.ID
+	if b.transcriber == nil {
+		b.sendMessage(tgbotapi.NewMessage(chatId, "🎙 Pesan suara belum didukung. Kirim pengeluaran dalam bentuk teks: Nominal, Kategori, Keterangan"))
+		return
+	}
+
+	transcript, err := b.transcribeVoice(message.Voice)
+	if err != nil {
+		log.Printf("failed to transcribe voice message of %d: %v", chatId, err)
+		b.sendMessage(tgbotapi.NewMessage(chatId, "❌ Gagal mengenali pesan suara. Coba lagi atau kirim dalam bentuk teks"))
+		return
+	}
+
+	expense, reply, ok := b.parseExpense(ctx, chatId, normalizeSpokenExpense(transcript))
+	if ok && expense.nominal <= 0 {
+		ok, reply = false, b.msg(chatId).FormatError
+	}
+	if !ok {
+		b.sendMessage(tgbotapi.NewMessage(chatId, fmt.Sprintf("🎙 Terdengar: \"%s\"\n\n%s", transcript, reply)))
+		return
+	}
+	expense.messageID = message.MessageID
+	expense.messageRef = messageRef(message)
+	expense.sender = senderName(message.From)
+
+	b.mu.Lock()
+	b.pendingVoice[chatId] = expense
+	b.mu.Unlock()
+
+	msg := tgbotapi.NewMessage(chatId, fmt.Sprintf("🎙 Terdengar: \"%s\"\n\n💰 Rp %s\n🎯 %s\n📚 %s\n📅 %s\n\nCatat pengeluaran ini?",
+		transcript, formatRupiah(expense.nominal), normalizeCategory(expense.category), expense.description, expense.date.Format("02-01-2006")))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Catat", "voice_confirm"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "voice_cancel"),
+		),
+	)
+	b.sendMessage(msg)
+}
+
+// transcribeVoice downloads a voice message from Telegram and transcribes
+// it.
+func (b *Bot) transcribeVoice(voice *tgbotapi.Voice) (string, error) {
+	fileURL, err := b.api.GetFileDirectURL(voice.FileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get voice message: %w", err)
+	}
+	resp, err := voiceClient.Get(fileURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to download voice message: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download voice message: %s", resp.Status)
+	}
+	return b.transcriber.Transcribe("voice.ogg", resp.Body)
+}
+
+// handleVoiceCallback answers the confirmation of a voice expense with
+// "confirm" or "cancel".
+func (b *Bot) handleVoiceCallback(ctx context.Context, chatID int64, messageID int, data string) {
+	b.mu.Lock()
+	expense, ok := b.pendingVoice[chatID]
+	delete(b.pendingVoice, chatID)
+	b.mu.Unlock()
+
+	if !ok {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "❌ Tidak ada pesan suara yang menunggu konfirmasi"))
+		return
+	}
+	if data != "confirm" {
+		b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🚫 Pengeluaran dari pesan suara tidak dicatat"))
+		return
+	}
+	b.sendMessage(tgbotapi.NewEditMessageText(chatID, messageID, "🎙 Pengeluaran dari pesan suara dicatat"))
+	b.recordExpense(ctx, chatID, expense)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// progressBarWidth is how many blocks progressBar draws.
+const progressBarWidth = 10
+
+// progressBar draws percent, capped at 100, as a bar of blocks.
+func progressBar(percent int) string {
+	filled := max(0, min(percent, 100)) * progressBarWidth / 100
+	return strings.Repeat("▓", filled) + strings.Repeat("░", progressBarWidth-filled)
+}
+
+// weeklyBudget prorates the monthly limit to one week of the month of date,
+// which has daysInMonth/7 weeks.
+func weeklyBudget(monthlyLimit int, date time.Time) int {
+	daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	return int(math.Round(float64(monthlyLimit) / (float64(daysInMonth) / 7.0)))
+}
+
+// getWeekVsBudget compares this week's spending with chatID's monthly limit
+// prorated to a week.
+func (b *Bot) getWeekVsBudget(ctx context.Context, chatID int64) (string, error) {
+	pref, _ := b.getPreference(chatID)
+	if pref.MonthlyLimit <= 0 {
+		return "❌ Batas bulanan belum diatur. Atur dulu dengan /limit monthly <nominal>", nil
+	}
+
+	weekStart, weekEnd := weekBounds(0)
+	rows, err := b.getEntriesBetween(ctx, weekStart, weekEnd.AddDate(0, 0, -1))
+	if err != nil {
+		return "", fmt.Errorf("failed to get week vs budget: %w", err)
+	}
+	total := 0
+	for _, row := range rows {
+		total += row.Nominal
+	}
+
+	now := time.Now()
+	target := weeklyBudget(pref.MonthlyLimit, now)
+	percent := 0
+	if target > 0 {
+		percent = total * 100 / target
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysLeft := int(weekEnd.Sub(today).Hours() / 24)
+
+	var result strings.Builder
+	result.WriteString("📅 Minggu ini vs budget mingguan\n\n")
+	result.WriteString(fmt.Sprintf("💰 Rp %s dari Rp %s\n", formatRupiah(total), formatRupiah(target)))
+	result.WriteString(fmt.Sprintf("%s %d%%\n", progressBar(percent), percent))
+	result.WriteString(fmt.Sprintf("⏳ %d hari lagi di minggu ini\n\n", daysLeft))
+	if total > target {
+		result.WriteString(fmt.Sprintf("⚠️ Sudah melewati budget mingguan sebesar Rp %s", formatRupiah(total-target)))
+	} else {
+		result.WriteString(fmt.Sprintf("✅ Sisa budget minggu ini Rp %s", formatRupiah(target-total)))
+	}
+	result.WriteString(fmt.Sprintf("\n\nℹ️ Budget mingguan = batas bulanan Rp %s dibagi jumlah minggu bulan ini", formatRupiah(pref.MonthlyLimit)))
+	return result.String(), nil
+}
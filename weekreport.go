@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// weeklyReportHour is the hour on Monday, in the user's timezone, the weekly
+// report is sent at.
+const weeklyReportHour = 8
+
+// getWeeklyReport lists last week's spending per category, biggest first.
+func (b *Bot) getWeeklyReport(ctx context.Context) (string, error) {
+	totals, err := b.getWeeklySummaryByOffset(ctx, -1)
+	if err != nil {
+		return "", err
+	}
+
+	weekStart, weekEnd := weekBounds(-1)
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("📅 Laporan Mingguan %s - %s\n\n",
+		weekStart.Format("02-01"), weekEnd.AddDate(0, 0, -1).Format("02-01-2006")))
+
+	categories := make([]string, 0, len(totals))
+	total := 0
+	for category, amount := range totals {
+		categories = append(categories, category)
+		total += amount
+	}
+	if len(categories) == 0 {
+		result.WriteString("Tidak ada pengeluaran minggu lalu")
+		return result.String(), nil
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if totals[categories[i]] != totals[categories[j]] {
+			return totals[categories[i]] > totals[categories[j]]
+		}
+		return categories[i] < categories[j]
+	})
+
+	for _, category := range categories {
+		result.WriteString(fmt.Sprintf("%s: Rp %s\n", category, formatRupiah(totals[category])))
+	}
+	result.WriteString(fmt.Sprintf("\n💰 Total: Rp %s", formatRupiah(total)))
+	return result.String(), nil
+}
+
+// sendWeeklyReports sends last week's report to the users who turned it on,
+// once every Monday morning.
+func (b *Bot) sendWeeklyReports(ctx context.Context, now time.Time) {
+	b.mu.RLock()
+	prefs := make([]UserPreference, 0, len(b.prefs))
+	for _, pref := range b.prefs {
+		prefs = append(prefs, pref)
+	}
+	b.mu.RUnlock()
+
+	var report string
+	for _, pref := range prefs {
+		local := now.In(pref.location())
+		if !pref.WeeklyReportEnabled || local.Weekday() != time.Monday || local.Hour() != weeklyReportHour {
+			continue
+		}
+
+		// The report may be attempted every minute of the hour, send it once
+		day := local.Format("2006-01-02")
+		b.mu.Lock()
+		sent := b.weeklyReportDay[pref.ChatID] == day
+		b.weeklyReportDay[pref.ChatID] = day
+		b.mu.Unlock()
+		if sent {
+			continue
+		}
+
+		// The summary covers the whole sheet, so it is built once for everyone
+		if report == "" {
+			var err error
+			report, err = b.getWeeklyReport(ctx)
+			if err != nil {
+				log.Printf("failed to build weekly report: %v", err)
+				return
+			}
+		}
+		if _, err := b.sendMessage(tgbotapi.NewMessage(pref.ChatID, report)); err != nil {
+			log.Printf("failed to send weekly report to %d: %v", pref.ChatID, err)
+		}
+	}
+}
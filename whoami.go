@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// entryStats are the usage numbers shown by /whoami.
+type entryStats struct {
+	thisMonth, allTime int
+	firstDate          string
+}
+
+// getEntryStats counts the entries with a single read of the sheet.
+func (b *Bot) getEntryStats(ctx context.Context, now time.Time) (entryStats, error) {
+	var stats entryStats
+
+	rows, err := b.getRows(ctx)
+	if err != nil {
+		return stats, err
+	}
+
+	var first time.Time
+	for _, row := range rows {
+		stats.allTime++
+		if row.Date.Year() == now.Year() && row.Date.Month() == now.Month() {
+			stats.thisMonth++
+		}
+		if first.IsZero() || row.Date.Before(first) {
+			first = row.Date
+		}
+	}
+	if !first.IsZero() {
+		stats.firstDate = first.Format("02-01-2006")
+	}
+	return stats, nil
+}
+
+func (b *Bot) getWhoami(ctx context.Context, chatID int64) (string, error) {
+	pref, ok := b.getPreference(chatID)
+	stats, err := b.getEntryStats(ctx, time.Now().In(pref.location()))
+	if err != nil {
+		return "", err
+	}
+
+	var result strings.Builder
+	result.WriteString("🪪 Tentang Kamu\n\n")
+	result.WriteString(fmt.Sprintf("🆔 Chat ID: %d\n", chatID))
+	if ok {
+		result.WriteString(fmt.Sprintf("👤 Nama: %s\n", pref.Name))
+		result.WriteString(fmt.Sprintf("🌏 Zona waktu: %s\n", timezoneLabel(pref.location().String())))
+		result.WriteString(fmt.Sprintf("👛 Akun aktif: %s\n", pref.account()))
+		if pref.JointSheet != "" {
+			result.WriteString(fmt.Sprintf("🤝 Dicatat bersama di tab: %s\n", pref.JointSheet))
+		}
+		result.WriteString(fmt.Sprintf("🔔 Pengingat: %s\n", pref.ReminderType.label()))
+		if pref.remindersPaused(time.Now()) {
+			result.WriteString(fmt.Sprintf("⏸ Dijeda sampai: %s\n", pref.PausedUntil.In(pref.location()).Format("02-01-2006 15:04")))
+		}
+		if pref.DigestTime != "" {
+			result.WriteString(fmt.Sprintf("☀️ Ringkasan pagi: %s\n", pref.DigestTime))
+		}
+		if pref.WeeklyReportEnabled {
+			result.WriteString(fmt.Sprintf("📅 Laporan mingguan: Senin %02d:00\n", weeklyReportHour))
+		}
+		if pref.ReportEmail != "" {
+			result.WriteString(fmt.Sprintf("📧 Laporan bulanan ke: %s\n", pref.ReportEmail))
+		}
+		if pref.MonthlyLimit > 0 {
+			result.WriteString(fmt.Sprintf("🎯 Batas bulanan: Rp %s\n", formatRupiah(pref.MonthlyLimit)))
+		}
+		if pref.WeeklyLimit > 0 {
+			result.WriteString(fmt.Sprintf("📅 Target mingguan: Rp %s\n", formatRupiah(pref.WeeklyLimit)))
+		}
+	} else {
+		result.WriteString("⚙️ Preferensi belum diatur, kirim /start\n")
+	}
+
+	result.WriteString("\n📊 Statistik:\n")
+	result.WriteString(fmt.Sprintf("• Entri bulan ini: %d\n", stats.thisMonth))
+	result.WriteString(fmt.Sprintf("• Entri sepanjang waktu: %d\n", stats.allTime))
+	if stats.firstDate != "" {
+		result.WriteString(fmt.Sprintf("• Entri pertama: %s\n", stats.firstDate))
+	}
+	return result.String(), nil
+}